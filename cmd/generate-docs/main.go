@@ -140,6 +140,22 @@ func generateDocumentation(cmd *cobra.Command) string {
 	sb.WriteString("meos-graphics --meos-host=meos.example.com --meos-port=none\n")
 	sb.WriteString("```\n\n")
 
+	sb.WriteString("### Let MeOS push data instead of polling it\n\n")
+	sb.WriteString("```bash\n")
+	sb.WriteString("meos-graphics --push --push-secret=changeme\n")
+	sb.WriteString("```\n\n")
+
+	sb.WriteString("### Load IOF XML 3.0 start and result lists from a directory\n\n")
+	sb.WriteString("```bash\n")
+	sb.WriteString("meos-graphics --iof-xml=./exports\n")
+	sb.WriteString("```\n\n")
+
+	sb.WriteString("### Record a race and replay it later at 10x speed\n\n")
+	sb.WriteString("```bash\n")
+	sb.WriteString("meos-graphics --record=race.journal\n")
+	sb.WriteString("meos-graphics --replay=race.journal --replay-speed=10\n")
+	sb.WriteString("```\n\n")
+
 	sb.WriteString("### Show version information\n\n")
 	sb.WriteString("```bash\n")
 	sb.WriteString("meos-graphics --version\n")
@@ -152,6 +168,9 @@ func generateDocumentation(cmd *cobra.Command) string {
 	sb.WriteString("- The `--poll-interval` flag accepts Go duration strings (e.g., \"200ms\", \"1s\", \"2m\", \"1h\")\n")
 	sb.WriteString("- When using `--meos-port=none`, the port is omitted from the MeOS server URL\n")
 	sb.WriteString("- In simulation mode, the application generates test data without connecting to a real MeOS server\n")
+	sb.WriteString("- In push mode, configure MeOS's online results module to post to `http://<host>:8090/mop` with the push secret as password\n")
+	sb.WriteString("- With `--iof-xml`, the file or directory is checked for changes every `--poll-interval`\n")
+	sb.WriteString("- A journal stores each MeOS response with the time it was received; replay applies them with the recorded delays divided by `--replay-speed`\n")
 
 	return sb.String()
 }
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,12 +17,21 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
+	"meos-graphics/internal/archive"
+	"meos-graphics/internal/audit"
 	"meos-graphics/internal/cmd"
+	"meos-graphics/internal/feed"
 	"meos-graphics/internal/handlers"
+	"meos-graphics/internal/history"
 	"meos-graphics/internal/i18n"
+	"meos-graphics/internal/iof"
 	"meos-graphics/internal/logger"
 	"meos-graphics/internal/meos"
 	"meos-graphics/internal/middleware"
+	"meos-graphics/internal/overrides"
+	"meos-graphics/internal/persist"
+	"meos-graphics/internal/push"
+	"meos-graphics/internal/replay"
 	"meos-graphics/internal/service"
 	"meos-graphics/internal/simulation"
 	"meos-graphics/internal/sse"
@@ -47,6 +58,11 @@ import (
 // @BasePath /
 // @schemes http https
 
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Admin token given with --admin-token, as "Bearer <token>"
+
 func main() {
 	rootCmd := cmd.NewRootCommand()
 	rootCmd.RunE = run
@@ -89,6 +105,10 @@ func run(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	if err := validateDataSourceFlags(); err != nil {
+		return err
+	}
+
 	if err := logger.Init(); err != nil {
 		return fmt.Errorf("failed to initialize logger: %v", err)
 	}
@@ -153,8 +173,32 @@ func run(_ *cobra.Command, _ []string) error {
 	}
 
 	var simulationAdapter *simulation.Adapter
-
-	if cmd.SimulationMode {
+	var meosAdapter *meos.Adapter
+	var replayAdapter *replay.Adapter
+	var pushReceiver *push.Receiver
+
+	// Adapter whose processed MOP responses are recorded with --record
+	var recordingAdapter *meos.Adapter
+
+	var archiveAdapter *archive.Adapter
+
+	if cmd.ArchiveEvent != 0 {
+		logger.InfoLogger.Printf("Serving archived event %d from %s read-only", cmd.ArchiveEvent, cmd.ArchivePath)
+		archiveAdapter = archive.NewAdapter(cmd.ArchivePath, cmd.ArchiveEvent, appState)
+		adapter = archiveAdapter
+	} else if cmd.IOFPath != "" {
+		logger.InfoLogger.Printf("Loading IOF XML from %s, checking for changes every %s", cmd.IOFPath, cmd.PollInterval)
+		adapter = iof.NewAdapter(cmd.IOFPath, cmd.PollInterval, appState)
+	} else if cmd.PushMode {
+		logger.InfoLogger.Println("Running in PUSH MODE - waiting for MeOS to post data to /mop")
+		pushReceiver = push.NewReceiver(appState, cmd.PushSecret)
+		adapter = pushReceiver
+		recordingAdapter = pushReceiver.Adapter()
+	} else if cmd.ReplayFile != "" {
+		logger.InfoLogger.Printf("Replaying journal %s at %gx speed", cmd.ReplayFile, cmd.ReplaySpeed)
+		replayAdapter = replay.NewAdapter(cmd.ReplayFile, cmd.ReplaySpeed, appState)
+		adapter = replayAdapter
+	} else if cmd.SimulationMode {
 		// Use simulation adapter with timing and content configuration
 		simulationAdapter = simulation.NewAdapter(appState, cmd.SimulationDuration,
 			cmd.SimulationPhaseStart, cmd.SimulationPhaseRunning, cmd.SimulationPhaseResults,
@@ -179,37 +223,115 @@ func run(_ *cobra.Command, _ []string) error {
 			return err
 		}
 
-		adapter = meos.NewAdapter(config, appState)
+		meosAdapter = meos.NewAdapter(config, appState)
+		adapter = meosAdapter
+		recordingAdapter = meosAdapter
 	}
 
-	// Connect adapter
-	if err := adapter.Connect(); err != nil {
-		logger.ErrorLogger.Printf("Failed to connect: %v", err)
-		if !cmd.SimulationMode {
-			logger.ErrorLogger.Println("Starting in offline mode - MeOS server not available")
+	if cmd.RecordFile != "" && recordingAdapter != nil {
+		journal, err := meos.OpenJournal(cmd.RecordFile)
+		if err != nil {
+			return err
 		}
-	} else {
-		logger.InfoLogger.Println("Connected successfully")
+		defer journal.Close()
+		recordingAdapter.RecordTo(journal)
+		logger.InfoLogger.Printf("Recording MeOS responses to %s", cmd.RecordFile)
+	}
 
-		if err := adapter.StartPolling(); err != nil {
-			logger.ErrorLogger.Printf("Failed to start polling: %v", err)
-			logger.ErrorLogger.Println("Continuing without polling")
-		} else {
-			logger.InfoLogger.Println("Started polling for updates")
+	// Serve the data saved before a restart until MeOS can be reached again
+	var saver *persist.Saver
+	if cmd.StateFile != "" {
+		saver = persist.NewSaver(cmd.StateFile, cmd.StateInterval, appState)
+		if meosAdapter != nil {
+			saver.TrackDifference(meosAdapter.AppliedDifference)
+		}
+		restoreState(cmd.StateFile, appState, meosAdapter, saver)
+		go saver.Run()
+	}
+
+	// Archive every event received, unless serving one from the archive
+	var recorder *archive.Recorder
+	if cmd.ArchivePath != "" && archiveAdapter == nil {
+		db, err := archive.Open(cmd.ArchivePath)
+		if err != nil {
+			return err
 		}
+		defer db.Close()
+		recorder = archive.NewRecorder(db, appState, time.Second)
+		appState.OnChange(recorder.HandleChange)
+		go recorder.Run()
+		logger.InfoLogger.Printf("Archiving events to %s", cmd.ArchivePath)
 	}
 
+	// Record the changes to competitors' results in the audit log. It listens before the
+	// first fetch, which brings the changes made while a restarted server was down.
+	auditLog := audit.New()
+	appState.OnChange(auditLog.HandleChange)
+
 	// Set up SSE hub
 	sseHub := sse.NewHub()
 	go sseHub.Run()
 
-	// Create service layer
+	// Let clients know as soon as the MeOS link goes up or down
+	if meosAdapter != nil {
+		meosAdapter.OnStatusChange(func(status meos.ConnectionStatus) {
+			if status.Connected {
+				logger.InfoLogger.Println("Connection to MeOS established")
+			} else {
+				logger.ErrorLogger.Printf("MeOS unreachable (%d consecutive failures): %s", status.ConsecutiveFailures, status.LastError)
+			}
+			sseHub.BroadcastUpdate("source-status", status)
+		})
+	}
+
+	// Connect adapter
+	if err := adapter.Connect(); err != nil {
+		// An archived event never arrives later
+		if archiveAdapter != nil {
+			return err
+		}
+		logger.ErrorLogger.Printf("Failed to connect: %v", err)
+		if meosAdapter != nil {
+			logger.ErrorLogger.Println("MeOS server not available - will keep retrying in the background")
+		}
+	} else {
+		logger.InfoLogger.Println("Connected successfully")
+	}
+
+	if err := adapter.StartPolling(); err != nil {
+		logger.ErrorLogger.Printf("Failed to start polling: %v", err)
+		logger.ErrorLogger.Println("Continuing without polling")
+	} else {
+		logger.InfoLogger.Println("Started polling for updates")
+	}
+
+	// Create service layer, with the manual overrides layered on top of the state
+	overrideStore := overrides.New(appState)
 	svc := service.New(appState)
+	svc.UseOverrides(overrideStore)
 
 	// Set up state change notifications
-	appState.OnUpdate(func() {
-		sseHub.BroadcastUpdate("update", gin.H{"timestamp": time.Now().Unix()})
-	})
+	appState.OnChange(sseHub.BroadcastChange)
+	overrideStore.OnChange(sseHub.BroadcastChange)
+
+	// Derive the competitor event feed from the state changes, with the overrides applied
+	competitorFeed := feed.New(appState)
+	competitorFeed.UseOverrides(overrideStore)
+	competitorFeed.OnItems(sseHub.BroadcastFeed)
+
+	// A replayed race runs on the time it was recorded at, so competitors start, run and
+	// finish as they did on the day
+	if replayAdapter != nil {
+		svc.UseClock(replayAdapter.Now)
+		competitorFeed.UseClock(replayAdapter.Now)
+	}
+	appState.OnChange(competitorFeed.HandleChange)
+	overrideStore.OnChange(competitorFeed.HandleChange)
+	go competitorFeed.Run()
+
+	// Keep every version of the data for requests about a past moment
+	stateHistory := history.New(appState, cmd.HistoryRetention)
+	appState.OnChange(stateHistory.HandleChange)
 
 	// Set up HTTP server
 	gin.SetMode(gin.ReleaseMode)
@@ -237,16 +359,38 @@ func run(_ *cobra.Command, _ []string) error {
 
 	// Create handlers
 	h := handlers.New(appState)
-	webHandler := web.New(svc, cmd.SimulationMode)
+	h.UseOverrides(overrideStore)
+	h.UseHistory(stateHistory)
+	if replayAdapter != nil {
+		h.UseClock(replayAdapter.Now)
+	}
+	webHandler := web.New(svc, competitorFeed, cmd.SimulationMode)
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		response := gin.H{
 			"status":         "ok",
-			"meos_connected": true,
+			"meos_connected": false,
 			"sse_clients":    sseHub.GetConnectedClients(),
 		}
 
+		// Add MeOS connection details when polling a MeOS server
+		if meosAdapter != nil {
+			status := meosAdapter.Status()
+			response["meos_connected"] = status.Connected
+			response["meos"] = status
+		}
+
+		// Add push statistics when MeOS pushes data to us
+		if pushReceiver != nil {
+			response["push"] = pushReceiver.Status()
+		}
+
+		// Add replay progress if replaying a journal
+		if replayAdapter != nil {
+			response["replay"] = replayAdapter.Status()
+		}
+
 		// Add simulation status if in simulation mode
 		if simulationAdapter != nil {
 			phase, nextPhaseIn, _ := simulationAdapter.GetSimulationStatus()
@@ -261,10 +405,47 @@ func run(_ *cobra.Command, _ []string) error {
 
 	// API endpoints (REST)
 	api := router.Group("/")
+	api.Use(h.AsOf(), h.Conditional())
+	api.GET("/event", h.GetEvent)
+	api.GET("/controls", h.GetControls)
 	api.GET("/classes", h.GetClasses)
 	api.GET("/classes/:classId/startlist", h.GetStartList)
 	api.GET("/classes/:classId/results", h.GetResults)
 	api.GET("/classes/:classId/splits", h.GetSplits)
+	api.GET("/classes/:classId/predictions", h.GetPredictions)
+	api.GET("/classes/:classId/teams", h.GetTeamResults)
+	api.GET("/classes/:classId/legs/:leg", h.GetLegStandings)
+	api.GET("/classes/:classId/changeovers", h.GetChangeovers)
+	api.GET("/competitors/:competitorId", h.GetCompetitor)
+	api.GET("/clubs", h.GetClubs)
+	api.GET("/clubs/:clubId", h.GetClub)
+	api.GET("/search", h.Search)
+	api.GET("/classes/:classId/iof/resultlist", h.GetClassIOFResultList)
+	api.GET("/classes/:classId/iof/startlist", h.GetClassIOFStartList)
+	api.GET("/iof/resultlist", h.GetIOFResultList)
+	api.GET("/iof/startlist", h.GetIOFStartList)
+
+	// Admin API for manual overrides, only when a token is configured
+	if cmd.AdminToken != "" {
+		admin := router.Group("/admin")
+		admin.Use(middleware.BearerToken(cmd.AdminToken))
+		admin.GET("/overrides", h.ListOverrides)
+		admin.PUT("/overrides/:competitorId", h.SetOverride)
+		admin.DELETE("/overrides/:competitorId", h.DeleteOverride)
+		admin.DELETE("/overrides", h.ClearOverrides)
+		logger.InfoLogger.Println("Admin API enabled under /admin")
+	}
+
+	// Competitor event feed, which changes as competitors start without a new revision
+	router.GET("/feed", competitorFeed.HandleFeed)
+
+	// Audit log of result changes received from MeOS
+	router.GET("/audit", auditLog.HandleAudit)
+
+	// Push endpoint for MeOS's online results module
+	if pushReceiver != nil {
+		router.POST("/mop", pushReceiver.HandlePush)
+	}
 
 	// Web interface endpoints
 	webGroup := router.Group("/web")
@@ -273,6 +454,20 @@ func run(_ *cobra.Command, _ []string) error {
 	webGroup.GET("/classes/:classId/startlist", webHandler.StartListPartial)
 	webGroup.GET("/classes/:classId/results", webHandler.ResultsPartial)
 	webGroup.GET("/classes/:classId/splits", webHandler.SplitsPartial)
+	webGroup.GET("/classes/:classId/teams", webHandler.TeamResultsPartial)
+	webGroup.GET("/classes/:classId/legs", webHandler.LegsPartial)
+	webGroup.GET("/competitors/:competitorId", webHandler.CompetitorPage)
+	webGroup.GET("/competitors/:competitorId/details", webHandler.CompetitorPartial)
+	webGroup.GET("/clubs", webHandler.ClubsPage)
+	webGroup.GET("/clubs/:clubId", webHandler.ClubPage)
+	webGroup.GET("/clubs/:clubId/competitors", webHandler.ClubCompetitorsPartial)
+	webGroup.GET("/search", webHandler.SearchPage)
+	webGroup.GET("/search/results", webHandler.SearchResultsPartial)
+	webGroup.GET("/speaker", webHandler.SpeakerPage)
+	webGroup.GET("/speaker/feed", webHandler.SpeakerFeedPartial)
+	webGroup.GET("/speaker/arrivals", webHandler.SpeakerArrivalsPartial)
+	webGroup.GET("/speaker/finishes", webHandler.SpeakerFinishesPartial)
+	webGroup.GET("/speaker/leaders", webHandler.SpeakerLeadersPartial)
 
 	// SSE endpoint
 	router.GET("/sse", sseHub.HandleSSE)
@@ -345,10 +540,106 @@ func run(_ *cobra.Command, _ []string) error {
 		logger.ErrorLogger.Printf("Error stopping adapter: %v", err)
 	}
 
+	if recorder != nil {
+		if err := recorder.Stop(); err != nil {
+			logger.ErrorLogger.Printf("Error archiving event: %v", err)
+		}
+	}
+
+	if saver != nil {
+		if err := saver.Stop(); err != nil {
+			logger.ErrorLogger.Printf("Error saving state snapshot: %v", err)
+		}
+	}
+
 	logger.InfoLogger.Println("Shutdown complete")
 	return nil
 }
 
+// restoreState loads the snapshot saved with --state-file into the state and makes the
+// MeOS adapter continue from the difference key saved with it. A missing or unreadable
+// snapshot leaves the state empty.
+func restoreState(path string, appState *state.State, meosAdapter *meos.Adapter, saver *persist.Saver) {
+	saved, err := persist.Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.InfoLogger.Printf("No state snapshot at %s yet, starting empty", path)
+		return
+	}
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to restore state snapshot, starting empty: %v", err)
+		return
+	}
+
+	saved.Restore(appState)
+	logger.InfoLogger.Printf("Restored %d classes and %d competitors from the state snapshot saved at %s",
+		len(saved.Classes), len(saved.Competitors), saved.SavedAt.Format(time.RFC3339))
+
+	if meosAdapter != nil && saved.NextDifference != "" {
+		meosAdapter.ResumeFrom(saved.NextDifference)
+		logger.InfoLogger.Printf("Resuming MeOS updates from difference %s", saved.NextDifference)
+	}
+	saver.MarkSaved(appState.Revision(), saved.NextDifference)
+}
+
+// validateDataSourceFlags checks that at most one alternative data source is selected and
+// that the record, replay and push flags are used in a valid combination
+func validateDataSourceFlags() error {
+	var sources []string
+	if cmd.SimulationMode {
+		sources = append(sources, "--simulation")
+	}
+	if cmd.ReplayFile != "" {
+		sources = append(sources, "--replay")
+	}
+	if cmd.PushMode {
+		sources = append(sources, "--push")
+	}
+	if cmd.IOFPath != "" {
+		sources = append(sources, "--iof-xml")
+	}
+	if cmd.ArchiveEvent != 0 {
+		sources = append(sources, "--archive-event")
+	}
+	if len(sources) > 1 {
+		return fmt.Errorf("only one data source can be selected, got %s", strings.Join(sources, " and "))
+	}
+
+	if cmd.RecordFile != "" && (cmd.SimulationMode || cmd.ReplayFile != "" || cmd.IOFPath != "" || cmd.ArchiveEvent != 0) {
+		return fmt.Errorf("--record can only be used when receiving data from MeOS")
+	}
+	if cmd.ReplaySpeed <= 0 {
+		return fmt.Errorf("replay speed must be positive: %g", cmd.ReplaySpeed)
+	}
+	if cmd.ReplaySpeed != 1 && cmd.ReplayFile == "" {
+		return fmt.Errorf("--replay-speed can only be used with --replay")
+	}
+	if cmd.StateFile != "" && (cmd.SimulationMode || cmd.ReplayFile != "" || cmd.IOFPath != "" || cmd.ArchiveEvent != 0) {
+		return fmt.Errorf("--state-file can only be used when receiving data from MeOS")
+	}
+	if cmd.StateInterval <= 0 {
+		return fmt.Errorf("state interval must be positive: %s", cmd.StateInterval)
+	}
+	if cmd.StateInterval != 10*time.Second && cmd.StateFile == "" {
+		return fmt.Errorf("--state-interval can only be used with --state-file")
+	}
+	if cmd.ArchiveEvent != 0 && cmd.ArchivePath == "" {
+		return fmt.Errorf("--archive-event requires --archive")
+	}
+	if cmd.ArchivePath != "" && cmd.SimulationMode {
+		return fmt.Errorf("--archive cannot be used with --simulation")
+	}
+	if cmd.HistoryRetention <= 0 {
+		return fmt.Errorf("history retention must be positive: %s", cmd.HistoryRetention)
+	}
+	if cmd.PushSecret != "" && !cmd.PushMode {
+		return fmt.Errorf("--push-secret can only be used with --push")
+	}
+	if cmd.PushMode && cmd.PushSecret == "" {
+		return fmt.Errorf("--push requires --push-secret")
+	}
+	return nil
+}
+
 // getStaticPath returns the path to the static files directory.
 // It works correctly whether running with 'go run' from any directory
 // or from a compiled binary.
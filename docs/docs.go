@@ -24,6 +24,268 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/overrides": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "List the competitor overrides set through the admin API, ordered by competitor ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List the manual overrides",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/overrides.Override"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Clear every competitor override so the data from MeOS is shown again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Clear all overrides",
+                "responses": {
+                    "200": {
+                        "description": "Number of overrides cleared",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/overrides/{competitorId}": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Replace the override of a competitor. The name, club and status replace the values from MeOS, hidden leaves the competitor out of every list and the time adjustment in seconds is added to the running time at the finish, which is only allowed for competitors who have finished and must keep the finish after the start. Overridden competitors are flagged with overridden in all responses.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Override a competitor's data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Competitor ID",
+                        "name": "competitorId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to override",
+                        "name": "override",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OverrideRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/overrides.Override"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Clear the override of a competitor so the data from MeOS is shown again",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Clear a competitor's override",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Competitor ID",
+                        "name": "competitorId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Override cleared"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "description": "Get the changes of competitors' status, start time, finish time, class and club received from MeOS, with the values before and after each change. Entries are in chronological order; the most recent ones are returned when there are more than the limit. The server keeps the last 10000 entries.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Get the audit log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only changes of this competitor",
+                        "name": "competitor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only changes of competitors in this class, including moves out of it",
+                        "name": "class",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes received at or after this time, in RFC 3339 format",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes received before this time, in RFC 3339 format",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries (default 100, at most 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/audit.Entry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/classes": {
             "get": {
                 "description": "Get a list of all competition classes sorted by order key",
@@ -37,6 +299,20 @@ const docTemplate = `{
                     "classes"
                 ],
                 "summary": "Get all competition classes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -45,14 +321,27 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/service.ClassInfo"
                             }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
                         }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
                     }
                 }
             }
         },
-        "/classes/{classId}/results": {
+        "/classes/{classId}/changeovers": {
             "get": {
-                "description": "Get the results for a specific competition class including positions and times",
+                "description": "Get all completed relay changeovers for a class in chronological order",
                 "consumes": [
                     "application/json"
                 ],
@@ -60,9 +349,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "classes"
+                    "relay"
                 ],
-                "summary": "Get results for a class",
+                "summary": "Get relay changeover times for a class",
                 "parameters": [
                     {
                         "type": "integer",
@@ -70,6 +359,18 @@ const docTemplate = `{
                         "name": "classId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -78,10 +379,23 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/service.ResultEntry"
+                                "$ref": "#/definitions/service.ChangeoverEntry"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
                             }
                         }
                     },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -91,8 +405,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -103,19 +417,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/classes/{classId}/splits": {
+        "/classes/{classId}/iof/resultlist": {
             "get": {
-                "description": "Get split times at each control for a specific competition class",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the results of a single class as an IOF XML 3.0 ResultList document, including split times at radio controls. Relay classes are listed as team results with a result per leg.",
                 "produces": [
-                    "application/json"
+                    "text/xml"
                 ],
                 "tags": [
-                    "classes"
+                    "iof"
                 ],
-                "summary": "Get split times for a class",
+                "summary": "Get IOF XML 3.0 result list for a class",
                 "parameters": [
                     {
                         "type": "integer",
@@ -123,15 +434,40 @@ const docTemplate = `{
                         "name": "classId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/service.SplitsResponse"
+                            "$ref": "#/definitions/iof.ResultList"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -153,19 +489,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/classes/{classId}/startlist": {
+        "/classes/{classId}/iof/startlist": {
             "get": {
-                "description": "Get the start list for a specific competition class",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the start list of a single class as an IOF XML 3.0 StartList document",
                 "produces": [
-                    "application/json"
+                    "text/xml"
                 ],
                 "tags": [
-                    "classes"
+                    "iof"
                 ],
-                "summary": "Get start list for a class",
+                "summary": "Get IOF XML 3.0 start list for a class",
                 "parameters": [
                     {
                         "type": "integer",
@@ -173,18 +506,40 @@ const docTemplate = `{
                         "name": "classId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/service.StartListEntry"
+                            "$ref": "#/definitions/iof.StartList"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
                             }
                         }
                     },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -194,8 +549,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -205,19 +560,2110 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "service.ClassInfo": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
+        },
+        "/classes/{classId}/legs/{leg}": {
+            "get": {
+                "description": "Get the team standings at the end of a relay leg, including leg times and leg ranks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relay"
+                ],
+                "summary": "Get standings after a relay leg",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "classId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Leg number (1-based)",
+                        "name": "leg",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.LegStandingsResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/classes/{classId}/predictions": {
+            "get": {
+                "description": "Get the predicted finish time and virtual position of each running competitor who has passed a radio control. The prediction compares the competitor's time at the last radio control with the times of the fastest competitors there and at the finish.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classes"
+                ],
+                "summary": "Get predicted finish times for a class",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "classId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Prediction"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/classes/{classId}/results": {
+            "get": {
+                "description": "Get the results for a specific competition class including positions and times",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classes"
+                ],
+                "summary": "Get results for a class",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "classId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.ResultEntry"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/classes/{classId}/splits": {
+            "get": {
+                "description": "Get split times at each control for a specific competition class",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classes"
+                ],
+                "summary": "Get split times for a class",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "classId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.SplitsResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/classes/{classId}/startlist": {
+            "get": {
+                "description": "Get the start list for a specific competition class",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "classes"
+                ],
+                "summary": "Get start list for a class",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "classId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.StartListEntry"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/classes/{classId}/teams": {
+            "get": {
+                "description": "Get the relay team results for a specific class including leg times and changeover times",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relay"
+                ],
+                "summary": "Get relay team results for a class",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Class ID",
+                        "name": "classId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.TeamResultEntry"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/clubs": {
+            "get": {
+                "description": "Get a list of all clubs with at least one competitor, sorted by name, with the number of competitors each",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clubs"
+                ],
+                "summary": "Get all clubs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.ClubInfo"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    }
+                }
+            }
+        },
+        "/clubs/{clubId}": {
+            "get": {
+                "description": "Get all of a club's competitors across the classes with their current status and result, sorted by class and then by position",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clubs"
+                ],
+                "summary": "Get a club's competitors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Club ID",
+                        "name": "clubId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ClubDetail"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/competitors/{competitorId}": {
+            "get": {
+                "description": "Get a competitor's club, class, card, status, start and finish times, running time and current position, with every split ranked within the class including leg times, and the predicted finish while running",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "competitors"
+                ],
+                "summary": "Get a competitor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Competitor ID",
+                        "name": "competitorId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.CompetitorDetail"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/controls": {
+            "get": {
+                "description": "Get all radio controls sorted by ID, with the number of passings recorded at each and the classes whose course includes it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Get all radio controls",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.ControlInfo"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    }
+                }
+            }
+        },
+        "/event": {
+            "get": {
+                "description": "Get the event name, organizer, date and start time with the number of classes, clubs and competitors",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Get the event details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.EventInfo"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/feed": {
+            "get": {
+                "description": "Get competitor events derived from the live data: starts, radio control passings with position, finishes with position, status changes to MP, DNF, DSQ or max time, and new class leaders. The same events are sent over SSE. Items are in chronological order; page backwards with before or poll for new items with after.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feed"
+                ],
+                "summary": "Get the competitor event feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only items after this ID",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only items before this ID",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items (default 50, at most 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/feed.Page"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/iof/resultlist": {
+            "get": {
+                "description": "Get the results of all classes as an IOF XML 3.0 ResultList document, including split times at radio controls. Relay classes are listed as team results with a result per leg. The list status is Snapshot while competitors are still out.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "iof"
+                ],
+                "summary": "Get IOF XML 3.0 result list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/iof.ResultList"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/iof/startlist": {
+            "get": {
+                "description": "Get the start list of all classes as an IOF XML 3.0 StartList document",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "iof"
+                ],
+                "summary": "Get IOF XML 3.0 start list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/iof.StartList"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/mop": {
+            "post": {
+                "description": "Accepts a MOPComplete or MOPDiff document posted by MeOS's online results module. The shared secret is passed in the pwd header. Zipped documents are supported. MeOS reads the outcome from the MOPStatus reply (OK, BADPWD, NOZIP or ERROR).",
+                "consumes": [
+                    "text/xml"
+                ],
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "push"
+                ],
+                "summary": "Receive pushed MOP data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Shared secret",
+                        "name": "pwd",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "MeOS competition ID",
+                        "name": "competition",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/push.MOPStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "Find competitors by partial name, club name, SI card number or bib. Every word of the query has to match, ignoring case and accents. Card numbers match from the start and bibs match exactly. At most 50 competitors are returned, names starting with the query first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "competitors"
+                ],
+                "summary": "Search competitors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously received response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404.",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.SearchResult"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Tag of the state the response was built from"
+                            },
+                            "X-State-Revision": {
+                                "type": "integer",
+                                "description": "State revision the response was built from"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "audit.Entry": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "string"
+                },
+                "afterId": {
+                    "type": "integer"
+                },
+                "before": {
+                    "description": "Values before and after the change: the MeOS status code, the time in RFC 3339\nformat, or the class or club name. Times that are not set are empty.",
+                    "type": "string"
+                },
+                "beforeId": {
+                    "description": "Class or club IDs before and after a class or club change",
+                    "type": "integer"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "classId": {
+                    "type": "integer"
+                },
+                "competitorId": {
+                    "type": "integer"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "State revision that has the new value",
+                    "type": "integer"
+                },
+                "time": {
+                    "description": "When the change was received",
+                    "type": "string"
+                }
+            }
+        },
+        "encoding_xml.Name": {
+            "type": "object",
+            "properties": {
+                "local": {
+                    "type": "string"
+                },
+                "space": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.Item": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "classId": {
+                    "type": "integer"
+                },
+                "club": {
+                    "type": "string"
+                },
+                "clubId": {
+                    "type": "integer"
+                },
+                "competitorId": {
+                    "type": "integer"
+                },
+                "control": {
+                    "type": "string"
+                },
+                "controlId": {
+                    "description": "Radio control of a split",
+                    "type": "integer"
+                },
+                "difference": {
+                    "type": "string"
+                },
+                "elapsedTime": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Position and time at the control or finish",
+                    "type": "integer"
+                },
+                "previousLeader": {
+                    "type": "string"
+                },
+                "previousLeaderId": {
+                    "description": "Leader that was replaced by a new class leader",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "statusText": {
+                    "type": "string"
+                },
+                "time": {
+                    "description": "Time is the start, passing or finish time, or when a status or leader change was seen",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.Page": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "description": "HasMore is set when there are more items in the paging direction: older ones\nwithout after, newer ones with after",
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/feed.Item"
+                    }
+                }
+            }
+        },
+        "handlers.OverrideRequest": {
+            "type": "object",
+            "properties": {
+                "clubId": {
+                    "type": "integer"
+                },
+                "hidden": {
+                    "description": "Leaves the competitor out of every list",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "MeOS status code, such as \"5\" for disqualified",
+                    "type": "string"
+                },
+                "timeAdjustment": {
+                    "description": "Seconds added to the running time at the finish",
+                    "type": "integer"
+                }
+            }
+        },
+        "iof.Class": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "shortName": {
+                    "type": "string"
+                }
+            }
+        },
+        "iof.ClassResult": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "$ref": "#/definitions/iof.Class"
+                },
+                "personResults": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.PersonResult"
+                    }
+                },
+                "teamResults": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.TeamResult"
+                    }
+                }
+            }
+        },
+        "iof.ClassStart": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "$ref": "#/definitions/iof.Class"
+                },
+                "personStarts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.PersonStart"
+                    }
+                },
+                "teamStarts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.TeamStart"
+                    }
+                }
+            }
+        },
+        "iof.Country": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "iof.DateAndOptionalTime": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "iof.Event": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organisers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.Organisation"
+                    }
+                },
+                "startTime": {
+                    "$ref": "#/definitions/iof.DateAndOptionalTime"
+                }
+            }
+        },
+        "iof.Organisation": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "$ref": "#/definitions/iof.Country"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "shortName": {
+                    "type": "string"
+                }
+            }
+        },
+        "iof.OverallResult": {
+            "type": "object",
+            "properties": {
+                "position": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "number"
+                },
+                "timeBehind": {
+                    "type": "number"
+                }
+            }
+        },
+        "iof.Person": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "$ref": "#/definitions/iof.PersonName"
+                }
+            }
+        },
+        "iof.PersonName": {
+            "type": "object",
+            "properties": {
+                "family": {
+                    "type": "string"
+                },
+                "given": {
+                    "type": "string"
+                }
+            }
+        },
+        "iof.PersonRaceResult": {
+            "type": "object",
+            "properties": {
+                "bibNumber": {
+                    "type": "string"
+                },
+                "controlCard": {
+                    "type": "string"
+                },
+                "finishTime": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "splitTimes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.SplitTime"
+                    }
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "number"
+                },
+                "timeBehind": {
+                    "type": "number"
+                }
+            }
+        },
+        "iof.PersonRaceStart": {
+            "type": "object",
+            "properties": {
+                "bibNumber": {
+                    "type": "string"
+                },
+                "controlCard": {
+                    "type": "string"
+                },
+                "startTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "iof.PersonResult": {
+            "type": "object",
+            "properties": {
+                "organisation": {
+                    "$ref": "#/definitions/iof.Organisation"
+                },
+                "person": {
+                    "$ref": "#/definitions/iof.Person"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.PersonRaceResult"
+                    }
+                }
+            }
+        },
+        "iof.PersonStart": {
+            "type": "object",
+            "properties": {
+                "organisation": {
+                    "$ref": "#/definitions/iof.Organisation"
+                },
+                "person": {
+                    "$ref": "#/definitions/iof.Person"
+                },
+                "starts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.PersonRaceStart"
+                    }
+                }
+            }
+        },
+        "iof.ResultList": {
+            "type": "object",
+            "properties": {
+                "classResults": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.ClassResult"
+                    }
+                },
+                "createTime": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/iof.Event"
+                },
+                "iofversion": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "xmlname": {
+                    "$ref": "#/definitions/encoding_xml.Name"
+                },
+                "xmlns": {
+                    "type": "string"
+                }
+            }
+        },
+        "iof.SplitTime": {
+            "type": "object",
+            "properties": {
+                "controlCode": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "number"
+                }
+            }
+        },
+        "iof.StartList": {
+            "type": "object",
+            "properties": {
+                "classStarts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.ClassStart"
+                    }
+                },
+                "createTime": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/iof.Event"
+                },
+                "iofversion": {
+                    "type": "string"
+                },
+                "xmlname": {
+                    "$ref": "#/definitions/encoding_xml.Name"
+                },
+                "xmlns": {
+                    "type": "string"
+                }
+            }
+        },
+        "iof.TeamMemberRaceResult": {
+            "type": "object",
+            "properties": {
+                "bibNumber": {
+                    "type": "string"
+                },
+                "controlCard": {
+                    "type": "string"
+                },
+                "finishTime": {
+                    "type": "string"
+                },
+                "leg": {
+                    "type": "integer"
+                },
+                "legOrder": {
+                    "type": "integer"
+                },
+                "overallResult": {
+                    "$ref": "#/definitions/iof.OverallResult"
+                },
+                "splitTimes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.SplitTime"
+                    }
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "number"
+                }
+            }
+        },
+        "iof.TeamMemberRaceStart": {
+            "type": "object",
+            "properties": {
+                "bibNumber": {
+                    "type": "string"
+                },
+                "controlCard": {
+                    "type": "string"
+                },
+                "leg": {
+                    "type": "integer"
+                },
+                "legOrder": {
+                    "type": "integer"
+                },
+                "startTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "iof.TeamMemberResult": {
+            "type": "object",
+            "properties": {
+                "organisation": {
+                    "$ref": "#/definitions/iof.Organisation"
+                },
+                "person": {
+                    "$ref": "#/definitions/iof.Person"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.TeamMemberRaceResult"
+                    }
+                }
+            }
+        },
+        "iof.TeamMemberStart": {
+            "type": "object",
+            "properties": {
+                "organisation": {
+                    "$ref": "#/definitions/iof.Organisation"
+                },
+                "person": {
+                    "$ref": "#/definitions/iof.Person"
+                },
+                "starts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.TeamMemberRaceStart"
+                    }
+                }
+            }
+        },
+        "iof.TeamResult": {
+            "type": "object",
+            "properties": {
+                "bibNumber": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.TeamMemberResult"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organisations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.Organisation"
+                    }
+                }
+            }
+        },
+        "iof.TeamStart": {
+            "type": "object",
+            "properties": {
+                "bibNumber": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.TeamMemberStart"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organisations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iof.Organisation"
+                    }
+                }
+            }
+        },
+        "overrides.Override": {
+            "type": "object",
+            "properties": {
+                "clubId": {
+                    "type": "integer"
+                },
+                "competitorId": {
+                    "type": "integer"
+                },
+                "hidden": {
+                    "description": "Leaves the competitor out of every list",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "MeOS status code, such as \"5\" for disqualified",
+                    "type": "string"
+                },
+                "timeAdjustment": {
+                    "description": "Seconds added to the running time at the finish",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "push.MOPStatus": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                },
+                "xmlname": {
+                    "$ref": "#/definitions/encoding_xml.Name"
+                }
+            }
+        },
+        "service.ChangeoverEntry": {
+            "type": "object",
+            "properties": {
+                "club": {
+                    "type": "string"
+                },
+                "elapsedTime": {
+                    "description": "Formatted team time at the changeover",
+                    "type": "string"
+                },
+                "incoming": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "leg": {
+                    "description": "Leg that was completed",
+                    "type": "integer"
+                },
+                "outgoing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "time": {
+                    "description": "Clock time of the changeover, formatted as HH:mm:ss",
+                    "type": "string"
+                }
+            }
+        },
+        "service.ClassInfo": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "orderKey": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ClubCompetitor": {
+            "type": "object",
+            "properties": {
+                "bib": {
+                    "type": "string"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "classId": {
+                    "type": "integer"
+                },
+                "competitorId": {
+                    "type": "integer"
+                },
+                "difference": {
+                    "description": "Formatted duration from the winner",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overridden": {
+                    "description": "Set when an admin has overridden the competitor's data",
+                    "type": "boolean"
+                },
+                "position": {
+                    "description": "Position in the class results",
+                    "type": "integer"
+                },
+                "runningTime": {
+                    "type": "string"
+                },
+                "startTime": {
+                    "description": "Formatted as HH:mm:ss",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status description, including running and waiting to start",
+                    "type": "string"
+                }
+            }
+        },
+        "service.ClubDetail": {
+            "type": "object",
+            "properties": {
+                "competitors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ClubCompetitor"
+                    }
+                },
+                "country": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ClubInfo": {
+            "type": "object",
+            "properties": {
+                "competitors": {
+                    "type": "integer"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.CompetitorDetail": {
+            "type": "object",
+            "properties": {
+                "bib": {
+                    "type": "string"
+                },
+                "card": {
+                    "type": "integer"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "classId": {
+                    "type": "integer"
+                },
+                "club": {
+                    "type": "string"
+                },
+                "clubId": {
+                    "type": "integer"
+                },
+                "difference": {
+                    "description": "Formatted duration from the winner",
+                    "type": "string"
+                },
+                "finishTime": {
+                    "description": "Formatted as HH:mm:ss",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overridden": {
+                    "description": "Set when an admin has overridden the competitor's data",
+                    "type": "boolean"
+                },
+                "position": {
+                    "description": "Current position in the class results",
+                    "type": "integer"
+                },
+                "prediction": {
+                    "description": "Predicted finish while running",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Prediction"
+                        }
+                    ]
+                },
+                "runningTime": {
+                    "type": "string"
+                },
+                "splits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.CompetitorSplit"
+                    }
+                },
+                "startTime": {
+                    "description": "Formatted as HH:mm:ss",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status description, including running and waiting to start",
+                    "type": "string"
+                }
+            }
+        },
+        "service.CompetitorSplit": {
+            "type": "object",
+            "properties": {
+                "bestLeg": {
+                    "type": "boolean"
+                },
+                "controlId": {
+                    "description": "-1 for the finish",
+                    "type": "integer"
+                },
+                "controlName": {
+                    "type": "string"
+                },
+                "elapsedTime": {
+                    "type": "string"
+                },
+                "legDifference": {
+                    "description": "Time lost to the best leg time",
+                    "type": "string"
+                },
+                "legPosition": {
+                    "type": "integer"
+                },
+                "legTime": {
+                    "description": "Time from the previous control, or the start for the first control",
+                    "type": "string"
+                },
+                "passingTime": {
+                    "description": "Clock time, formatted as HH:mm:ss",
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Rank at the control",
+                    "type": "integer"
+                },
+                "timeDifference": {
+                    "description": "Formatted duration from the best time at the control",
+                    "type": "string"
+                }
+            }
+        },
+        "service.ControlClass": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ControlInfo": {
+            "type": "object",
+            "properties": {
+                "classes": {
+                    "description": "Sorted by order key",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ControlClass"
+                    }
+                },
+                "id": {
+                    "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
-                "orderKey": {
+                "passings": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.EventInfo": {
+            "type": "object",
+            "properties": {
+                "classes": {
+                    "type": "integer"
+                },
+                "clubs": {
+                    "type": "integer"
+                },
+                "competitors": {
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "Formatted as YYYY-MM-DD",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organizer": {
+                    "type": "string"
+                },
+                "startTime": {
+                    "description": "Formatted as HH:mm:ss",
+                    "type": "string"
+                }
+            }
+        },
+        "service.LegStandingEntry": {
+            "type": "object",
+            "properties": {
+                "changeoverTime": {
+                    "description": "Formatted as HH:mm:ss",
+                    "type": "string"
+                },
+                "club": {
+                    "type": "string"
+                },
+                "difference": {
+                    "description": "Formatted duration from leader",
+                    "type": "string"
+                },
+                "elapsedTime": {
+                    "description": "Formatted team time at the end of the leg",
+                    "type": "string"
+                },
+                "legPosition": {
+                    "type": "integer"
+                },
+                "legTime": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "runners": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                },
+                "teamId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.LegStandingsResponse": {
+            "type": "object",
+            "properties": {
+                "className": {
+                    "type": "string"
+                },
+                "leg": {
+                    "type": "integer"
+                },
+                "legs": {
+                    "description": "Total number of legs in the class",
+                    "type": "integer"
+                },
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.LegStandingEntry"
+                    }
+                }
+            }
+        },
+        "service.Prediction": {
+            "type": "object",
+            "properties": {
+                "club": {
+                    "type": "string"
+                },
+                "competitorId": {
+                    "type": "integer"
+                },
+                "lastControl": {
+                    "description": "Last radio control passed, which the prediction is based on",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overridden": {
+                    "description": "Set when an admin has overridden the competitor's data",
+                    "type": "boolean"
+                },
+                "predictedFinish": {
+                    "description": "Predicted clock time at the finish, formatted as HH:mm:ss",
+                    "type": "string"
+                },
+                "predictedTime": {
+                    "description": "Formatted predicted running time",
+                    "type": "string"
+                },
+                "virtualPosition": {
+                    "description": "Position among the finishers at the predicted time",
                     "type": "integer"
                 }
             }
@@ -235,13 +2681,65 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "overridden": {
+                    "description": "Set when an admin has overridden the competitor's data",
+                    "type": "boolean"
+                },
                 "position": {
                     "type": "integer"
                 },
+                "predictedTime": {
+                    "description": "Set for running competitors who have passed a radio control",
+                    "type": "string"
+                },
                 "runningTime": {
                     "description": "Formatted duration string",
                     "type": "string"
                 },
+                "status": {
+                    "type": "string"
+                },
+                "virtualPosition": {
+                    "description": "Position at the predicted time",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.SearchResult": {
+            "type": "object",
+            "properties": {
+                "bib": {
+                    "type": "string"
+                },
+                "card": {
+                    "type": "integer"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "classId": {
+                    "type": "integer"
+                },
+                "club": {
+                    "type": "string"
+                },
+                "clubId": {
+                    "type": "integer"
+                },
+                "competitorId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overridden": {
+                    "description": "Set when an admin has overridden the competitor's data",
+                    "type": "boolean"
+                },
+                "startTime": {
+                    "description": "Formatted as HH:mm:ss",
+                    "type": "string"
+                },
                 "status": {
                     "type": "string"
                 }
@@ -250,12 +2748,20 @@ const docTemplate = `{
         "service.SplitStanding": {
             "type": "object",
             "properties": {
+                "bestLegTime": {
+                    "description": "Fastest leg time",
+                    "type": "string"
+                },
                 "controlId": {
                     "type": "integer"
                 },
                 "controlName": {
                     "type": "string"
                 },
+                "legFrom": {
+                    "description": "Control the leg starts at, \"Start\" for the first control",
+                    "type": "string"
+                },
                 "standings": {
                     "type": "array",
                     "items": {
@@ -267,15 +2773,38 @@ const docTemplate = `{
         "service.SplitTime": {
             "type": "object",
             "properties": {
+                "bestLeg": {
+                    "description": "Set for the fastest leg time",
+                    "type": "boolean"
+                },
                 "club": {
                     "type": "string"
                 },
+                "competitorId": {
+                    "type": "integer"
+                },
                 "elapsedTime": {
                     "type": "string"
                 },
+                "legDifference": {
+                    "description": "Time lost to the best leg time",
+                    "type": "string"
+                },
+                "legPosition": {
+                    "description": "Rank of the leg time",
+                    "type": "integer"
+                },
+                "legTime": {
+                    "description": "Time from the previous control, or the start for the first control",
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
+                "overridden": {
+                    "description": "Set when an admin has overridden the competitor's data",
+                    "type": "boolean"
+                },
                 "position": {
                     "type": "integer"
                 },
@@ -290,6 +2819,13 @@ const docTemplate = `{
                 "className": {
                     "type": "string"
                 },
+                "predictions": {
+                    "description": "Predicted finishes of the running competitors, fastest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Prediction"
+                    }
+                },
                 "splits": {
                     "type": "array",
                     "items": {
@@ -307,11 +2843,86 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "overridden": {
+                    "description": "Set when an admin has overridden the competitor's data",
+                    "type": "boolean"
+                },
                 "startTime": {
                     "description": "Formatted as HH:mm",
                     "type": "string"
                 }
             }
+        },
+        "service.TeamLegResult": {
+            "type": "object",
+            "properties": {
+                "changeoverTime": {
+                    "description": "Clock time the leg was completed, formatted as HH:mm:ss",
+                    "type": "string"
+                },
+                "elapsedTime": {
+                    "description": "Formatted team time at the end of the leg",
+                    "type": "string"
+                },
+                "leg": {
+                    "type": "integer"
+                },
+                "legTime": {
+                    "description": "Formatted duration of the leg",
+                    "type": "string"
+                },
+                "runners": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.TeamResultEntry": {
+            "type": "object",
+            "properties": {
+                "bib": {
+                    "type": "string"
+                },
+                "club": {
+                    "type": "string"
+                },
+                "difference": {
+                    "description": "Formatted duration from leader",
+                    "type": "string"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.TeamLegResult"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "runningTime": {
+                    "description": "Formatted duration string",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin token given with --admin-token, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
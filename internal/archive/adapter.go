@@ -0,0 +1,47 @@
+package archive
+
+import (
+	"fmt"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/state"
+)
+
+// Adapter serves an archived event read-only. It loads the event once and never changes it.
+type Adapter struct {
+	path    string
+	eventID int64
+	state   *state.State
+}
+
+// NewAdapter creates an adapter serving the event with the given ID from the archive at path
+func NewAdapter(path string, eventID int64, appState *state.State) *Adapter {
+	return &Adapter{path: path, eventID: eventID, state: appState}
+}
+
+// Connect loads the archived event into the state
+func (a *Adapter) Connect() error {
+	archive, err := OpenReadOnly(a.path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	if err := archive.Load(a.eventID, a.state); err != nil {
+		return fmt.Errorf("failed to load event %d from %s: %w", a.eventID, a.path, err)
+	}
+
+	event := a.state.GetEvent()
+	logger.InfoLogger.Printf("Serving archived event %d (%s) with %d competitors", a.eventID, event.Name, len(a.state.GetCompetitors()))
+	return nil
+}
+
+// StartPolling does nothing as an archived event does not change
+func (a *Adapter) StartPolling() error {
+	return nil
+}
+
+// Stop does nothing as there is nothing to stop
+func (a *Adapter) Stop() error {
+	return nil
+}
@@ -0,0 +1,597 @@
+package archive
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // Registers the pure Go SQLite driver
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS events (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	organizer TEXT NOT NULL,
+	start TEXT NOT NULL,
+	first_seen TEXT NOT NULL,
+	last_updated TEXT NOT NULL,
+	UNIQUE (name, start)
+);
+CREATE TABLE IF NOT EXISTS controls (
+	event_id INTEGER NOT NULL REFERENCES events (id),
+	id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	PRIMARY KEY (event_id, id)
+);
+CREATE TABLE IF NOT EXISTS classes (
+	event_id INTEGER NOT NULL REFERENCES events (id),
+	id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	order_key INTEGER NOT NULL,
+	PRIMARY KEY (event_id, id)
+);
+CREATE TABLE IF NOT EXISTS class_controls (
+	event_id INTEGER NOT NULL REFERENCES events (id),
+	class_id INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	control_id INTEGER NOT NULL,
+	PRIMARY KEY (event_id, class_id, position)
+);
+CREATE TABLE IF NOT EXISTS clubs (
+	event_id INTEGER NOT NULL REFERENCES events (id),
+	id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	country TEXT NOT NULL,
+	PRIMARY KEY (event_id, id)
+);
+CREATE TABLE IF NOT EXISTS competitors (
+	event_id INTEGER NOT NULL REFERENCES events (id),
+	id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	card INTEGER NOT NULL,
+	bib TEXT NOT NULL,
+	club_id INTEGER NOT NULL,
+	class_id INTEGER NOT NULL,
+	status TEXT NOT NULL,
+	start_time TEXT NOT NULL,
+	finish_time TEXT,
+	team_id INTEGER NOT NULL,
+	leg INTEGER NOT NULL,
+	PRIMARY KEY (event_id, id)
+);
+CREATE TABLE IF NOT EXISTS splits (
+	event_id INTEGER NOT NULL REFERENCES events (id),
+	competitor_id INTEGER NOT NULL,
+	control_id INTEGER NOT NULL,
+	passing_time TEXT NOT NULL,
+	PRIMARY KEY (event_id, competitor_id, control_id)
+);
+CREATE TABLE IF NOT EXISTS status_history (
+	event_id INTEGER NOT NULL REFERENCES events (id),
+	competitor_id INTEGER NOT NULL,
+	recorded_at TEXT NOT NULL,
+	status TEXT NOT NULL,
+	finish_time TEXT
+);
+CREATE INDEX IF NOT EXISTS status_history_competitor ON status_history (event_id, competitor_id);
+CREATE TABLE IF NOT EXISTS teams (
+	event_id INTEGER NOT NULL REFERENCES events (id),
+	id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	bib TEXT NOT NULL,
+	club_id INTEGER NOT NULL,
+	class_id INTEGER NOT NULL,
+	status TEXT NOT NULL,
+	start_time TEXT NOT NULL,
+	finish_time TEXT,
+	PRIMARY KEY (event_id, id)
+);
+CREATE TABLE IF NOT EXISTS team_legs (
+	event_id INTEGER NOT NULL REFERENCES events (id),
+	team_id INTEGER NOT NULL,
+	leg INTEGER NOT NULL,
+	competitor_id INTEGER NOT NULL,
+	PRIMARY KEY (event_id, team_id, leg, competitor_id)
+);
+`
+
+// ErrEventNotFound is returned when an archived event does not exist
+var ErrEventNotFound = errors.New("archived event not found")
+
+// Event summarizes an archived event
+type Event struct {
+	ID          int64
+	Name        string
+	Organizer   string
+	Start       time.Time
+	FirstSeen   time.Time
+	LastUpdated time.Time
+	Competitors int
+}
+
+// StatusChange is a competitor's status as archived at the time it changed
+type StatusChange struct {
+	RecordedAt time.Time
+	Status     string
+	FinishTime *time.Time
+}
+
+// Archive stores the events the server has ingested in a SQLite database, keyed by event
+// name and start
+type Archive struct {
+	db *sql.DB
+}
+
+// Open opens the archive database at path for recording, creating it if it does not exist
+func Open(path string) (*Archive, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids busy errors between our own writes
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create archive schema: %w", err)
+	}
+	return &Archive{db: db}, nil
+}
+
+// OpenReadOnly opens an existing archive database at path for reading. Unlike Open it fails
+// when there is no database at path instead of creating an empty one.
+func OpenReadOnly(path string) (*Archive, error) {
+	// SQLite reports a missing file as a vague "unable to open database file"
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	// SQLite URI filenames treat these characters specially, so escape them in the path
+	escaped := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(path)
+	db, err := sql.Open("sqlite", "file:"+escaped+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open archive %s: %w", path, err)
+	}
+	return &Archive{db: db}, nil
+}
+
+// Close closes the archive database
+func (a *Archive) Close() error {
+	return a.db.Close()
+}
+
+// Events returns the archived events, most recent start first
+func (a *Archive) Events() ([]Event, error) {
+	rows, err := a.db.Query(`
+		SELECT e.id, e.name, e.organizer, e.start, e.first_seen, e.last_updated,
+			(SELECT COUNT(*) FROM competitors c WHERE c.event_id = e.id)
+		FROM events e
+		ORDER BY e.start DESC, e.id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		var start, firstSeen, lastUpdated string
+		if err := rows.Scan(&event.ID, &event.Name, &event.Organizer, &start, &firstSeen, &lastUpdated, &event.Competitors); err != nil {
+			return nil, fmt.Errorf("failed to read archived event: %w", err)
+		}
+		event.Start = parseTime(start)
+		event.FirstSeen = parseTime(firstSeen)
+		event.LastUpdated = parseTime(lastUpdated)
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// StatusHistory returns every status a competitor has had, oldest first
+func (a *Archive) StatusHistory(eventID int64, competitorID int) ([]StatusChange, error) {
+	rows, err := a.db.Query(`
+		SELECT recorded_at, status, finish_time FROM status_history
+		WHERE event_id = ? AND competitor_id = ?
+		ORDER BY rowid`, eventID, competitorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []StatusChange
+	for rows.Next() {
+		var change StatusChange
+		var recordedAt string
+		var finish sql.NullString
+		if err := rows.Scan(&recordedAt, &change.Status, &finish); err != nil {
+			return nil, fmt.Errorf("failed to read status history: %w", err)
+		}
+		change.RecordedAt = parseTime(recordedAt)
+		change.FinishTime = parseNullTime(finish)
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+// store writes the event of a snapshot to the archive. Controls, classes and clubs are
+// always replaced; competitors and teams only for the given IDs, or all of them when all is
+// set. Entities in the lists that no longer exist are removed. It returns the archive ID of
+// the event, or 0 when the snapshot has no event yet.
+func (a *Archive) store(snap *state.Snapshot, competitorIDs, teamIDs []int, all bool) (int64, error) {
+	event := snap.Event()
+	if event == nil {
+		return 0, nil
+	}
+	now := time.Now()
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start archive transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var eventID int64
+	err = tx.QueryRow(`
+		INSERT INTO events (name, organizer, start, first_seen, last_updated) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (name, start) DO UPDATE SET organizer = excluded.organizer, last_updated = excluded.last_updated
+		RETURNING id`,
+		event.Name, event.Organizer, formatTime(event.Start), formatTime(now), formatTime(now)).Scan(&eventID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive event: %w", err)
+	}
+
+	for _, table := range []string{"controls", "classes", "class_controls", "clubs"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE event_id = ?`, eventID); err != nil {
+			return 0, fmt.Errorf("failed to clear archived %s: %w", table, err)
+		}
+	}
+	for _, ctrl := range snap.Controls() {
+		if _, err := tx.Exec(`INSERT INTO controls (event_id, id, name) VALUES (?, ?, ?)`, eventID, ctrl.ID, ctrl.Name); err != nil {
+			return 0, fmt.Errorf("failed to archive control %d: %w", ctrl.ID, err)
+		}
+	}
+	for _, class := range snap.Classes() {
+		if _, err := tx.Exec(`INSERT INTO classes (event_id, id, name, order_key) VALUES (?, ?, ?, ?)`,
+			eventID, class.ID, class.Name, class.OrderKey); err != nil {
+			return 0, fmt.Errorf("failed to archive class %d: %w", class.ID, err)
+		}
+		for i, ctrl := range class.RadioControls {
+			if _, err := tx.Exec(`INSERT INTO class_controls (event_id, class_id, position, control_id) VALUES (?, ?, ?, ?)`,
+				eventID, class.ID, i, ctrl.ID); err != nil {
+				return 0, fmt.Errorf("failed to archive radio controls of class %d: %w", class.ID, err)
+			}
+		}
+	}
+	for _, club := range snap.Clubs() {
+		if _, err := tx.Exec(`INSERT INTO clubs (event_id, id, name, country) VALUES (?, ?, ?, ?)`,
+			eventID, club.ID, club.Name, club.CountryCode); err != nil {
+			return 0, fmt.Errorf("failed to archive club %d: %w", club.ID, err)
+		}
+	}
+
+	if all {
+		competitorIDs = competitorIDs[:0:0]
+		for _, comp := range snap.Competitors() {
+			competitorIDs = append(competitorIDs, comp.ID)
+		}
+		teamIDs = teamIDs[:0:0]
+		for _, team := range snap.Teams() {
+			teamIDs = append(teamIDs, team.ID)
+		}
+	}
+	for _, id := range competitorIDs {
+		comp, ok := snap.Competitor(id)
+		if err := storeCompetitor(tx, eventID, id, comp, ok, now); err != nil {
+			return 0, err
+		}
+	}
+	for _, id := range teamIDs {
+		team, ok := snap.Team(id)
+		if err := storeTeam(tx, eventID, id, team, ok); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit archive transaction: %w", err)
+	}
+	return eventID, nil
+}
+
+// storeCompetitor replaces a competitor and their splits, or removes them when they no
+// longer exist, and appends their status to the history when it changed
+func storeCompetitor(tx *sql.Tx, eventID int64, id int, comp models.Competitor, exists bool, now time.Time) error {
+	if _, err := tx.Exec(`DELETE FROM splits WHERE event_id = ? AND competitor_id = ?`, eventID, id); err != nil {
+		return fmt.Errorf("failed to clear archived splits of competitor %d: %w", id, err)
+	}
+	if !exists {
+		if _, err := tx.Exec(`DELETE FROM competitors WHERE event_id = ? AND id = ?`, eventID, id); err != nil {
+			return fmt.Errorf("failed to remove archived competitor %d: %w", id, err)
+		}
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		INSERT OR REPLACE INTO competitors
+			(event_id, id, name, card, bib, club_id, class_id, status, start_time, finish_time, team_id, leg)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		eventID, comp.ID, comp.Name, comp.Card, comp.Bib, comp.Club.ID, comp.Class.ID, comp.Status,
+		formatTime(comp.StartTime), formatNullTime(comp.FinishTime), comp.TeamID, comp.Leg)
+	if err != nil {
+		return fmt.Errorf("failed to archive competitor %d: %w", id, err)
+	}
+	for _, split := range comp.Splits {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO splits (event_id, competitor_id, control_id, passing_time) VALUES (?, ?, ?, ?)`,
+			eventID, id, split.Control.ID, formatTime(split.PassingTime)); err != nil {
+			return fmt.Errorf("failed to archive splits of competitor %d: %w", id, err)
+		}
+	}
+
+	var status string
+	var finish sql.NullString
+	err = tx.QueryRow(`
+		SELECT status, finish_time FROM status_history
+		WHERE event_id = ? AND competitor_id = ?
+		ORDER BY rowid DESC LIMIT 1`, eventID, id).Scan(&status, &finish)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read status history of competitor %d: %w", id, err)
+	}
+	if err == nil && status == comp.Status && finish == formatNullTime(comp.FinishTime) {
+		return nil
+	}
+	if _, err := tx.Exec(`INSERT INTO status_history (event_id, competitor_id, recorded_at, status, finish_time) VALUES (?, ?, ?, ?, ?)`,
+		eventID, id, formatTime(now), comp.Status, formatNullTime(comp.FinishTime)); err != nil {
+		return fmt.Errorf("failed to archive status of competitor %d: %w", id, err)
+	}
+	return nil
+}
+
+// storeTeam replaces a team and its legs, or removes it when it no longer exists
+func storeTeam(tx *sql.Tx, eventID int64, id int, team models.Team, exists bool) error {
+	if _, err := tx.Exec(`DELETE FROM team_legs WHERE event_id = ? AND team_id = ?`, eventID, id); err != nil {
+		return fmt.Errorf("failed to clear archived legs of team %d: %w", id, err)
+	}
+	if !exists {
+		if _, err := tx.Exec(`DELETE FROM teams WHERE event_id = ? AND id = ?`, eventID, id); err != nil {
+			return fmt.Errorf("failed to remove archived team %d: %w", id, err)
+		}
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		INSERT OR REPLACE INTO teams (event_id, id, name, bib, club_id, class_id, status, start_time, finish_time)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		eventID, team.ID, team.Name, team.Bib, team.Club.ID, team.Class.ID, team.Status,
+		formatTime(team.StartTime), formatNullTime(team.FinishTime))
+	if err != nil {
+		return fmt.Errorf("failed to archive team %d: %w", id, err)
+	}
+	for _, leg := range team.Legs {
+		for _, competitorID := range leg.CompetitorIDs {
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO team_legs (event_id, team_id, leg, competitor_id) VALUES (?, ?, ?, ?)`,
+				eventID, id, leg.Number, competitorID); err != nil {
+				return fmt.Errorf("failed to archive legs of team %d: %w", id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Load replaces the state's data with an archived event
+func (a *Archive) Load(eventID int64, appState *state.State) error {
+	var name, organizer, start string
+	err := a.db.QueryRow(`SELECT name, organizer, start FROM events WHERE id = ?`, eventID).Scan(&name, &organizer, &start)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrEventNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load archived event: %w", err)
+	}
+	event := &models.Event{Name: name, Organizer: organizer, Start: parseTime(start)}
+
+	controls := []models.Control{}
+	controlsByID := make(map[int]models.Control)
+	err = a.query(`SELECT id, name FROM controls WHERE event_id = ? ORDER BY id`, eventID, func(rows *sql.Rows) error {
+		var ctrl models.Control
+		if err := rows.Scan(&ctrl.ID, &ctrl.Name); err != nil {
+			return err
+		}
+		controls = append(controls, ctrl)
+		controlsByID[ctrl.ID] = ctrl
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	radioControls := make(map[int][]models.Control)
+	err = a.query(`SELECT class_id, control_id FROM class_controls WHERE event_id = ? ORDER BY class_id, position`, eventID, func(rows *sql.Rows) error {
+		var classID, controlID int
+		if err := rows.Scan(&classID, &controlID); err != nil {
+			return err
+		}
+		radioControls[classID] = append(radioControls[classID], controlsByID[controlID])
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	classes := []models.Class{}
+	classesByID := make(map[int]models.Class)
+	err = a.query(`SELECT id, name, order_key FROM classes WHERE event_id = ? ORDER BY order_key, id`, eventID, func(rows *sql.Rows) error {
+		var class models.Class
+		if err := rows.Scan(&class.ID, &class.Name, &class.OrderKey); err != nil {
+			return err
+		}
+		class.RadioControls = append([]models.Control{}, radioControls[class.ID]...)
+		classes = append(classes, class)
+		classesByID[class.ID] = class
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	clubs := []models.Club{}
+	clubsByID := make(map[int]models.Club)
+	err = a.query(`SELECT id, name, country FROM clubs WHERE event_id = ? ORDER BY id`, eventID, func(rows *sql.Rows) error {
+		var club models.Club
+		if err := rows.Scan(&club.ID, &club.Name, &club.CountryCode); err != nil {
+			return err
+		}
+		clubs = append(clubs, club)
+		clubsByID[club.ID] = club
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	splits := make(map[int][]models.Split)
+	err = a.query(`SELECT competitor_id, control_id, passing_time FROM splits WHERE event_id = ? ORDER BY competitor_id, rowid`, eventID, func(rows *sql.Rows) error {
+		var competitorID, controlID int
+		var passing string
+		if err := rows.Scan(&competitorID, &controlID, &passing); err != nil {
+			return err
+		}
+		ctrl, ok := controlsByID[controlID]
+		if !ok {
+			ctrl = models.Control{ID: controlID}
+		}
+		splits[competitorID] = append(splits[competitorID], models.Split{Control: ctrl, PassingTime: parseTime(passing)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	competitors := []models.Competitor{}
+	err = a.query(`
+		SELECT id, name, card, bib, club_id, class_id, status, start_time, finish_time, team_id, leg
+		FROM competitors WHERE event_id = ? ORDER BY id`, eventID, func(rows *sql.Rows) error {
+		var comp models.Competitor
+		var startTime string
+		var finish sql.NullString
+		if err := rows.Scan(&comp.ID, &comp.Name, &comp.Card, &comp.Bib, &comp.Club.ID, &comp.Class.ID, &comp.Status,
+			&startTime, &finish, &comp.TeamID, &comp.Leg); err != nil {
+			return err
+		}
+		comp.StartTime = parseTime(startTime)
+		comp.FinishTime = parseNullTime(finish)
+		comp.Splits = splits[comp.ID]
+		if club, ok := clubsByID[comp.Club.ID]; ok {
+			comp.Club = club
+		}
+		if class, ok := classesByID[comp.Class.ID]; ok {
+			comp.Class = class
+		}
+		competitors = append(competitors, comp)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	legs := make(map[int][]models.Leg)
+	err = a.query(`SELECT team_id, leg, competitor_id FROM team_legs WHERE event_id = ? ORDER BY team_id, leg, competitor_id`, eventID, func(rows *sql.Rows) error {
+		var teamID, number, competitorID int
+		if err := rows.Scan(&teamID, &number, &competitorID); err != nil {
+			return err
+		}
+		teamLegs := legs[teamID]
+		if n := len(teamLegs); n > 0 && teamLegs[n-1].Number == number {
+			teamLegs[n-1].CompetitorIDs = append(teamLegs[n-1].CompetitorIDs, competitorID)
+		} else {
+			teamLegs = append(teamLegs, models.Leg{Number: number, CompetitorIDs: []int{competitorID}})
+		}
+		legs[teamID] = teamLegs
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	teams := []models.Team{}
+	err = a.query(`
+		SELECT id, name, bib, club_id, class_id, status, start_time, finish_time
+		FROM teams WHERE event_id = ? ORDER BY id`, eventID, func(rows *sql.Rows) error {
+		var team models.Team
+		var startTime string
+		var finish sql.NullString
+		if err := rows.Scan(&team.ID, &team.Name, &team.Bib, &team.Club.ID, &team.Class.ID, &team.Status, &startTime, &finish); err != nil {
+			return err
+		}
+		team.StartTime = parseTime(startTime)
+		team.FinishTime = parseNullTime(finish)
+		team.Legs = legs[team.ID]
+		if club, ok := clubsByID[team.Club.ID]; ok {
+			team.Club = club
+		}
+		if class, ok := classesByID[team.Class.ID]; ok {
+			team.Class = class
+		}
+		teams = append(teams, team)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	appState.UpdateFromMeOS(event, controls, classes, clubs, competitors, teams)
+	return nil
+}
+
+// query runs a query with the event ID and calls scan for every row
+func (a *Archive) query(query string, eventID int64, scan func(*sql.Rows) error) error {
+	rows, err := a.db.Query(query, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to load archived event: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return fmt.Errorf("failed to load archived event: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// formatTime stores a time as RFC 3339 text, and the zero time as an empty string
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+func parseNullTime(s sql.NullString) *time.Time {
+	if !s.Valid {
+		return nil
+	}
+	t := parseTime(s.String)
+	return &t
+}
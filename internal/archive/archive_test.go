@@ -0,0 +1,196 @@
+package archive
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+func openTestArchive(t *testing.T) (*Archive, string) {
+	t.Helper()
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "archive.db")
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { archive.Close() })
+	return archive, path
+}
+
+// testEvent returns the data of an event with one radio control, a finished and a running
+// competitor and a relay team
+func testEvent(start time.Time) (*models.Event, []models.Control, []models.Class, []models.Club, []models.Competitor, []models.Team) {
+	radio := models.Control{ID: 31, Name: "Radio"}
+	class := models.Class{ID: 1, Name: "H21", OrderKey: 1, RadioControls: []models.Control{radio}}
+	club := models.Club{ID: 1, Name: "OK Pan", CountryCode: "DEN"}
+	finish := start.Add(30 * time.Minute)
+
+	competitors := []models.Competitor{
+		{
+			ID: 1, Name: "Finished", Card: 500001, Bib: "1", Club: club, Class: class, Status: "1",
+			StartTime: start, FinishTime: &finish, TeamID: 1, Leg: 1,
+			Splits: []models.Split{{Control: radio, PassingTime: start.Add(10 * time.Minute)}},
+		},
+		{ID: 2, Name: "Running", Club: club, Class: class, Status: "0", StartTime: start},
+	}
+	teams := []models.Team{{
+		ID: 1, Name: "OK Pan 1", Club: club, Class: class, Status: "0", StartTime: start,
+		Legs: []models.Leg{{Number: 1, CompetitorIDs: []int{1}}, {Number: 2, CompetitorIDs: []int{2}}},
+	}}
+	return &models.Event{Name: "Spring Cup", Organizer: "OK Pan", Start: start},
+		[]models.Control{radio}, []models.Class{class}, []models.Club{club}, competitors, teams
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	archive, path := openTestArchive(t)
+	appState := state.New()
+	appState.UpdateFromMeOS(testEvent(time.Date(2024, 5, 4, 10, 0, 0, 0, time.UTC)))
+	if _, err := archive.store(appState.Snapshot(), nil, nil, true); err != nil {
+		t.Fatalf("store() error = %v", err)
+	}
+
+	readOnly, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly() error = %v", err)
+	}
+	defer readOnly.Close()
+	if events, err := readOnly.Events(); err != nil || len(events) != 1 {
+		t.Errorf("Events() = %v, %v, want one event", events, err)
+	}
+	if _, err := readOnly.store(appState.Snapshot(), nil, nil, true); err == nil {
+		t.Error("store() on a read-only archive succeeded")
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.db")
+	if _, err := OpenReadOnly(missing); err == nil {
+		t.Error("OpenReadOnly() of a missing database succeeded")
+	}
+	if _, err := os.Stat(missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("OpenReadOnly() of a missing database created it: %v", err)
+	}
+}
+
+func TestRecorderAndLoad(t *testing.T) {
+	archive, _ := openTestArchive(t)
+	start := time.Date(2024, 5, 4, 10, 0, 0, 0, time.UTC)
+
+	appState := state.New()
+	recorder := NewRecorder(archive, appState, time.Hour)
+	appState.OnChange(recorder.HandleChange)
+
+	// Nothing is archived before there is an event
+	if err := recorder.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if events, _ := archive.Events(); len(events) != 0 {
+		t.Fatalf("Events() = %+v before the event arrived", events)
+	}
+
+	event, controls, classes, clubs, competitors, teams := testEvent(start)
+	appState.UpdateFromMeOS(event, controls, classes, clubs, competitors, teams)
+	if err := recorder.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// The running competitor finishes and the finished one is removed
+	finish := start.Add(40 * time.Minute)
+	running := competitors[1]
+	running.Status = "1"
+	running.FinishTime = &finish
+	appState.UpdateFromMeOS(event, controls, classes, clubs, []models.Competitor{running}, teams)
+	if err := recorder.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	events, err := archive.Events()
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Name != "Spring Cup" || !events[0].Start.Equal(start) || events[0].Competitors != 1 {
+		t.Fatalf("Events() = %+v, want Spring Cup with one competitor", events)
+	}
+
+	history, err := archive.StatusHistory(events[0].ID, 2)
+	if err != nil {
+		t.Fatalf("StatusHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[0].Status != "0" || history[1].Status != "1" || history[1].FinishTime == nil {
+		t.Errorf("StatusHistory() = %+v, want running then finished", history)
+	}
+
+	loaded := state.New()
+	if err := archive.Load(events[0].ID, loaded); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if e := loaded.GetEvent(); e == nil || e.Organizer != "OK Pan" {
+		t.Errorf("Loaded event = %+v", e)
+	}
+	if got := loaded.GetClasses(); len(got) != 1 || len(got[0].RadioControls) != 1 || got[0].RadioControls[0].Name != "Radio" {
+		t.Errorf("Loaded classes = %+v", got)
+	}
+	if got := loaded.GetCompetitors(); len(got) != 1 || got[0].ID != 2 || got[0].Club.Name != "OK Pan" ||
+		got[0].FinishTime == nil || !got[0].FinishTime.Equal(finish) {
+		t.Errorf("Loaded competitors = %+v", got)
+	}
+	if got := loaded.GetTeams(); len(got) != 1 || len(got[0].Legs) != 2 || got[0].Legs[1].CompetitorIDs[0] != 2 {
+		t.Errorf("Loaded teams = %+v", got)
+	}
+}
+
+func TestLoad_Splits(t *testing.T) {
+	archive, _ := openTestArchive(t)
+	start := time.Date(2024, 5, 4, 10, 0, 0, 0, time.UTC)
+
+	appState := state.New()
+	appState.UpdateFromMeOS(testEvent(start))
+	if _, err := archive.store(appState.Snapshot(), nil, nil, true); err != nil {
+		t.Fatalf("store() error = %v", err)
+	}
+
+	loaded := state.New()
+	if err := archive.Load(1, loaded); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	comp := loaded.GetCompetitor(1)
+	if comp == nil || len(comp.Splits) != 1 || comp.Splits[0].Control.Name != "Radio" ||
+		!comp.Splits[0].PassingTime.Equal(start.Add(10*time.Minute)) {
+		t.Errorf("Loaded competitor = %+v", comp)
+	}
+	if comp != nil && (comp.TeamID != 1 || comp.Leg != 1 || comp.Card != 500001) {
+		t.Errorf("Loaded competitor details = %+v", comp)
+	}
+}
+
+func TestAdapter(t *testing.T) {
+	archive, path := openTestArchive(t)
+
+	appState := state.New()
+	appState.UpdateFromMeOS(testEvent(time.Date(2024, 5, 4, 10, 0, 0, 0, time.UTC)))
+	eventID, err := archive.store(appState.Snapshot(), nil, nil, true)
+	if err != nil {
+		t.Fatalf("store() error = %v", err)
+	}
+
+	served := state.New()
+	if err := NewAdapter(path, eventID, served).Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if len(served.GetCompetitors()) != 2 {
+		t.Errorf("Served %d competitors, want 2", len(served.GetCompetitors()))
+	}
+
+	err = NewAdapter(path, eventID+1, state.New()).Connect()
+	if !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("Connect() of a missing event error = %v, want ErrEventNotFound", err)
+	}
+}
@@ -0,0 +1,133 @@
+package archive
+
+import (
+	"sync"
+	"time"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/state"
+)
+
+// Recorder writes the state to the archive as it changes. Changes are collected and
+// written together every interval, so a burst of MeOS updates costs a single transaction.
+type Recorder struct {
+	archive  *Archive
+	state    *state.State
+	interval time.Duration
+
+	mu          sync.Mutex
+	competitors map[int]bool
+	teams       map[int]bool
+	all         bool // Write every competitor and team on the next flush
+
+	// flushMu keeps flushes in order without blocking HandleChange during a write
+	flushMu sync.Mutex
+
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+// NewRecorder creates a recorder writing the state to the archive every interval. The
+// first flush writes the whole event.
+func NewRecorder(archive *Archive, appState *state.State, interval time.Duration) *Recorder {
+	return &Recorder{
+		archive:     archive,
+		state:       appState,
+		interval:    interval,
+		competitors: make(map[int]bool),
+		teams:       make(map[int]bool),
+		all:         true,
+		stopChan:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// HandleChange remembers what changed for the next flush. Register it with State.OnChange.
+func (r *Recorder) HandleChange(change state.Change) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if change.Event {
+		r.all = true
+	}
+	for _, id := range change.Competitors {
+		r.competitors[id] = true
+	}
+	for _, id := range change.Teams {
+		r.teams[id] = true
+	}
+}
+
+// Run flushes the collected changes every interval until Stop is called
+func (r *Recorder) Run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopChan:
+			return
+		case <-ticker.C:
+			if err := r.Flush(); err != nil {
+				logger.ErrorLogger.Printf("Failed to archive event: %v", err)
+			}
+		}
+	}
+}
+
+// Stop ends Run and flushes the remaining changes
+func (r *Recorder) Stop() error {
+	close(r.stopChan)
+	<-r.done
+	return r.Flush()
+}
+
+// Flush writes the collected changes to the archive. Changes are kept for the next flush
+// while the state has no event or writing fails. The changes are taken out under the lock
+// and written without it, so updates of the state are not held up by the database.
+func (r *Recorder) Flush() error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.Lock()
+	if !r.all && len(r.competitors) == 0 && len(r.teams) == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+	competitors, teams, all := r.competitors, r.teams, r.all
+	r.competitors, r.teams, r.all = make(map[int]bool), make(map[int]bool), false
+	r.mu.Unlock()
+
+	eventID, err := r.archive.store(r.state.Snapshot(), keys(competitors), keys(teams), all)
+	if err != nil || eventID == 0 {
+		r.restore(competitors, teams, all)
+		return err
+	}
+
+	logger.DebugLogger.Printf("Archived %d competitors and %d teams of event %d", len(competitors), len(teams), eventID)
+	return nil
+}
+
+// restore merges changes that could not be written back for the next flush
+func (r *Recorder) restore(competitors, teams map[int]bool, all bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.all = r.all || all
+	for id := range competitors {
+		r.competitors[id] = true
+	}
+	for id := range teams {
+		r.teams[id] = true
+	}
+}
+
+func keys(set map[int]bool) []int {
+	result := make([]int, 0, len(set))
+	for id := range set {
+		result = append(result, id)
+	}
+	return result
+}
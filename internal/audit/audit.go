@@ -0,0 +1,282 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+// Fields an entry can record a change of
+const (
+	FieldStatus     = "status"
+	FieldStartTime  = "startTime"
+	FieldFinishTime = "finishTime"
+	FieldClass      = "class"
+	FieldClub       = "club"
+)
+
+// MaxEntries is how many of the most recent entries the log keeps
+const MaxEntries = 10000
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// Entry records a change of one field of a competitor
+type Entry struct {
+	ID       uint64    `json:"id"`
+	Time     time.Time `json:"time"`     // When the change was received
+	Revision uint64    `json:"revision"` // State revision that has the new value
+
+	CompetitorID int    `json:"competitorId"`
+	Name         string `json:"name"`
+	ClassID      int    `json:"classId"`
+	Class        string `json:"class"`
+
+	Field string `json:"field"`
+	// Values before and after the change: the MeOS status code, the time in RFC 3339
+	// format, or the class or club name. Times that are not set are empty.
+	Before string `json:"before"`
+	After  string `json:"after"`
+	// Class or club IDs before and after a class or club change
+	BeforeID int `json:"beforeId,omitempty"`
+	AfterID  int `json:"afterId,omitempty"`
+}
+
+// Filter selects audit entries. Zero values match everything.
+type Filter struct {
+	CompetitorID int
+	// ClassID matches entries of competitors in the class, and class changes away from it
+	ClassID int
+	From    time.Time // Inclusive
+	To      time.Time // Exclusive
+}
+
+// Matches reports whether an entry passes the filter
+func (f Filter) Matches(entry Entry) bool {
+	if f.CompetitorID != 0 && entry.CompetitorID != f.CompetitorID {
+		return false
+	}
+	if f.ClassID != 0 && entry.ClassID != f.ClassID && !(entry.Field == FieldClass && entry.BeforeID == f.ClassID) {
+		return false
+	}
+	if !f.From.IsZero() && entry.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !entry.Time.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// Log records changes to the competitors' status, start time, finish time, class and club
+// as MeOS reports them. It compares the snapshots before and after each update, so every
+// update is recorded on its own even when several follow in quick succession.
+type Log struct {
+	mu      sync.RWMutex
+	entries []Entry // In revision order
+	lastID  uint64
+}
+
+// New creates a log that records changes from now on. Register its HandleChange with
+// State.OnChange.
+func New() *Log {
+	return &Log{}
+}
+
+// HandleChange records the changes of a state update
+func (l *Log) HandleChange(change state.Change) {
+	l.record(change, time.Now())
+}
+
+// record adds the entries for what the update changed
+func (l *Log) record(change state.Change, now time.Time) {
+	if change.Previous == nil || change.Current == nil {
+		return
+	}
+	entries := diff(change.Previous, change.Current, change.Competitors, now)
+	if len(entries) == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range entries {
+		l.lastID++
+		entries[i].ID = l.lastID
+	}
+
+	// Listeners of concurrent updates may run out of order, so keep the entries sorted
+	i := len(l.entries)
+	for i > 0 && l.entries[i-1].Revision > change.Revision {
+		i--
+	}
+	l.entries = append(l.entries[:i], append(entries, l.entries[i:]...)...)
+	if len(l.entries) > MaxEntries {
+		l.entries = append([]Entry{}, l.entries[len(l.entries)-MaxEntries:]...)
+	}
+}
+
+// diff returns the entries for the given competitors that changed between two snapshots.
+// Competitors that were just entered or removed have nothing to compare and are skipped.
+func diff(prev, current *state.Snapshot, competitorIDs []int, now time.Time) []Entry {
+	var entries []Entry
+	for _, id := range competitorIDs {
+		comp, ok := current.Competitor(id)
+		if !ok {
+			continue
+		}
+		old, ok := prev.Competitor(id)
+		if !ok {
+			continue
+		}
+
+		newEntry := func(field, before, after string) Entry {
+			entry := Entry{
+				Time:         now,
+				Revision:     current.Revision(),
+				CompetitorID: comp.ID,
+				Name:         comp.Name,
+				ClassID:      comp.Class.ID,
+				Class:        comp.Class.Name,
+				Field:        field,
+				Before:       before,
+				After:        after,
+			}
+			if class, ok := current.Class(comp.Class.ID); ok {
+				entry.Class = class.Name
+			}
+			return entry
+		}
+
+		if comp.Status != old.Status {
+			entries = append(entries, newEntry(FieldStatus, old.Status, comp.Status))
+		}
+		if !comp.StartTime.Equal(old.StartTime) {
+			entries = append(entries, newEntry(FieldStartTime, formatTime(old.StartTime), formatTime(comp.StartTime)))
+		}
+		if !sameTime(comp.FinishTime, old.FinishTime) {
+			entries = append(entries, newEntry(FieldFinishTime, formatFinish(old.FinishTime), formatFinish(comp.FinishTime)))
+		}
+		if comp.Class.ID != old.Class.ID {
+			entry := newEntry(FieldClass, className(prev, old.Class), className(current, comp.Class))
+			entry.BeforeID, entry.AfterID = old.Class.ID, comp.Class.ID
+			entries = append(entries, entry)
+		}
+		if comp.Club.ID != old.Club.ID {
+			entry := newEntry(FieldClub, old.Club.Name, comp.Club.Name)
+			entry.BeforeID, entry.AfterID = old.Club.ID, comp.Club.ID
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// className returns the class name as the snapshot has it
+func className(snap *state.Snapshot, class models.Class) string {
+	if c, ok := snap.Class(class.ID); ok {
+		return c.Name
+	}
+	return class.Name
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatFinish(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+// Entries returns up to limit of the most recent entries that pass the filter, in
+// chronological order
+func (l *Log) Entries(filter Filter, limit int) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var matched []Entry
+	for i := len(l.entries) - 1; i >= 0 && len(matched) < limit; i-- {
+		if filter.Matches(l.entries[i]) {
+			matched = append(matched, l.entries[i])
+		}
+	}
+
+	result := make([]Entry, len(matched))
+	for i, entry := range matched {
+		result[len(matched)-1-i] = entry
+	}
+	return result
+}
+
+// HandleAudit returns the audit log
+// @Summary Get the audit log
+// @Description Get the changes of competitors' status, start time, finish time, class and club received from MeOS, with the values before and after each change. Entries are in chronological order; the most recent ones are returned when there are more than the limit. The server keeps the last 10000 entries.
+// @Tags audit
+// @Produce json
+// @Param competitor query int false "Only changes of this competitor"
+// @Param class query int false "Only changes of competitors in this class, including moves out of it"
+// @Param from query string false "Only changes received at or after this time, in RFC 3339 format"
+// @Param to query string false "Only changes received before this time, in RFC 3339 format"
+// @Param limit query int false "Maximum number of entries (default 100, at most 1000)"
+// @Success 200 {array} audit.Entry
+// @Failure 400 {object} map[string]string
+// @Router /audit [get]
+func (l *Log) HandleAudit(c *gin.Context) {
+	var filter Filter
+	var err error
+	if value := c.Query("competitor"); value != "" {
+		if filter.CompetitorID, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid competitor ID"})
+			return
+		}
+	}
+	if value := c.Query("class"); value != "" {
+		if filter.ClassID, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+			return
+		}
+	}
+	if value := c.Query("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from time"})
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to time"})
+			return
+		}
+	}
+
+	limit := defaultLimit
+	if value := c.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = min(limit, maxLimit)
+	}
+
+	c.JSON(http.StatusOK, l.Entries(filter, limit))
+}
@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+func TestLog_Update(t *testing.T) {
+	base := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	classes := []models.Class{{ID: 1, Name: "H21"}, {ID: 2, Name: "H35"}}
+	clubs := []models.Club{{ID: 5, Name: "OK Test"}, {ID: 6, Name: "OK Other"}}
+	finish := base.Add(30 * time.Minute)
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Anna", Status: "1", Class: classes[0], Club: clubs[0], StartTime: base, FinishTime: &finish},
+		{ID: 2, Name: "Bert", Status: "0", Class: classes[0], Club: clubs[0], StartTime: base.Add(time.Minute)},
+	}
+
+	appState := state.New()
+	l := New()
+	var now time.Time
+	appState.OnChange(func(change state.Change) { l.record(change, now) })
+
+	update := func(at time.Time) {
+		t.Helper()
+		now = at
+		appState.UpdateFromMeOS(&models.Event{Name: "Test"}, nil, classes, clubs, competitors, nil)
+	}
+
+	// Loading the event records nothing
+	update(base)
+	if entries := l.Entries(Filter{}, 100); len(entries) != 0 {
+		t.Fatalf("Expected no entries after loading, got %+v", entries)
+	}
+
+	// Anna is disqualified and her finish time corrected
+	competitors[0].Status = "5"
+	corrected := finish.Add(-time.Minute)
+	competitors[0].FinishTime = &corrected
+	update(base.Add(time.Hour))
+
+	entries := l.Entries(Filter{}, 100)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %+v", entries)
+	}
+	if e := entries[0]; e.Field != FieldStatus || e.Before != "1" || e.After != "5" || e.CompetitorID != 1 || e.Revision != 2 {
+		t.Errorf("Unexpected status entry: %+v", e)
+	}
+	if e := entries[1]; e.Field != FieldFinishTime || e.Before != "2026-06-01T10:30:00Z" || e.After != "2026-06-01T10:29:00Z" {
+		t.Errorf("Unexpected finish time entry: %+v", e)
+	}
+
+	// The jury overturns the disqualification, and Bert moves class and club
+	competitors[0].Status = "1"
+	competitors[1].Class = classes[1]
+	competitors[1].Club = clubs[1]
+	update(base.Add(2 * time.Hour))
+
+	entries = l.Entries(Filter{CompetitorID: 1}, 100)
+	if len(entries) != 3 || entries[2].Before != "5" || entries[2].After != "1" || entries[2].ID != 3 {
+		t.Errorf("Expected Anna's reinstatement last, got %+v", entries)
+	}
+
+	entries = l.Entries(Filter{CompetitorID: 2}, 100)
+	if len(entries) != 2 {
+		t.Fatalf("Expected Bert's class and club changes, got %+v", entries)
+	}
+	if e := entries[0]; e.Field != FieldClass || e.Before != "H21" || e.After != "H35" || e.BeforeID != 1 || e.AfterID != 2 {
+		t.Errorf("Unexpected class entry: %+v", e)
+	}
+	if e := entries[1]; e.Field != FieldClub || e.Before != "OK Test" || e.After != "OK Other" {
+		t.Errorf("Unexpected club entry: %+v", e)
+	}
+
+	// Moving out of a class counts for the old class
+	if entries := l.Entries(Filter{ClassID: 1}, 100); len(entries) != 4 {
+		t.Errorf("Expected 4 entries in H21, got %+v", entries)
+	}
+	if entries := l.Entries(Filter{ClassID: 2}, 100); len(entries) != 2 {
+		t.Errorf("Expected 2 entries in H35, got %+v", entries)
+	}
+
+	// Time ranges include the start and exclude the end
+	if entries := l.Entries(Filter{From: base.Add(time.Hour), To: base.Add(2 * time.Hour)}, 100); len(entries) != 2 {
+		t.Errorf("Expected the first 2 entries in the time range, got %+v", entries)
+	}
+
+	// The limit keeps the most recent entries
+	if entries := l.Entries(Filter{}, 1); len(entries) != 1 || entries[0].ID != 5 {
+		t.Errorf("Expected the last entry, got %+v", entries)
+	}
+}
+
+func TestLog_RecordsEachUpdate(t *testing.T) {
+	classes := []models.Class{{ID: 1, Name: "H21"}}
+	competitors := []models.Competitor{{ID: 1, Name: "Anna", Status: "1", Class: classes[0]}}
+	appState := state.New()
+	appState.UpdateFromMeOS(nil, nil, classes, nil, competitors, nil)
+
+	// Anna is disqualified and reinstated before the log hears of either update
+	var changes []state.Change
+	appState.OnChange(func(change state.Change) { changes = append(changes, change) })
+	competitors[0].Status = "5"
+	appState.UpdateFromMeOS(nil, nil, classes, nil, competitors, nil)
+	competitors[0].Status = "1"
+	appState.UpdateFromMeOS(nil, nil, classes, nil, competitors, nil)
+
+	// Listeners may even run out of order
+	l := New()
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	l.record(changes[1], now)
+	l.record(changes[0], now)
+
+	entries := l.Entries(Filter{}, 100)
+	if len(entries) != 2 {
+		t.Fatalf("Expected both status changes, got %+v", entries)
+	}
+	if e := entries[0]; e.Revision != 2 || e.Before != "1" || e.After != "5" {
+		t.Errorf("Unexpected first entry: %+v", e)
+	}
+	if e := entries[1]; e.Revision != 3 || e.Before != "5" || e.After != "1" {
+		t.Errorf("Unexpected second entry: %+v", e)
+	}
+}
+
+func TestLog_HandleAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	classes := []models.Class{{ID: 1, Name: "H21"}}
+	competitors := []models.Competitor{{ID: 1, Name: "Anna", Status: "0", Class: classes[0]}}
+	appState := state.New()
+	l := New()
+	appState.OnChange(l.HandleChange)
+	appState.UpdateFromMeOS(nil, nil, classes, nil, competitors, nil)
+	competitors[0].Status = "4"
+	appState.UpdateFromMeOS(nil, nil, classes, nil, competitors, nil)
+
+	router := gin.New()
+	router.GET("/audit", l.HandleAudit)
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantLen  int
+	}{
+		{"/audit", http.StatusOK, 1},
+		{"/audit?competitor=1&class=1", http.StatusOK, 1},
+		{"/audit?competitor=2", http.StatusOK, 0},
+		{"/audit?from=2000-01-01T00:00:00Z&to=2001-01-01T00:00:00Z", http.StatusOK, 0},
+		{"/audit?competitor=abc", http.StatusBadRequest, 0},
+		{"/audit?class=abc", http.StatusBadRequest, 0},
+		{"/audit?from=yesterday", http.StatusBadRequest, 0},
+		{"/audit?to=2026-06-01", http.StatusBadRequest, 0},
+		{"/audit?limit=0", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("Expected status %d, got %d", tt.wantCode, w.Code)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var entries []Entry
+			if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+				t.Fatalf("Failed to unmarshal entries: %v", err)
+			}
+			if len(entries) != tt.wantLen {
+				t.Errorf("Expected %d entries, got %+v", tt.wantLen, entries)
+			}
+		})
+	}
+}
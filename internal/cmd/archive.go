@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"meos-graphics/internal/archive"
+)
+
+// newArchiveCommand creates the command for inspecting an event archive
+func newArchiveCommand() *cobra.Command {
+	archiveCmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Inspect the event archive written with --archive",
+	}
+
+	archiveCmd.AddCommand(&cobra.Command{
+		Use:   "list <database>",
+		Short: "List the archived events",
+		Long: `List the events archived in a database written with --archive, most recent first.
+Serve one of them read-only with --archive <database> --archive-event <id>.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := archive.OpenReadOnly(args[0])
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			events, err := db.Events()
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No archived events")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tDATE\tNAME\tORGANIZER\tCOMPETITORS\tLAST UPDATED")
+			for _, event := range events {
+				date := "-"
+				if !event.Start.IsZero() {
+					date = event.Start.Format("2006-01-02")
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\n", event.ID, date, event.Name, event.Organizer,
+					event.Competitors, event.LastUpdated.Local().Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	})
+
+	archiveCmd.AddCommand(&cobra.Command{
+		Use:   "history <database> <event-id> <competitor-id>",
+		Short: "Show the status changes of an archived competitor",
+		Long: `Show every status an archived competitor has had, oldest first, with the time it was
+recorded and the finish time at that moment. Find the event ID with 'archive list'.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			eventID, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid event ID %q", args[1])
+			}
+			competitorID, err := strconv.Atoi(args[2])
+			if err != nil {
+				return fmt.Errorf("invalid competitor ID %q", args[2])
+			}
+
+			db, err := archive.OpenReadOnly(args[0])
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			history, err := db.StatusHistory(eventID, competitorID)
+			if err != nil {
+				return err
+			}
+			if len(history) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No status changes archived for this competitor")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "RECORDED\tSTATUS\tFINISH")
+			for _, change := range history {
+				finish := "-"
+				if change.FinishTime != nil {
+					finish = change.FinishTime.Local().Format("15:04:05")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", change.RecordedAt.Local().Format("2006-01-02 15:04:05"), change.Status, finish)
+			}
+			return w.Flush()
+		},
+	})
+
+	return archiveCmd
+}
@@ -15,6 +15,32 @@ var (
 	SwaggerHost    string
 	Language       string
 
+	// Record and replay configuration
+	RecordFile  string
+	ReplayFile  string
+	ReplaySpeed float64
+
+	// Push mode configuration
+	PushMode   bool
+	PushSecret string
+
+	// IOF XML file data source
+	IOFPath string
+
+	// State snapshot configuration
+	StateFile     string
+	StateInterval time.Duration
+
+	// Event archive configuration
+	ArchivePath  string
+	ArchiveEvent int64
+
+	// Admin API configuration
+	AdminToken string
+
+	// How long the history for historical queries is kept
+	HistoryRetention time.Duration
+
 	// Simulation timing configuration
 	SimulationDuration     time.Duration
 	SimulationPhaseStart   time.Duration
@@ -36,9 +62,13 @@ func NewRootCommand() *cobra.Command {
 		Long: `MeOS Graphics API Server connects to MeOS (orienteering event software) 
 and provides competition data for graphics displays.
 
-The server can run in two modes:
+The server can run in six modes:
 - Normal mode: Connects to a real MeOS server
-- Simulation mode: Generates test data for development`,
+- Push mode: Receives data posted by MeOS's online results module
+- IOF XML mode: Loads IOF XML 3.0 start and result lists from a file or directory
+- Simulation mode: Generates test data for development
+- Replay mode: Plays back a journal recorded with --record
+- Archive mode: Serves an event archived with --archive read-only`,
 		Version: version.Version,
 	}
 
@@ -49,6 +79,32 @@ The server can run in two modes:
 	rootCmd.Flags().StringVar(&SwaggerHost, "swagger-host", "localhost:8090", "Hostname for Swagger documentation API calls")
 	rootCmd.Flags().StringVarP(&Language, "language", "l", "en", "Language for status display (en=English, da=Danish)")
 
+	// Record and replay flags
+	rootCmd.Flags().StringVar(&RecordFile, "record", "", "Record every MeOS response to this journal file for later replay")
+	rootCmd.Flags().StringVar(&ReplayFile, "replay", "", "Replay a journal file recorded with --record instead of connecting to MeOS")
+	rootCmd.Flags().Float64Var(&ReplaySpeed, "replay-speed", 1, "Replay speed multiplier, e.g. 10 replays ten times faster (only with --replay)")
+
+	// Push mode flags
+	rootCmd.Flags().BoolVar(&PushMode, "push", false, "Receive MOP data posted by MeOS on POST /mop instead of polling the MeOS server")
+	rootCmd.Flags().StringVar(&PushSecret, "push-secret", "", "Shared secret MeOS must send as password when pushing (required with --push)")
+
+	// IOF XML flags
+	rootCmd.Flags().StringVar(&IOFPath, "iof-xml", "", "Load IOF XML 3.0 StartList/ResultList documents from this file or directory and reload them on change")
+
+	// State snapshot flags
+	rootCmd.Flags().StringVar(&StateFile, "state-file", "", "Save the competition data to this file periodically and restore it at startup, resuming MeOS updates from where they left off")
+	rootCmd.Flags().DurationVar(&StateInterval, "state-interval", 10*time.Second, "How often to save the competition data when it has changed (only with --state-file)")
+
+	// Event archive flags
+	rootCmd.Flags().StringVar(&ArchivePath, "archive", "", "Archive every event received into this SQLite database (list them with 'meos-graphics archive list')")
+	rootCmd.Flags().Int64Var(&ArchiveEvent, "archive-event", 0, "Serve the archived event with this ID from the --archive database read-only instead of connecting to MeOS")
+
+	// Admin API flags
+	rootCmd.Flags().StringVar(&AdminToken, "admin-token", "", "Enable the admin API under /admin for manual result overrides, authenticated with this bearer token")
+
+	// History flags
+	rootCmd.Flags().DurationVar(&HistoryRetention, "history-retention", 24*time.Hour, "How long to keep the history of the competition data that requests with ?at= are answered from")
+
 	// Simulation timing flags
 	rootCmd.Flags().DurationVar(&SimulationDuration, "simulation-duration", 15*time.Minute, "Total simulation cycle duration (only with --simulation)")
 	rootCmd.Flags().DurationVar(&SimulationPhaseStart, "simulation-phase-start", 3*time.Minute, "Duration of start list phase (only with --simulation)")
@@ -61,5 +117,7 @@ The server can run in two modes:
 	rootCmd.Flags().IntVar(&SimulationRunnersPerClass, "simulation-runners", 20, "Number of competitors per class (only with --simulation)")
 	rootCmd.Flags().IntVar(&SimulationRadioControls, "simulation-controls", 3, "Number of radio controls per class (only with --simulation)")
 
+	rootCmd.AddCommand(newArchiveCommand())
+
 	return rootCmd
 }
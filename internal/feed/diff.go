@@ -0,0 +1,222 @@
+package feed
+
+import (
+	"sort"
+	"time"
+
+	"meos-graphics/internal/i18n"
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/service"
+	"meos-graphics/internal/state"
+)
+
+// diff returns the items for what changed between two snapshots. Competitors that were
+// just entered have nothing to compare with and produce no items, so loading an event
+// does not flood the feed.
+func diff(prev, current *state.Snapshot, now time.Time) []Item {
+	var items []Item
+	leaderClasses := map[int]bool{}
+
+	for _, comp := range current.Competitors() {
+		old, ok := prev.Competitor(comp.ID)
+		if !ok {
+			continue
+		}
+
+		for _, split := range comp.Splits {
+			if !hasSplit(old, split.Control.ID) {
+				items = append(items, splitItem(current, comp, split))
+			}
+		}
+
+		if finished(comp) && !finished(old) {
+			items = append(items, finishItem(current, comp))
+		}
+		if comp.Status != old.Status && outStatus(comp.Status) {
+			item := newItem(current, TypeStatus, comp)
+			item.Time = now
+			item.Status = comp.Status
+			item.StatusText = i18n.GetInstance().GetStatusDescription(comp.Status)
+			items = append(items, item)
+		}
+
+		if finished(comp) != finished(old) || (finished(comp) && !comp.FinishTime.Equal(*old.FinishTime)) ||
+			comp.Class.ID != old.Class.ID {
+			leaderClasses[comp.Class.ID] = true
+			leaderClasses[old.Class.ID] = true
+		}
+	}
+
+	classIDs := make([]int, 0, len(leaderClasses))
+	for id := range leaderClasses {
+		classIDs = append(classIDs, id)
+	}
+	sort.Ints(classIDs)
+	for _, classID := range classIDs {
+		before, hadLeader := leader(prev, classID)
+		after, hasLeader := leader(current, classID)
+		if !hasLeader || (hadLeader && before.ID == after.ID) {
+			continue
+		}
+		item := finishItem(current, after)
+		item.Type = TypeLeader
+		item.Time = now
+		if hadLeader {
+			item.PreviousLeaderID = before.ID
+			item.PreviousLeader = before.Name
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// starts returns an item for each competitor with a start time in (from, to]
+func starts(current *state.Snapshot, from, to time.Time) []Item {
+	var started []models.Competitor
+	for _, comp := range current.Competitors() {
+		if comp.StartTime.After(from) && !comp.StartTime.After(to) && !notStarting(comp.Status) {
+			started = append(started, comp)
+		}
+	}
+	sort.SliceStable(started, func(i, j int) bool { return started[i].StartTime.Before(started[j].StartTime) })
+
+	items := make([]Item, 0, len(started))
+	for _, comp := range started {
+		item := newItem(current, TypeStarted, comp)
+		item.Time = comp.StartTime
+		items = append(items, item)
+	}
+	return items
+}
+
+// newItem returns an item of the given type describing the competitor
+func newItem(current *state.Snapshot, itemType string, comp models.Competitor) Item {
+	className := comp.Class.Name
+	if class, ok := current.Class(comp.Class.ID); ok {
+		className = class.Name
+	}
+	return Item{
+		Type:         itemType,
+		CompetitorID: comp.ID,
+		Name:         comp.Name,
+		ClubID:       comp.Club.ID,
+		Club:         comp.Club.Name,
+		ClassID:      comp.Class.ID,
+		Class:        className,
+	}
+}
+
+// splitItem describes a radio control passing with the position at the control
+func splitItem(current *state.Snapshot, comp models.Competitor, split models.Split) Item {
+	item := newItem(current, TypeSplit, comp)
+	item.Time = split.PassingTime
+	item.ControlID = split.Control.ID
+	item.Control = split.Control.Name
+	if ctrl, ok := current.Control(split.Control.ID); ok && ctrl.Name != "" {
+		item.Control = ctrl.Name
+	}
+
+	elapsed := split.PassingTime.Sub(comp.StartTime)
+	var times []time.Duration
+	for _, other := range current.CompetitorsByClass(comp.Class.ID) {
+		for _, s := range other.Splits {
+			if s.Control.ID == split.Control.ID {
+				times = append(times, s.PassingTime.Sub(other.StartTime))
+				break
+			}
+		}
+	}
+	setPosition(&item, elapsed, times)
+	return item
+}
+
+// finishItem describes a finish with the position in the class
+func finishItem(current *state.Snapshot, comp models.Competitor) Item {
+	item := newItem(current, TypeFinished, comp)
+	item.Time = *comp.FinishTime
+	item.Status = comp.Status
+	item.StatusText = i18n.GetInstance().GetStatusDescription(comp.Status)
+
+	var times []time.Duration
+	for _, other := range current.CompetitorsByClass(comp.Class.ID) {
+		if finished(other) {
+			times = append(times, other.FinishTime.Sub(other.StartTime))
+		}
+	}
+	setPosition(&item, comp.FinishTime.Sub(comp.StartTime), times)
+	return item
+}
+
+// setPosition ranks a time among the times of the class. Equal times share a position,
+// as in the results and splits.
+func setPosition(item *Item, elapsed time.Duration, times []time.Duration) {
+	position := 1
+	best := elapsed
+	for _, t := range times {
+		if t < elapsed {
+			position++
+		}
+		if t < best {
+			best = t
+		}
+	}
+	item.Position = position
+	item.ElapsedTime = service.FormatDuration(elapsed)
+	if position > 1 {
+		item.Difference = "+" + service.FormatDuration(elapsed-best)
+	}
+}
+
+// leader returns the fastest finisher of a class, breaking ties by name as the results do
+func leader(snap *state.Snapshot, classID int) (models.Competitor, bool) {
+	var best models.Competitor
+	found := false
+	for _, comp := range snap.CompetitorsByClass(classID) {
+		if !finished(comp) {
+			continue
+		}
+		if !found {
+			best, found = comp, true
+			continue
+		}
+		t, bestTime := comp.FinishTime.Sub(comp.StartTime), best.FinishTime.Sub(best.StartTime)
+		if t < bestTime || (t == bestTime && comp.Name < best.Name) {
+			best = comp
+		}
+	}
+	return best, found
+}
+
+// finished reports whether the competitor has an approved finish
+func finished(comp models.Competitor) bool {
+	return comp.Status == "1" && comp.FinishTime != nil
+}
+
+// outStatus reports whether a status takes the competitor out of the results: missing
+// punch, did not finish, disqualified or over the maximum time
+func outStatus(status string) bool {
+	switch status {
+	case "3", "4", "5", "6":
+		return true
+	}
+	return false
+}
+
+// notStarting reports whether a status means the competitor will not start
+func notStarting(status string) bool {
+	switch status {
+	case "20", "21", "99":
+		return true
+	}
+	return false
+}
+
+func hasSplit(comp models.Competitor, controlID int) bool {
+	for _, split := range comp.Splits {
+		if split.Control.ID == controlID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,276 @@
+package feed
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meos-graphics/internal/overrides"
+	"meos-graphics/internal/state"
+)
+
+// Item types, also used as the SSE event types
+const (
+	TypeStarted  = "competitor-started"
+	TypeSplit    = "competitor-split"
+	TypeFinished = "competitor-finished"
+	TypeStatus   = "competitor-status"
+	TypeLeader   = "class-leader"
+)
+
+// MaxItems is how many of the most recent items the feed keeps
+const MaxItems = 1000
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// Item is something that happened to a competitor, in the form a speaker would announce it
+type Item struct {
+	ID   uint64 `json:"id"`
+	Type string `json:"type"`
+	// Time is the start, passing or finish time, or when a status or leader change was seen
+	Time time.Time `json:"time"`
+
+	CompetitorID int    `json:"competitorId"`
+	Name         string `json:"name"`
+	ClubID       int    `json:"clubId,omitempty"`
+	Club         string `json:"club"`
+	ClassID      int    `json:"classId"`
+	Class        string `json:"class"`
+
+	// Radio control of a split
+	ControlID int    `json:"controlId,omitempty"`
+	Control   string `json:"control,omitempty"`
+
+	// Position and time at the control or finish
+	Position    int    `json:"position,omitempty"`
+	ElapsedTime string `json:"elapsedTime,omitempty"`
+	Difference  string `json:"difference,omitempty"`
+
+	Status     string `json:"status,omitempty"`
+	StatusText string `json:"statusText,omitempty"`
+
+	// Leader that was replaced by a new class leader
+	PreviousLeaderID int    `json:"previousLeaderId,omitempty"`
+	PreviousLeader   string `json:"previousLeader,omitempty"`
+}
+
+// Page is a page of feed items in chronological order
+type Page struct {
+	Items []Item `json:"items"`
+	// HasMore is set when there are more items in the paging direction: older ones
+	// without after, newer ones with after
+	HasMore bool `json:"hasMore"`
+}
+
+// Feed turns state changes into a feed of competitor events. It compares each new
+// snapshot with the previous one, and watches the clock for competitors that start.
+type Feed struct {
+	state     *state.State
+	overrides *overrides.Store
+	clock     func() time.Time
+
+	// updateMu serializes updates so items reach the callbacks in order
+	updateMu      sync.Mutex
+	prev          *state.Snapshot
+	checkedStarts time.Time
+
+	mu        sync.RWMutex
+	items     []Item
+	lastID    uint64
+	callbacks []func([]Item)
+}
+
+// New creates a feed that reports changes from now on
+func New(appState *state.State) *Feed {
+	return &Feed{
+		state:         appState,
+		clock:         time.Now,
+		prev:          appState.Snapshot(),
+		checkedStarts: time.Now(),
+	}
+}
+
+// UseClock makes the feed watch the time returned by clock for competitors that start,
+// such as the replayed time of a recorded race. Call it before Run.
+func (f *Feed) UseClock(clock func() time.Time) {
+	f.updateMu.Lock()
+	defer f.updateMu.Unlock()
+	f.clock = clock
+	f.checkedStarts = clock()
+}
+
+// UseOverrides builds the feed from the state with the manual overrides of a store
+// applied, leaving out hidden competitors. Call it before Run.
+func (f *Feed) UseOverrides(store *overrides.Store) {
+	f.updateMu.Lock()
+	defer f.updateMu.Unlock()
+	f.overrides = store
+	f.prev = f.snapshot()
+}
+
+// OnItems registers a callback to be called with new items
+func (f *Feed) OnItems(callback func([]Item)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.callbacks = append(f.callbacks, callback)
+}
+
+// HandleChange updates the feed after a state or override change
+func (f *Feed) HandleChange(state.Change) {
+	f.update(f.now())
+}
+
+// Run checks for competitors that started once a second
+func (f *Feed) Run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		f.update(f.now())
+	}
+}
+
+// now returns the time of the feed's clock
+func (f *Feed) now() time.Time {
+	f.updateMu.Lock()
+	defer f.updateMu.Unlock()
+	return f.clock()
+}
+
+// snapshot returns the state the feed is built from, with any overrides applied
+func (f *Feed) snapshot() *state.Snapshot {
+	if f.overrides == nil {
+		return f.state.Snapshot()
+	}
+	return f.overrides.Apply(f.state.Snapshot())
+}
+
+// update adds the items for everything that happened since the last update
+func (f *Feed) update(now time.Time) {
+	f.updateMu.Lock()
+	defer f.updateMu.Unlock()
+
+	// An override changes the snapshot without a new revision
+	current := f.snapshot()
+	var items []Item
+	if current != f.prev {
+		items = diff(f.prev, current, now)
+		f.prev = current
+	}
+	if now.After(f.checkedStarts) {
+		items = append(items, starts(current, f.checkedStarts, now)...)
+		f.checkedStarts = now
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	f.mu.Lock()
+	for i := range items {
+		f.lastID++
+		items[i].ID = f.lastID
+	}
+	f.items = append(f.items, items...)
+	if len(f.items) > MaxItems {
+		f.items = append([]Item{}, f.items[len(f.items)-MaxItems:]...)
+	}
+	callbacks := make([]func([]Item), len(f.callbacks))
+	copy(callbacks, f.callbacks)
+	f.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(items)
+	}
+}
+
+// Items returns up to limit items. With after it returns the items following that ID,
+// with before the items preceding it, and otherwise the most recent ones. Items of
+// competitors that have been hidden since are left out.
+func (f *Feed) Items(after, before uint64, limit int) Page {
+	f.mu.RLock()
+	items := f.items
+	f.mu.RUnlock()
+	if f.overrides != nil {
+		items = f.visible(items)
+	}
+
+	var start, end int
+	var hasMore bool
+	if after > 0 {
+		start = sort.Search(len(items), func(i int) bool { return items[i].ID > after })
+		end = min(start+limit, len(items))
+		hasMore = end < len(items)
+	} else {
+		end = len(items)
+		if before > 0 {
+			end = sort.Search(len(items), func(i int) bool { return items[i].ID >= before })
+		}
+		start = max(end-limit, 0)
+		hasMore = start > 0
+	}
+
+	return Page{
+		Items:   append([]Item{}, items[start:end]...),
+		HasMore: hasMore,
+	}
+}
+
+// visible returns the items of competitors that are not hidden by an override
+func (f *Feed) visible(items []Item) []Item {
+	result := make([]Item, 0, len(items))
+	for _, item := range items {
+		if o, ok := f.overrides.Get(item.CompetitorID); !ok || !o.Hidden {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// HandleFeed returns a page of the competitor event feed
+// @Summary Get the competitor event feed
+// @Description Get competitor events derived from the live data: starts, radio control passings with position, finishes with position, status changes to MP, DNF, DSQ or max time, and new class leaders. The same events are sent over SSE. Items are in chronological order; page backwards with before or poll for new items with after.
+// @Tags feed
+// @Produce json
+// @Param after query int false "Only items after this ID"
+// @Param before query int false "Only items before this ID"
+// @Param limit query int false "Maximum number of items (default 50, at most 500)"
+// @Success 200 {object} feed.Page
+// @Failure 400 {object} map[string]string
+// @Router /feed [get]
+func (f *Feed) HandleFeed(c *gin.Context) {
+	var after, before uint64
+	var err error
+	if value := c.Query("after"); value != "" {
+		if after, err = strconv.ParseUint(value, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after ID"})
+			return
+		}
+	}
+	if value := c.Query("before"); value != "" {
+		if before, err = strconv.ParseUint(value, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before ID"})
+			return
+		}
+	}
+	if after > 0 && before > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "after and before cannot be combined"})
+		return
+	}
+
+	limit := defaultLimit
+	if value := c.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = min(limit, maxLimit)
+	}
+
+	c.JSON(http.StatusOK, f.Items(after, before, limit))
+}
@@ -0,0 +1,303 @@
+package feed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/overrides"
+	"meos-graphics/internal/state"
+)
+
+func TestFeed_Update(t *testing.T) {
+	base := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	radio := models.Control{ID: 31, Name: "Radio 1"}
+	class := models.Class{ID: 1, Name: "H21", RadioControls: []models.Control{radio}}
+	club := models.Club{ID: 5, Name: "OK Test"}
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Anna", Status: "0", Class: class, Club: club, StartTime: base},
+		{ID: 2, Name: "Bert", Status: "0", Class: class, Club: club, StartTime: base.Add(time.Minute)},
+		{ID: 3, Name: "Carl", Status: "0", Class: class, Club: club, StartTime: base.Add(2 * time.Minute)},
+	}
+
+	appState := state.New()
+	f := New(appState)
+	f.checkedStarts = base.Add(-time.Hour)
+
+	var received []Item
+	f.OnItems(func(items []Item) { received = append(received, items...) })
+
+	update := func(now time.Time) []Item {
+		t.Helper()
+		received = nil
+		appState.UpdateFromMeOS(&models.Event{Name: "Test"}, []models.Control{radio}, []models.Class{class}, []models.Club{club}, competitors, nil)
+		f.update(now)
+		return received
+	}
+
+	// Loading the event only reports the competitors that started by now
+	items := update(base.Add(30 * time.Second))
+	if len(items) != 1 || items[0].Type != TypeStarted || items[0].CompetitorID != 1 {
+		t.Fatalf("Expected Anna's start after loading, got %+v", items)
+	}
+
+	// Bert passes the radio control first, then Anna passes it slower
+	competitors[1].Splits = []models.Split{{Control: radio, PassingTime: base.Add(6 * time.Minute)}}
+	items = update(base.Add(90 * time.Second))
+	if len(items) != 2 || items[0].Type != TypeSplit || items[1].Type != TypeStarted {
+		t.Fatalf("Expected a split and Bert's start, got %+v", items)
+	}
+	if items[0].Position != 1 || items[0].Control != "Radio 1" || items[0].ElapsedTime != "5:00.0" {
+		t.Errorf("Unexpected split item: %+v", items[0])
+	}
+
+	competitors[0].Splits = []models.Split{{Control: radio, PassingTime: base.Add(7 * time.Minute)}}
+	items = update(base.Add(90 * time.Second))
+	if len(items) != 1 || items[0].Position != 2 || items[0].Difference != "+2:00.0" {
+		t.Fatalf("Expected Anna second at the radio control, got %+v", items)
+	}
+
+	// Anna finishes and leads, then Bert finishes faster and takes over
+	finishAnna := base.Add(20 * time.Minute)
+	competitors[0].Status = "1"
+	competitors[0].FinishTime = &finishAnna
+	items = update(base.Add(90 * time.Second))
+	if len(items) != 2 || items[0].Type != TypeFinished || items[0].Position != 1 {
+		t.Fatalf("Expected Anna to finish first, got %+v", items)
+	}
+	if items[1].Type != TypeLeader || items[1].CompetitorID != 1 || items[1].PreviousLeaderID != 0 {
+		t.Errorf("Expected Anna as the first leader, got %+v", items[1])
+	}
+
+	finishBert := base.Add(19 * time.Minute)
+	competitors[1].Status = "1"
+	competitors[1].FinishTime = &finishBert
+	items = update(base.Add(90 * time.Second))
+	if len(items) != 2 || items[0].Position != 1 || items[1].Type != TypeLeader {
+		t.Fatalf("Expected Bert to finish first and lead, got %+v", items)
+	}
+	if items[1].CompetitorID != 2 || items[1].PreviousLeaderID != 1 || items[1].PreviousLeader != "Anna" {
+		t.Errorf("Expected Bert to replace Anna as leader, got %+v", items[1])
+	}
+
+	// Bert is disqualified, which makes Anna the leader again
+	competitors[1].Status = "5"
+	items = update(base.Add(90 * time.Second))
+	if len(items) != 2 || items[0].Type != TypeStatus || items[0].Status != "5" {
+		t.Fatalf("Expected Bert's disqualification, got %+v", items)
+	}
+	if items[1].Type != TypeLeader || items[1].CompetitorID != 1 {
+		t.Errorf("Expected Anna to lead again, got %+v", items[1])
+	}
+
+	// Nothing changed and nobody started
+	if items = update(base.Add(90 * time.Second)); len(items) != 0 {
+		t.Errorf("Expected no items, got %+v", items)
+	}
+
+	page := f.Items(0, 0, 100)
+	if len(page.Items) != 10 || page.Items[0].ID != 1 || page.Items[9].ID != 10 {
+		t.Errorf("Expected all 10 items in order, got %+v", page.Items)
+	}
+}
+
+func TestFeed_UseClock(t *testing.T) {
+	base := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	class := models.Class{ID: 1, Name: "H21"}
+	competitors := []models.Competitor{{ID: 1, Name: "Anna", Status: "0", Class: class, StartTime: base}}
+
+	appState := state.New()
+	f := New(appState)
+	clock := base.Add(-time.Minute)
+	f.UseClock(func() time.Time { return clock })
+	appState.OnChange(f.HandleChange)
+
+	var received []Item
+	f.OnItems(func(items []Item) { received = append(received, items...) })
+
+	// Starts follow the feed's clock rather than the wall clock
+	appState.UpdateFromMeOS(&models.Event{Name: "Test"}, nil, []models.Class{class}, nil, competitors, nil)
+	for _, item := range received {
+		if item.Type == TypeStarted {
+			t.Fatalf("Got a start before the clock reached it: %+v", item)
+		}
+	}
+
+	received = nil
+	clock = base.Add(30 * time.Second)
+	f.HandleChange(state.Change{})
+	if len(received) != 1 || received[0].Type != TypeStarted || received[0].CompetitorID != 1 {
+		t.Errorf("Expected Anna's start, got %+v", received)
+	}
+}
+
+func TestFeed_UseOverrides(t *testing.T) {
+	base := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	radio := models.Control{ID: 31, Name: "Radio 1"}
+	class := models.Class{ID: 1, Name: "H21", RadioControls: []models.Control{radio}}
+	finishAnna := base.Add(20 * time.Minute)
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Anna", Status: "1", Class: class, StartTime: base, FinishTime: &finishAnna},
+		{ID: 2, Name: "Bert", Status: "0", Class: class, StartTime: base},
+	}
+
+	appState := state.New()
+	load := func() {
+		appState.UpdateFromMeOS(&models.Event{Name: "Test"}, []models.Control{radio}, []models.Class{class}, nil, competitors, nil)
+	}
+	load()
+
+	store := overrides.New(appState)
+	f := New(appState)
+	f.UseOverrides(store)
+	now := base.Add(time.Hour)
+	f.checkedStarts = now
+
+	var received []Item
+	f.OnItems(func(items []Item) { received = append(received, items...) })
+
+	// Bert passes the radio control, and is hidden afterwards
+	competitors[1].Splits = []models.Split{{Control: radio, PassingTime: base.Add(6 * time.Minute)}}
+	load()
+	f.update(now)
+	if len(received) != 1 || received[0].CompetitorID != 2 {
+		t.Fatalf("Expected Bert's split, got %+v", received)
+	}
+	if _, err := store.Set(overrides.Override{CompetitorID: 2, Hidden: true}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	f.update(now)
+	if page := f.Items(0, 0, 10); len(page.Items) != 0 {
+		t.Errorf("Expected the hidden competitor's items to be left out, got %+v", page.Items)
+	}
+
+	// A hidden competitor's finish is not reported
+	received = nil
+	finishBert := base.Add(19 * time.Minute)
+	competitors[1].Status = "1"
+	competitors[1].FinishTime = &finishBert
+	load()
+	f.update(now)
+	if len(received) != 0 {
+		t.Errorf("Expected no items for the hidden competitor, got %+v", received)
+	}
+
+	// Disqualifying a finished competitor is reported as a status change
+	if _, err := store.Set(overrides.Override{CompetitorID: 1, Status: ptr("5")}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	f.update(now)
+	if len(received) != 1 || received[0].Type != TypeStatus || received[0].CompetitorID != 1 || received[0].Status != "5" {
+		t.Fatalf("Expected Anna's disqualification, got %+v", received)
+	}
+
+	// Later updates keep the disqualification
+	received = nil
+	load()
+	f.update(now)
+	if len(received) != 0 {
+		t.Errorf("Expected no items after an unchanged update, got %+v", received)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestFeed_Items(t *testing.T) {
+	f := New(state.New())
+	for i := uint64(1); i <= 10; i++ {
+		f.items = append(f.items, Item{ID: i})
+	}
+
+	ids := func(page Page) []uint64 {
+		var result []uint64
+		for _, item := range page.Items {
+			result = append(result, item.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name        string
+		after       uint64
+		before      uint64
+		limit       int
+		wantIDs     []uint64
+		wantHasMore bool
+	}{
+		{"latest", 0, 0, 3, []uint64{8, 9, 10}, true},
+		{"before", 0, 4, 5, []uint64{1, 2, 3}, false},
+		{"after", 6, 0, 2, []uint64{7, 8}, true},
+		{"after the last item", 10, 0, 2, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := f.Items(tt.after, tt.before, tt.limit)
+			got := ids(page)
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("Items() = %v, want %v", got, tt.wantIDs)
+			}
+			for i := range got {
+				if got[i] != tt.wantIDs[i] {
+					t.Errorf("Items() = %v, want %v", got, tt.wantIDs)
+					break
+				}
+			}
+			if page.HasMore != tt.wantHasMore {
+				t.Errorf("HasMore = %v, want %v", page.HasMore, tt.wantHasMore)
+			}
+		})
+	}
+}
+
+func TestFeed_HandleFeed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	f := New(state.New())
+	f.items = []Item{{ID: 1, Type: TypeStarted}, {ID: 2, Type: TypeFinished}}
+
+	router := gin.New()
+	router.GET("/feed", f.HandleFeed)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantItems  int
+	}{
+		{"", http.StatusOK, 2},
+		{"?after=1", http.StatusOK, 1},
+		{"?limit=1", http.StatusOK, 1},
+		{"?after=x", http.StatusBadRequest, 0},
+		{"?limit=0", http.StatusBadRequest, 0},
+		{"?after=1&before=2", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/feed"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var page Page
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if len(page.Items) != tt.wantItems {
+				t.Errorf("Expected %d items, got %d", tt.wantItems, len(page.Items))
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"meos-graphics/internal/overrides"
+)
+
+// OverrideRequest is the body of a request to override a competitor's data. Fields that
+// are left out keep the value from MeOS.
+type OverrideRequest struct {
+	Name           *string `json:"name,omitempty"`
+	ClubID         *int    `json:"clubId,omitempty"`
+	Status         *string `json:"status,omitempty"`         // MeOS status code, such as "5" for disqualified
+	Hidden         bool    `json:"hidden,omitempty"`         // Leaves the competitor out of every list
+	TimeAdjustment int     `json:"timeAdjustment,omitempty"` // Seconds added to the running time at the finish
+	Reason         string  `json:"reason,omitempty"`
+}
+
+// ListOverrides returns all manual overrides
+// @Summary List the manual overrides
+// @Description List the competitor overrides set through the admin API, ordered by competitor ID
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {array} overrides.Override
+// @Failure 401 {object} map[string]string
+// @Router /admin/overrides [get]
+func (h *Handler) ListOverrides(c *gin.Context) {
+	c.JSON(http.StatusOK, h.overrides.List())
+}
+
+// SetOverride overrides a competitor's data
+// @Summary Override a competitor's data
+// @Description Replace the override of a competitor. The name, club and status replace the values from MeOS, hidden leaves the competitor out of every list and the time adjustment in seconds is added to the running time at the finish, which is only allowed for competitors who have finished and must keep the finish after the start. Overridden competitors are flagged with overridden in all responses.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param competitorId path int true "Competitor ID"
+// @Param override body OverrideRequest true "Fields to override"
+// @Success 200 {object} overrides.Override
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/overrides/{competitorId} [put]
+func (h *Handler) SetOverride(c *gin.Context) {
+	var competitorID int
+	if _, err := fmt.Sscanf(c.Param("competitorId"), "%d", &competitorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid competitor ID"})
+		return
+	}
+
+	var req OverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	override, err := h.overrides.Set(overrides.Override{
+		CompetitorID:   competitorID,
+		Name:           req.Name,
+		ClubID:         req.ClubID,
+		Status:         req.Status,
+		Hidden:         req.Hidden,
+		TimeAdjustment: req.TimeAdjustment,
+		Reason:         req.Reason,
+	})
+	if errors.Is(err, overrides.ErrCompetitorNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, override)
+}
+
+// DeleteOverride clears the override of a competitor
+// @Summary Clear a competitor's override
+// @Description Clear the override of a competitor so the data from MeOS is shown again
+// @Tags admin
+// @Security AdminToken
+// @Param competitorId path int true "Competitor ID"
+// @Success 204 "Override cleared"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/overrides/{competitorId} [delete]
+func (h *Handler) DeleteOverride(c *gin.Context) {
+	var competitorID int
+	if _, err := fmt.Sscanf(c.Param("competitorId"), "%d", &competitorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid competitor ID"})
+		return
+	}
+
+	if !h.overrides.Delete(competitorID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "override not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ClearOverrides clears all manual overrides
+// @Summary Clear all overrides
+// @Description Clear every competitor override so the data from MeOS is shown again
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} map[string]int "Number of overrides cleared"
+// @Failure 401 {object} map[string]string
+// @Router /admin/overrides [delete]
+func (h *Handler) ClearOverrides(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"cleared": h.overrides.Clear()})
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meos-graphics/internal/history"
+	"meos-graphics/internal/service"
+	"meos-graphics/internal/state"
+)
+
+// asOfKey is the context key of the historical view of a request with an at parameter
+const asOfKey = "asOf"
+
+// asOf is the state a request asks for with its at parameter
+type asOf struct {
+	snapshot *state.Snapshot
+	at       time.Time
+	service  *service.Service
+}
+
+// UseHistory lets requests ask for the data as it was at a past moment with an at
+// query parameter
+func (h *Handler) UseHistory(hist *history.History) {
+	h.history = hist
+}
+
+// AsOf returns middleware that answers requests with an at query parameter from the state
+// as it was at that time. The time is in RFC 3339 format, or local time without a zone
+// such as 2026-10-16T11:42:00.
+func (h *Handler) AsOf() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.Query("at")
+		if value == "" {
+			c.Next()
+			return
+		}
+		if h.history == nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "History is not recorded"})
+			return
+		}
+
+		at, err := parseAt(value)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid at time"})
+			return
+		}
+		if at.After(time.Now()) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "at is in the future"})
+			return
+		}
+
+		snap, err := h.history.At(at)
+		if errors.Is(err, history.ErrNotRecorded) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error": "No data recorded before " + h.history.Start().Format(time.RFC3339),
+			})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(asOfKey, asOf{snapshot: snap, at: at, service: h.service.At(snap, at)})
+		c.Next()
+	}
+}
+
+// parseAt parses an RFC 3339 time, or a local time without a zone
+func parseAt(value string) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	return time.ParseInLocation("2006-01-02T15:04:05", value, time.Local)
+}
+
+// historical returns the past state a request asks for, if it has an at parameter
+func historical(c *gin.Context) (asOf, bool) {
+	value, ok := c.Get(asOfKey)
+	if !ok {
+		return asOf{}, false
+	}
+	view, ok := value.(asOf)
+	return view, ok
+}
+
+// serviceFor returns the service that answers a request, which works on the past state
+// for requests with an at parameter
+func (h *Handler) serviceFor(c *gin.Context) *service.Service {
+	if view, ok := historical(c); ok {
+		return view.service
+	}
+	return h.service
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevisionHeader carries the state revision a response was built from
+const RevisionHeader = "X-State-Revision"
+
+// Conditional returns middleware that tags GET responses with an ETag and the state
+// revision, and answers 304 Not Modified when the client's If-None-Match still matches.
+// Responses that tell waiting from running competitors also change when a start time
+// passes, so the tag includes the number of starts so far, and the version of the manual
+// overrides. Requests for a past moment are tagged with the revision and starts at that time.
+func (h *Handler) Conditional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		var etag string
+		snap := h.state.Snapshot()
+		if view, ok := historical(c); ok {
+			snap = view.snapshot
+			etag = fmt.Sprintf(`W/"%s-h%d-%d"`, h.instance, snap.Revision(), snap.Started(view.at))
+		} else {
+			var overridesVersion uint64
+			if h.overrides != nil {
+				overridesVersion = h.overrides.Version()
+			}
+			etag = fmt.Sprintf(`W/"%s-%d-%d-%d"`, h.instance, snap.Revision(), snap.Started(h.service.Now()), overridesVersion)
+		}
+
+		c.Header("ETag", etag)
+		c.Header(RevisionHeader, strconv.FormatUint(snap.Revision(), 10))
+
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+		c.Next()
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists the tag, using the weak
+// comparison RFC 9110 requires for If-None-Match
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
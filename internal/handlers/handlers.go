@@ -1,23 +1,93 @@
 package handlers
 
 import (
+	"encoding/xml"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"meos-graphics/internal/history"
+	"meos-graphics/internal/iof"
+	"meos-graphics/internal/overrides"
 	"meos-graphics/internal/service"
 	"meos-graphics/internal/state"
 )
 
 type Handler struct {
-	service *service.Service
+	service   *service.Service
+	state     *state.State
+	overrides *overrides.Store
+	history   *history.History
+
+	// instance distinguishes ETags issued by different runs of the server, as revisions
+	// start over on restart
+	instance string
 }
 
 func New(appState *state.State) *Handler {
 	return &Handler{
-		service: service.New(appState),
+		service:  service.New(appState),
+		state:    appState,
+		instance: strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+}
+
+// UseOverrides applies the manual overrides of a store to all responses and lets the admin
+// endpoints change them
+func (h *Handler) UseOverrides(store *overrides.Store) {
+	h.overrides = store
+	h.service.UseOverrides(store)
+}
+
+// UseClock makes all responses answer as of the time returned by clock instead of the
+// current time
+func (h *Handler) UseClock(clock func() time.Time) {
+	h.service.UseClock(clock)
+}
+
+// GetEvent returns the event details
+// @Summary Get the event details
+// @Description Get the event name, organizer, date and start time with the number of classes, clubs and competitors
+// @Tags event
+// @Accept json
+// @Produce json
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {object} service.EventInfo
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 404 {object} map[string]string
+// @Router /event [get]
+func (h *Handler) GetEvent(c *gin.Context) {
+	event, err := h.serviceFor(c).GetEvent()
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
 	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// GetControls returns all radio controls
+// @Summary Get all radio controls
+// @Description Get all radio controls sorted by ID, with the number of passings recorded at each and the classes whose course includes it
+// @Tags event
+// @Accept json
+// @Produce json
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {array} service.ControlInfo
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Router /controls [get]
+func (h *Handler) GetControls(c *gin.Context) {
+	c.JSON(http.StatusOK, h.serviceFor(c).GetControls())
 }
 
 // GetClasses returns all competition classes
@@ -26,10 +96,15 @@ func New(appState *state.State) *Handler {
 // @Tags classes
 // @Accept json
 // @Produce json
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
 // @Success 200 {array} service.ClassInfo
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
 // @Router /classes [get]
 func (h *Handler) GetClasses(c *gin.Context) {
-	classes := h.service.GetClasses()
+	classes := h.serviceFor(c).GetClasses()
 	c.JSON(http.StatusOK, classes)
 }
 
@@ -40,7 +115,12 @@ func (h *Handler) GetClasses(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param classId path int true "Class ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
 // @Success 200 {array} service.StartListEntry
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /classes/{classId}/startlist [get]
@@ -51,7 +131,7 @@ func (h *Handler) GetStartList(c *gin.Context) {
 		return
 	}
 
-	startList, err := h.service.GetStartList(classID)
+	startList, err := h.serviceFor(c).GetStartList(classID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -67,7 +147,12 @@ func (h *Handler) GetStartList(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param classId path int true "Class ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
 // @Success 200 {array} service.ResultEntry
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /classes/{classId}/results [get]
@@ -78,7 +163,7 @@ func (h *Handler) GetResults(c *gin.Context) {
 		return
 	}
 
-	results, err := h.service.GetResults(classID)
+	results, err := h.serviceFor(c).GetResults(classID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -94,7 +179,12 @@ func (h *Handler) GetResults(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param classId path int true "Class ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
 // @Success 200 {object} service.SplitsResponse
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /classes/{classId}/splits [get]
@@ -105,7 +195,7 @@ func (h *Handler) GetSplits(c *gin.Context) {
 		return
 	}
 
-	splits, err := h.service.GetSplits(classID)
+	splits, err := h.serviceFor(c).GetSplits(classID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -113,3 +203,367 @@ func (h *Handler) GetSplits(c *gin.Context) {
 
 	c.JSON(http.StatusOK, splits)
 }
+
+// GetPredictions returns predicted finish times for a specific class
+// @Summary Get predicted finish times for a class
+// @Description Get the predicted finish time and virtual position of each running competitor who has passed a radio control. The prediction compares the competitor's time at the last radio control with the times of the fastest competitors there and at the finish.
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Param classId path int true "Class ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {array} service.Prediction
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /classes/{classId}/predictions [get]
+func (h *Handler) GetPredictions(c *gin.Context) {
+	var classID int
+	if _, err := fmt.Sscanf(c.Param("classId"), "%d", &classID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	predictions, err := h.serviceFor(c).GetPredictions(classID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, predictions)
+}
+
+// GetCompetitor returns the details of a single competitor
+// @Summary Get a competitor
+// @Description Get a competitor's club, class, card, status, start and finish times, running time and current position, with every split ranked within the class including leg times, and the predicted finish while running
+// @Tags competitors
+// @Accept json
+// @Produce json
+// @Param competitorId path int true "Competitor ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {object} service.CompetitorDetail
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /competitors/{competitorId} [get]
+func (h *Handler) GetCompetitor(c *gin.Context) {
+	var competitorID int
+	if _, err := fmt.Sscanf(c.Param("competitorId"), "%d", &competitorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid competitor ID"})
+		return
+	}
+
+	competitor, err := h.serviceFor(c).GetCompetitor(competitorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, competitor)
+}
+
+// GetClubs returns all clubs with competitors
+// @Summary Get all clubs
+// @Description Get a list of all clubs with at least one competitor, sorted by name, with the number of competitors each
+// @Tags clubs
+// @Accept json
+// @Produce json
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {array} service.ClubInfo
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Router /clubs [get]
+func (h *Handler) GetClubs(c *gin.Context) {
+	c.JSON(http.StatusOK, h.serviceFor(c).GetClubs())
+}
+
+// GetClub returns a club with all of its competitors
+// @Summary Get a club's competitors
+// @Description Get all of a club's competitors across the classes with their current status and result, sorted by class and then by position
+// @Tags clubs
+// @Accept json
+// @Produce json
+// @Param clubId path int true "Club ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {object} service.ClubDetail
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /clubs/{clubId} [get]
+func (h *Handler) GetClub(c *gin.Context) {
+	var clubID int
+	if _, err := fmt.Sscanf(c.Param("clubId"), "%d", &clubID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid club ID"})
+		return
+	}
+
+	club, err := h.serviceFor(c).GetClub(clubID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, club)
+}
+
+// Search finds competitors by name, club, card or bib
+// @Summary Search competitors
+// @Description Find competitors by partial name, club name, SI card number or bib. Every word of the query has to match, ignoring case and accents. Card numbers match from the start and bibs match exactly. At most 50 competitors are returned, names starting with the query first.
+// @Tags competitors
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {array} service.SearchResult
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 400 {object} map[string]string
+// @Router /search [get]
+func (h *Handler) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing search query"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.serviceFor(c).Search(query))
+}
+
+// GetTeamResults returns relay team results for a specific class
+// @Summary Get relay team results for a class
+// @Description Get the relay team results for a specific class including leg times and changeover times
+// @Tags relay
+// @Accept json
+// @Produce json
+// @Param classId path int true "Class ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {array} service.TeamResultEntry
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /classes/{classId}/teams [get]
+func (h *Handler) GetTeamResults(c *gin.Context) {
+	var classID int
+	if _, err := fmt.Sscanf(c.Param("classId"), "%d", &classID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	results, err := h.serviceFor(c).GetTeamResults(classID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetLegStandings returns the team standings after a relay leg
+// @Summary Get standings after a relay leg
+// @Description Get the team standings at the end of a relay leg, including leg times and leg ranks
+// @Tags relay
+// @Accept json
+// @Produce json
+// @Param classId path int true "Class ID"
+// @Param leg path int true "Leg number (1-based)"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {object} service.LegStandingsResponse
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /classes/{classId}/legs/{leg} [get]
+func (h *Handler) GetLegStandings(c *gin.Context) {
+	var classID int
+	if _, err := fmt.Sscanf(c.Param("classId"), "%d", &classID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	var leg int
+	if _, err := fmt.Sscanf(c.Param("leg"), "%d", &leg); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid leg"})
+		return
+	}
+
+	standings, err := h.serviceFor(c).GetLegStandings(classID, leg)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, standings)
+}
+
+// GetChangeovers returns the relay changeovers for a specific class
+// @Summary Get relay changeover times for a class
+// @Description Get all completed relay changeovers for a class in chronological order
+// @Tags relay
+// @Accept json
+// @Produce json
+// @Param classId path int true "Class ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {array} service.ChangeoverEntry
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /classes/{classId}/changeovers [get]
+func (h *Handler) GetChangeovers(c *gin.Context) {
+	var classID int
+	if _, err := fmt.Sscanf(c.Param("classId"), "%d", &classID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	changeovers, err := h.serviceFor(c).GetChangeovers(classID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, changeovers)
+}
+
+// GetIOFResultList returns the results of the whole event as IOF XML
+// @Summary Get IOF XML 3.0 result list
+// @Description Get the results of all classes as an IOF XML 3.0 ResultList document, including split times at radio controls. Relay classes are listed as team results with a result per leg. The list status is Snapshot while competitors are still out.
+// @Tags iof
+// @Produce xml
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {object} iof.ResultList
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 500 {object} map[string]string
+// @Router /iof/resultlist [get]
+func (h *Handler) GetIOFResultList(c *gin.Context) {
+	svc := h.serviceFor(c)
+	data, err := svc.GetExportData(0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	renderIOF(c, iof.NewResultList(*data, svc.Now()))
+}
+
+// GetIOFStartList returns the start list of the whole event as IOF XML
+// @Summary Get IOF XML 3.0 start list
+// @Description Get the start list of all classes as an IOF XML 3.0 StartList document
+// @Tags iof
+// @Produce xml
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {object} iof.StartList
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 500 {object} map[string]string
+// @Router /iof/startlist [get]
+func (h *Handler) GetIOFStartList(c *gin.Context) {
+	svc := h.serviceFor(c)
+	data, err := svc.GetExportData(0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	renderIOF(c, iof.NewStartList(*data, svc.Now()))
+}
+
+// GetClassIOFResultList returns the results of a class as IOF XML
+// @Summary Get IOF XML 3.0 result list for a class
+// @Description Get the results of a single class as an IOF XML 3.0 ResultList document, including split times at radio controls. Relay classes are listed as team results with a result per leg.
+// @Tags iof
+// @Produce xml
+// @Param classId path int true "Class ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {object} iof.ResultList
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /classes/{classId}/iof/resultlist [get]
+func (h *Handler) GetClassIOFResultList(c *gin.Context) {
+	var classID int
+	if _, err := fmt.Sscanf(c.Param("classId"), "%d", &classID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	svc := h.serviceFor(c)
+	data, err := svc.GetExportData(classID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	renderIOF(c, iof.NewResultList(*data, svc.Now()))
+}
+
+// GetClassIOFStartList returns the start list of a class as IOF XML
+// @Summary Get IOF XML 3.0 start list for a class
+// @Description Get the start list of a single class as an IOF XML 3.0 StartList document
+// @Tags iof
+// @Produce xml
+// @Param classId path int true "Class ID"
+// @Param If-None-Match header string false "ETag of a previously received response"
+// @Param at query string false "Return the data as it was at this time, in RFC 3339 format or local time such as 2026-10-16T11:42:00. The history is kept in memory since the server last started, for at most --history-retention (24 hours by default); earlier times get 404."
+// @Success 200 {object} iof.StartList
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Header 200 {string} ETag "Tag of the state the response was built from"
+// @Header 200 {integer} X-State-Revision "State revision the response was built from"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /classes/{classId}/iof/startlist [get]
+func (h *Handler) GetClassIOFStartList(c *gin.Context) {
+	var classID int
+	if _, err := fmt.Sscanf(c.Param("classId"), "%d", &classID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid class ID"})
+		return
+	}
+
+	svc := h.serviceFor(c)
+	data, err := svc.GetExportData(classID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	renderIOF(c, iof.NewStartList(*data, svc.Now()))
+}
+
+// renderIOF writes an IOF XML document with an XML declaration
+func renderIOF(c *gin.Context, document interface{}) {
+	output, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", append([]byte(xml.Header), output...))
+}
@@ -2,16 +2,23 @@ package handlers
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"meos-graphics/internal/history"
+	"meos-graphics/internal/iof"
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/middleware"
 	"meos-graphics/internal/models"
+	"meos-graphics/internal/overrides"
 	"meos-graphics/internal/service"
 	"meos-graphics/internal/state"
 	"meos-graphics/internal/testhelpers"
@@ -20,10 +27,25 @@ import (
 func setupTestRouter(h *Handler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(h.AsOf(), h.Conditional())
+	router.GET("/event", h.GetEvent)
+	router.GET("/controls", h.GetControls)
 	router.GET("/classes", h.GetClasses)
 	router.GET("/classes/:classId/startlist", h.GetStartList)
 	router.GET("/classes/:classId/results", h.GetResults)
 	router.GET("/classes/:classId/splits", h.GetSplits)
+	router.GET("/classes/:classId/predictions", h.GetPredictions)
+	router.GET("/classes/:classId/teams", h.GetTeamResults)
+	router.GET("/classes/:classId/legs/:leg", h.GetLegStandings)
+	router.GET("/classes/:classId/changeovers", h.GetChangeovers)
+	router.GET("/competitors/:competitorId", h.GetCompetitor)
+	router.GET("/clubs", h.GetClubs)
+	router.GET("/clubs/:clubId", h.GetClub)
+	router.GET("/search", h.Search)
+	router.GET("/classes/:classId/iof/resultlist", h.GetClassIOFResultList)
+	router.GET("/classes/:classId/iof/startlist", h.GetClassIOFStartList)
+	router.GET("/iof/resultlist", h.GetIOFResultList)
+	router.GET("/iof/startlist", h.GetIOFStartList)
 	return router
 }
 
@@ -467,6 +489,227 @@ func TestHandler_GetSplits_ClassNotFound(t *testing.T) {
 	}
 }
 
+func TestHandler_GetPredictions(t *testing.T) {
+	s := state.New()
+	class := testhelpers.CreateTestClass(1, "Elite", 10)
+	s.UpdateFromMeOS(nil, nil, []models.Class{class}, nil, nil, nil)
+	router := setupTestRouter(New(s))
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/classes/1/predictions", http.StatusOK},
+		{"/classes/999/predictions", http.StatusNotFound},
+		{"/classes/abc/predictions", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("GET %s status code = %d, want %d", tt.path, w.Code, tt.code)
+		}
+		if tt.code == http.StatusOK && w.Body.String() != "[]" {
+			t.Errorf("GET %s body = %s, want []", tt.path, w.Body.String())
+		}
+	}
+}
+
+func TestHandler_GetCompetitor(t *testing.T) {
+	s := state.New()
+	class := testhelpers.CreateTestClass(1, "Elite", 10)
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	comp := testhelpers.CreateFinishedCompetitor(1, "John Doe", club, class, 36000)
+	s.UpdateFromMeOS(nil, nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+	router := setupTestRouter(New(s))
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/competitors/1", http.StatusOK},
+		{"/competitors/999", http.StatusNotFound},
+		{"/competitors/abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("GET %s status code = %d, want %d", tt.path, w.Code, tt.code)
+		}
+	}
+}
+
+func TestHandler_EventAndControls(t *testing.T) {
+	s := state.New()
+	router := setupTestRouter(New(s))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/event", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d before the event is loaded, got %d", http.StatusNotFound, w.Code)
+	}
+
+	s.UpdateFromMeOS(&models.Event{Name: "Test Event"}, []models.Control{{ID: 31, Name: "Radio"}}, nil, nil, nil, nil)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/event", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var event service.EventInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &event); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if event.Name != "Test Event" {
+		t.Errorf("Expected event name Test Event, got %s", event.Name)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/controls", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var controls []service.ControlInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &controls); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(controls) != 1 || controls[0].Name != "Radio" {
+		t.Errorf("Expected the Radio control, got %+v", controls)
+	}
+}
+
+func TestHandler_Clubs(t *testing.T) {
+	s := state.New()
+	class := testhelpers.CreateTestClass(1, "Elite", 10)
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	comp := testhelpers.CreateFinishedCompetitor(1, "John Doe", club, class, 36000)
+	s.UpdateFromMeOS(nil, nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+	router := setupTestRouter(New(s))
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/clubs", http.StatusOK},
+		{"/clubs/1", http.StatusOK},
+		{"/clubs/999", http.StatusNotFound},
+		{"/clubs/abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("GET %s status code = %d, want %d", tt.path, w.Code, tt.code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/clubs/1", nil)
+	router.ServeHTTP(w, req)
+
+	var detail service.ClubDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(detail.Competitors) != 1 || detail.Competitors[0].Position != 1 {
+		t.Errorf("Expected John Doe in first place, got %+v", detail.Competitors)
+	}
+}
+
+func TestHandler_Search(t *testing.T) {
+	s := state.New()
+	class := testhelpers.CreateTestClass(1, "Elite", 10)
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	comp := testhelpers.CreateTestCompetitor(1, "John Doe", club, class)
+	s.UpdateFromMeOS(nil, nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+	router := setupTestRouter(New(s))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/search?q=doe", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var results []service.SearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(results) != 1 || results[0].CompetitorID != 1 {
+		t.Errorf("Expected John Doe, got %+v", results)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/search", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d without a query, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandler_RelayEndpoints(t *testing.T) {
+	s := state.New()
+	relay := testhelpers.CreateTestClass(1, "Relay", 10)
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+
+	leg1 := testhelpers.CreateFinishedCompetitor(1, "First Runner", club, relay, 36000)
+	leg2 := testhelpers.CreateTestCompetitor(2, "Second Runner", club, relay)
+	leg2.StartTime = *leg1.FinishTime
+
+	s.UpdateFromMeOS(nil, nil, []models.Class{relay}, []models.Club{club}, []models.Competitor{leg1, leg2}, []models.Team{
+		{
+			ID: 1, Name: "Relay Team", Club: club, Class: relay, Status: "0", StartTime: leg1.StartTime,
+			Legs: []models.Leg{{Number: 1, CompetitorIDs: []int{1}}, {Number: 2, CompetitorIDs: []int{2}}},
+		},
+	})
+
+	router := setupTestRouter(New(s))
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/classes/1/teams", http.StatusOK},
+		{"/classes/1/legs/1", http.StatusOK},
+		{"/classes/1/legs/3", http.StatusNotFound},
+		{"/classes/1/legs/abc", http.StatusBadRequest},
+		{"/classes/1/changeovers", http.StatusOK},
+		{"/classes/999/changeovers", http.StatusNotFound},
+		{"/classes/abc/teams", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("GET %s status code = %d, want %d", tt.path, w.Code, tt.code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/classes/1/changeovers", nil)
+	router.ServeHTTP(w, req)
+
+	var changeovers []service.ChangeoverEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &changeovers); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(changeovers) != 1 || changeovers[0].ElapsedTime != "1:00:00.0" {
+		t.Errorf("Changeovers = %+v, want one changeover after 1:00:00.0", changeovers)
+	}
+}
+
 func TestHandler_RadioTimeCalculation(t *testing.T) {
 	// Set up state with test data
 	s := state.New()
@@ -564,3 +807,335 @@ func TestHandler_ConcurrentRequests(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestHandler_IOFExport(t *testing.T) {
+	s := state.New()
+	radio := testhelpers.CreateTestControl(31, "Radio 1")
+	class1 := testhelpers.CreateTestClass(1, "Men Elite", 10, radio)
+	class2 := testhelpers.CreateTestClass(2, "Women Elite", 20)
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+
+	winner := testhelpers.CreateFinishedCompetitor(1, "John Doe", club, class1, 8340)
+	winner.Splits = []models.Split{testhelpers.CreateTestSplit(radio, 3020, winner.StartTime)}
+	second := testhelpers.CreateFinishedCompetitor(2, "Jane Smith", club, class1, 9120)
+	other := testhelpers.CreateTestCompetitor(3, "Mike Johnson", club, class2)
+
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), []models.Control{radio}, []models.Class{class1, class2},
+		[]models.Club{club}, []models.Competitor{second, winner, other}, nil)
+
+	router := setupTestRouter(New(s))
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/iof/resultlist", http.StatusOK},
+		{"/iof/startlist", http.StatusOK},
+		{"/classes/1/iof/resultlist", http.StatusOK},
+		{"/classes/1/iof/startlist", http.StatusOK},
+		{"/classes/999/iof/resultlist", http.StatusNotFound},
+		{"/classes/abc/iof/startlist", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("GET %s status code = %d, want %d", tt.path, w.Code, tt.code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/classes/1/iof/resultlist", nil)
+	router.ServeHTTP(w, req)
+
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/xml; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want application/xml", contentType)
+	}
+
+	var list iof.ResultList
+	if err := xml.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to parse result list: %v", err)
+	}
+	if list.XMLNS != iof.Namespace || list.IOFVersion != "3.0" {
+		t.Errorf("Missing IOF namespace or version: %q %q", list.XMLNS, list.IOFVersion)
+	}
+	if len(list.ClassResults) != 1 || len(list.ClassResults[0].PersonResults) != 2 {
+		t.Fatalf("Expected one class with two results, got %+v", list.ClassResults)
+	}
+
+	first := list.ClassResults[0].PersonResults[0]
+	if first.Person.Name.Given != "John" || first.Person.Name.Family != "Doe" {
+		t.Errorf("First result name = %+v, want John Doe", first.Person.Name)
+	}
+	result := first.Results[0]
+	if result.Position != 1 || result.Status != iof.StatusOK || result.Time == nil || *result.Time != 834 {
+		t.Errorf("Unexpected winner result: %+v", result)
+	}
+	if len(result.SplitTimes) != 1 || result.SplitTimes[0].ControlCode != "31" || *result.SplitTimes[0].Time != 302 {
+		t.Errorf("Unexpected split times: %+v", result.SplitTimes)
+	}
+}
+
+func TestHandler_ConditionalGET(t *testing.T) {
+	s := state.New()
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	class := testhelpers.CreateTestClass(1, "Men Elite", 10)
+	comp := testhelpers.CreateFinishedCompetitor(1, "John Doe", club, class, 8340)
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+
+	router := setupTestRouter(New(s))
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("/classes/1/results", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("First request: status %d, ETag %q", first.Code, etag)
+	}
+	if got := first.Header().Get(RevisionHeader); got != "1" {
+		t.Errorf("%s = %q, want %q", RevisionHeader, got, "1")
+	}
+
+	// Unchanged state answers 304 without a body, on every endpoint
+	for _, path := range []string{"/classes/1/results", "/classes", "/iof/resultlist"} {
+		w := get(path, etag)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("GET %s with matching If-None-Match: status %d, want 304", path, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("GET %s 304 response has a body", path)
+		}
+	}
+	if w := get("/classes", `"other", `+etag); w.Code != http.StatusNotModified {
+		t.Errorf("ETag in a list: status %d, want 304", w.Code)
+	}
+
+	// A state change makes the old tag stale
+	comp.FinishTime = nil
+	comp.Status = "0"
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+	w := get("/classes/1/results", etag)
+	if w.Code != http.StatusOK {
+		t.Errorf("Stale If-None-Match: status %d, want 200", w.Code)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Error("ETag did not change with the state")
+	}
+	if got := w.Header().Get(RevisionHeader); got != "2" {
+		t.Errorf("%s = %q, want %q", RevisionHeader, got, "2")
+	}
+
+	// An update without changes keeps the tag
+	etag = w.Header().Get("ETag")
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+	if w := get("/classes/1/results", etag); w.Code != http.StatusNotModified {
+		t.Errorf("Unchanged update: status %d, want 304", w.Code)
+	}
+
+}
+
+func TestHandler_Overrides(t *testing.T) {
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	s := state.New()
+	class := testhelpers.CreateTestClass(1, "H21", 1)
+	clubs := []models.Club{testhelpers.CreateTestClub(1, "OK Pan", "DEN"), testhelpers.CreateTestClub(2, "OK Ravinen", "DEN")}
+	comp := testhelpers.CreateFinishedCompetitor(1, "Anna", clubs[0], class, 18000)
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, []models.Class{class}, clubs, []models.Competitor{comp}, nil)
+
+	h := New(s)
+	h.UseOverrides(overrides.New(s))
+	router := setupTestRouter(h)
+	admin := router.Group("/admin")
+	admin.Use(middleware.BearerToken("secret"))
+	admin.GET("/overrides", h.ListOverrides)
+	admin.PUT("/overrides/:competitorId", h.SetOverride)
+	admin.DELETE("/overrides/:competitorId", h.DeleteOverride)
+	admin.DELETE("/overrides", h.ClearOverrides)
+
+	request := func(method, path, token, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		token        string
+		body         string
+		expectedCode int
+	}{
+		{"no token", "GET", "/admin/overrides", "", "", http.StatusUnauthorized},
+		{"wrong token", "GET", "/admin/overrides", "wrong", "", http.StatusUnauthorized},
+		{"list", "GET", "/admin/overrides", "secret", "", http.StatusOK},
+		{"invalid competitor ID", "PUT", "/admin/overrides/abc", "secret", `{"hidden": true}`, http.StatusBadRequest},
+		{"invalid body", "PUT", "/admin/overrides/1", "secret", `{"hidden": "yes"}`, http.StatusBadRequest},
+		{"unknown competitor", "PUT", "/admin/overrides/99", "secret", `{"hidden": true}`, http.StatusNotFound},
+		{"unknown status", "PUT", "/admin/overrides/1", "secret", `{"status": "7"}`, http.StatusBadRequest},
+		{"delete missing", "DELETE", "/admin/overrides/1", "secret", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := request(tt.method, tt.path, tt.token, tt.body); w.Code != tt.expectedCode {
+				t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, w.Code, tt.expectedCode)
+			}
+		})
+	}
+
+	etag := request("GET", "/classes/1/results", "", "").Header().Get("ETag")
+
+	w := request("PUT", "/admin/overrides/1", "secret", `{"name": "Anna S", "clubId": 2, "timeAdjustment": -60, "reason": "Typo"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT override: status %d, body %s", w.Code, w.Body.String())
+	}
+
+	// Results carry the override and the flag, under a new ETag
+	w = request("GET", "/classes/1/results", "", "")
+	if w.Header().Get("ETag") == etag {
+		t.Error("ETag did not change with the overrides")
+	}
+	var results []service.ResultEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to unmarshal results: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "Anna S" || results[0].Club != "OK Ravinen" ||
+		results[0].RunningTime != "29:00.0" || !results[0].Overridden {
+		t.Errorf("Results = %+v", results)
+	}
+
+	var detail service.CompetitorDetail
+	w = request("GET", "/competitors/1", "", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("Failed to unmarshal competitor: %v", err)
+	}
+	if !detail.Overridden || detail.Club != "OK Ravinen" {
+		t.Errorf("Competitor = %+v", detail)
+	}
+
+	var list []overrides.Override
+	w = request("GET", "/admin/overrides", "secret", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal overrides: %v", err)
+	}
+	if len(list) != 1 || list[0].CompetitorID != 1 || list[0].Reason != "Typo" {
+		t.Errorf("Overrides = %+v", list)
+	}
+
+	// Hidden competitors disappear from every list
+	request("PUT", "/admin/overrides/1", "secret", `{"hidden": true}`)
+	if w := request("GET", "/competitors/1", "", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Hidden competitor: status %d, want 404", w.Code)
+	}
+
+	if w := request("DELETE", "/admin/overrides/1", "secret", ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE override: status %d, want 204", w.Code)
+	}
+	var cleared service.CompetitorDetail
+	w = request("GET", "/competitors/1", "", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &cleared); err != nil {
+		t.Fatalf("Failed to unmarshal competitor: %v", err)
+	}
+	if cleared.Overridden || cleared.Name != "Anna" {
+		t.Errorf("Competitor after clearing = %+v", cleared)
+	}
+
+	request("PUT", "/admin/overrides/1", "secret", `{"status": "5"}`)
+	if w := request("DELETE", "/admin/overrides", "secret", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"cleared":1`) {
+		t.Errorf("DELETE overrides: status %d, body %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandler_AsOf(t *testing.T) {
+	s := state.New()
+	class := testhelpers.CreateTestClass(1, "H21", 1)
+	club := testhelpers.CreateTestClub(1, "OK Pan", "DEN")
+	comp := testhelpers.CreateTestCompetitor(1, "Anna", club, class)
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+
+	beforeHistory := time.Now().Add(-time.Minute).Format(time.RFC3339)
+	hist := history.New(s, time.Hour)
+	s.OnChange(hist.HandleChange)
+	h := New(s)
+	h.UseHistory(hist)
+	router := setupTestRouter(h)
+
+	time.Sleep(5 * time.Millisecond)
+	running := time.Now().Format(time.RFC3339Nano)
+	time.Sleep(5 * time.Millisecond)
+
+	comp = testhelpers.CreateFinishedCompetitor(1, "Anna", club, class, 18000)
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{"invalid time", "/classes/1/results?at=noon", http.StatusBadRequest},
+		{"future", "/classes/1/results?at=" + time.Now().Add(time.Hour).Format(time.RFC3339), http.StatusBadRequest},
+		{"before the history", "/classes/1/results?at=" + beforeHistory, http.StatusNotFound},
+		{"local time", "/classes?at=" + time.Now().Add(-time.Minute).Format("2006-01-02T15:04:05"), http.StatusNotFound},
+		{"competitor", "/competitors/1?at=" + running, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := get(tt.path); w.Code != tt.expectedCode {
+				t.Errorf("GET %s: status %d, want %d", tt.path, w.Code, tt.expectedCode)
+			}
+		})
+	}
+
+	// The results before the finish show Anna without a time, under the old revision
+	w := get("/classes/1/results?at=" + running)
+	if got := w.Header().Get(RevisionHeader); got != "1" {
+		t.Errorf("%s = %q, want %q", RevisionHeader, got, "1")
+	}
+	var results []service.ResultEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to unmarshal results: %v", err)
+	}
+	if len(results) != 1 || results[0].RunningTime != "" {
+		t.Errorf("Results before the finish = %+v", results)
+	}
+
+	// IOF documents are created as of the requested time
+	for _, path := range []string{"/iof/resultlist", "/classes/1/iof/startlist"} {
+		w = get(path + "?at=" + running)
+		if body := w.Body.String(); !strings.Contains(body, `createTime="`+running+`"`) {
+			t.Errorf("GET %s?at= does not have CreateTime %s: %s", path, running, body)
+		}
+	}
+
+	w = get("/classes/1/results")
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to unmarshal results: %v", err)
+	}
+	if len(results) != 1 || results[0].RunningTime != "30:00.0" {
+		t.Errorf("Current results = %+v", results)
+	}
+}
@@ -0,0 +1,219 @@
+package history
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+// ErrNotRecorded is returned for times before the history starts
+var ErrNotRecorded = errors.New("no data recorded at that time")
+
+// pruneInterval is how often versions older than the retention window are dropped
+const pruneInterval = time.Minute
+
+// version is the value of an entity from a moment on, until the next version
+type version[T any] struct {
+	at      time.Time
+	value   T
+	removed bool
+}
+
+// versions keeps the versions of all entities of a kind by ID, oldest first
+type versions[T any] map[int][]version[T]
+
+// record adds a version for each of the given IDs, taken from the snapshot, or marks the
+// entity removed when the snapshot does not have it
+func (v versions[T]) record(at time.Time, ids []int, lookup func(int) (T, bool)) {
+	for _, id := range ids {
+		value, ok := lookup(id)
+		v[id] = append(v[id], version[T]{at: at, value: value, removed: !ok})
+	}
+}
+
+// at returns the values of the entities that existed at the given time, ordered by ID
+func (v versions[T]) at(t time.Time) []T {
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	values := make([]T, 0, len(ids))
+	for _, id := range ids {
+		if value, ok := latest(v[id], t); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// prune drops the versions superseded before cutoff, and the entities removed by then
+func (v versions[T]) prune(cutoff time.Time) {
+	for id, list := range v {
+		if list = trim(list, cutoff); len(list) == 0 {
+			delete(v, id)
+		} else {
+			v[id] = list
+		}
+	}
+}
+
+// trim drops the versions superseded before cutoff. The version in effect at cutoff is
+// kept as the starting point, unless it marks the entity removed.
+func trim[T any](list []version[T], cutoff time.Time) []version[T] {
+	i := sort.Search(len(list), func(i int) bool { return list[i].at.After(cutoff) })
+	if i == 0 {
+		return list
+	}
+	first := i - 1
+	if list[first].removed {
+		first = i
+	}
+	if first == 0 {
+		return list
+	}
+	return append([]version[T](nil), list[first:]...)
+}
+
+// latest returns the value of the last version at or before t
+func latest[T any](list []version[T], t time.Time) (T, bool) {
+	i := sort.Search(len(list), func(i int) bool { return list[i].at.After(t) })
+	if i == 0 || list[i-1].removed {
+		var zero T
+		return zero, false
+	}
+	return list[i-1].value, true
+}
+
+// History records every version of the competition data, so the state can be rebuilt as
+// it was at any moment within the retention window since the server started. Only what
+// changed is stored with each update: competitors, teams, controls, clubs, classes and the
+// event each keep their own versions. The history is kept in memory and lost on restart.
+type History struct {
+	retention time.Duration
+
+	mu     sync.RWMutex
+	prev   *state.Snapshot
+	start  time.Time // Earliest time the state can be rebuilt for
+	pruned time.Time
+	// Revisions in the order they were received
+	revisions []version[uint64]
+
+	event       []version[*models.Event]
+	controls    versions[models.Control]
+	classes     versions[models.Class]
+	clubs       versions[models.Club]
+	competitors versions[models.Competitor]
+	teams       versions[models.Team]
+
+	// The last snapshot rebuilt, which serves every time until the next revision
+	cached *state.Snapshot
+}
+
+// New creates a history that starts with the current state and keeps the versions of the
+// last retention period
+func New(appState *state.State, retention time.Duration) *History {
+	return newHistory(appState, time.Now(), retention)
+}
+
+func newHistory(appState *state.State, now time.Time, retention time.Duration) *History {
+	h := &History{
+		retention:   retention,
+		prev:        state.NewSnapshot(0, nil, nil, nil, nil, nil, nil),
+		start:       now,
+		pruned:      now,
+		controls:    versions[models.Control]{},
+		classes:     versions[models.Class]{},
+		clubs:       versions[models.Club]{},
+		competitors: versions[models.Competitor]{},
+		teams:       versions[models.Team]{},
+	}
+	h.update(appState.Snapshot(), now)
+	return h
+}
+
+// HandleChange records the data of a state update
+func (h *History) HandleChange(change state.Change) {
+	if change.Current != nil {
+		h.update(change.Current, time.Now())
+	}
+}
+
+// update records the versions of everything that changed up to the given snapshot. A
+// snapshot older than the last one recorded, from a listener that ran late, adds nothing.
+func (h *History) update(current *state.Snapshot, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.revisions) > 0 && current.Revision() <= h.prev.Revision() {
+		return
+	}
+	change := state.Diff(h.prev, current)
+	h.prev = current
+
+	h.revisions = append(h.revisions, version[uint64]{at: now, value: current.Revision()})
+	if change.Event || len(h.event) == 0 {
+		h.event = append(h.event, version[*models.Event]{at: now, value: current.Event()})
+	}
+	h.controls.record(now, change.Controls, current.Control)
+	h.classes.record(now, change.Classes, current.Class)
+	h.clubs.record(now, change.Clubs, current.Club)
+	h.competitors.record(now, change.Competitors, current.Competitor)
+	h.teams.record(now, change.Teams, current.Team)
+
+	if now.Sub(h.pruned) >= pruneInterval {
+		h.prune(now.Add(-h.retention))
+		h.pruned = now
+	}
+}
+
+// prune forgets the state before cutoff
+func (h *History) prune(cutoff time.Time) {
+	if !cutoff.After(h.start) {
+		return
+	}
+	h.start = cutoff
+	h.revisions = trim(h.revisions, cutoff)
+	h.event = trim(h.event, cutoff)
+	h.controls.prune(cutoff)
+	h.classes.prune(cutoff)
+	h.clubs.prune(cutoff)
+	h.competitors.prune(cutoff)
+	h.teams.prune(cutoff)
+}
+
+// Start returns the earliest time the state can be rebuilt for
+func (h *History) Start() time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.start
+}
+
+// At rebuilds the state as it was at the given time
+func (h *History) At(t time.Time) (*state.Snapshot, error) {
+	h.mu.RLock()
+	revision, ok := latest(h.revisions, t)
+	if !ok || t.Before(h.start) {
+		h.mu.RUnlock()
+		return nil, ErrNotRecorded
+	}
+	if h.cached != nil && h.cached.Revision() == revision {
+		defer h.mu.RUnlock()
+		return h.cached, nil
+	}
+
+	event, _ := latest(h.event, t)
+	snap := state.NewSnapshot(revision, event, h.controls.at(t), h.classes.at(t), h.clubs.at(t),
+		h.competitors.at(t), h.teams.at(t))
+	h.mu.RUnlock()
+
+	h.mu.Lock()
+	h.cached = snap
+	h.mu.Unlock()
+	return snap, nil
+}
@@ -0,0 +1,193 @@
+package history
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+func TestHistory_At(t *testing.T) {
+	base := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	classes := []models.Class{{ID: 1, Name: "H21"}, {ID: 2, Name: "D21"}}
+	club := models.Club{ID: 5, Name: "OK Test"}
+	competitors := []models.Competitor{
+		{ID: 2, Name: "Bert", Status: "0", Class: classes[0], Club: club, StartTime: base},
+		{ID: 1, Name: "Anna", Status: "0", Class: classes[0], Club: club, StartTime: base},
+	}
+
+	appState := state.New()
+	h := newHistory(appState, base.Add(-time.Hour), 24*time.Hour)
+
+	update := func(now time.Time) {
+		t.Helper()
+		appState.UpdateFromMeOS(&models.Event{Name: "Test"}, nil, classes, []models.Club{club}, competitors, nil)
+		h.update(appState.Snapshot(), now)
+	}
+
+	update(base)
+
+	// Anna finishes, then is disqualified and Bert is removed
+	finish := base.Add(30 * time.Minute)
+	competitors[1].Status = "1"
+	competitors[1].FinishTime = &finish
+	update(finish)
+
+	competitors[1].Status = "5"
+	competitors = competitors[1:]
+	update(base.Add(time.Hour))
+
+	// An update without changes records nothing
+	update(base.Add(2 * time.Hour))
+	if len(h.revisions) != 4 {
+		t.Errorf("Expected 4 revisions, got %d", len(h.revisions))
+	}
+
+	if h.Start() != base.Add(-time.Hour) {
+		t.Errorf("Start() = %s", h.Start())
+	}
+	if _, err := h.At(base.Add(-2 * time.Hour)); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("At() before the start: error = %v, want ErrNotRecorded", err)
+	}
+
+	snap, err := h.At(base.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("At(): %v", err)
+	}
+	if snap.Revision() != 0 || len(snap.Competitors()) != 0 || snap.Event() != nil {
+		t.Errorf("Expected the empty state before loading, got revision %d with %d competitors", snap.Revision(), len(snap.Competitors()))
+	}
+
+	snap, err = h.At(base.Add(10 * time.Minute))
+	if err != nil {
+		t.Fatalf("At(): %v", err)
+	}
+	if snap.Revision() != 1 || snap.Event().Name != "Test" || len(snap.Classes()) != 2 {
+		t.Errorf("Unexpected snapshot at revision %d: %+v", snap.Revision(), snap.Event())
+	}
+	competitorsAt := snap.CompetitorsByClass(1)
+	if len(competitorsAt) != 2 || competitorsAt[0].Name != "Anna" || competitorsAt[0].Status != "0" {
+		t.Errorf("Expected both competitors running, ordered by ID, got %+v", competitorsAt)
+	}
+
+	// The time of a change is included
+	snap, _ = h.At(finish)
+	if anna, _ := snap.Competitor(1); anna.Status != "1" || anna.FinishTime == nil {
+		t.Errorf("Expected Anna finished, got %+v", anna)
+	}
+	if _, ok := snap.Competitor(2); !ok {
+		t.Error("Bert missing before he was removed")
+	}
+
+	snap, _ = h.At(base.Add(3 * time.Hour))
+	if snap.Revision() != 3 {
+		t.Errorf("Revision = %d, want 3", snap.Revision())
+	}
+	if anna, _ := snap.Competitor(1); anna.Status != "5" {
+		t.Errorf("Expected Anna disqualified, got %+v", anna)
+	}
+	if _, ok := snap.Competitor(2); ok {
+		t.Error("Bert still there after he was removed")
+	}
+	if again, _ := h.At(base.Add(4 * time.Hour)); again != snap {
+		t.Error("Expected the cached snapshot for the same revision")
+	}
+}
+
+func TestHistory_Retention(t *testing.T) {
+	base := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	classes := []models.Class{{ID: 1, Name: "H21"}}
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Anna", Status: "0", Class: classes[0], StartTime: base},
+		{ID: 2, Name: "Bert", Status: "0", Class: classes[0], StartTime: base},
+	}
+
+	appState := state.New()
+	h := newHistory(appState, base, 2*time.Hour)
+
+	update := func(now time.Time) {
+		t.Helper()
+		appState.UpdateFromMeOS(&models.Event{Name: "Test"}, nil, classes, nil, competitors, nil)
+		h.update(appState.Snapshot(), now)
+	}
+
+	update(base)
+	finish := base.Add(time.Hour)
+	competitors[0].Status = "1"
+	competitors[0].FinishTime = &finish
+	update(finish)
+	competitors[0].Status = "5"
+	competitors = competitors[:1]
+	update(base.Add(2 * time.Hour))
+
+	// Three hours in, the first hour is forgotten
+	competitors[0].Name = "Anna B"
+	update(base.Add(3 * time.Hour))
+	if h.Start() != base.Add(time.Hour) {
+		t.Errorf("Start() = %s, want %s", h.Start(), base.Add(time.Hour))
+	}
+	if _, err := h.At(base.Add(30 * time.Minute)); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("At() before the retention window: error = %v, want ErrNotRecorded", err)
+	}
+	snap, err := h.At(base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("At(): %v", err)
+	}
+	if anna, _ := snap.Competitor(1); anna.Status != "1" || snap.Event() == nil || len(snap.Classes()) != 1 {
+		t.Errorf("Expected Anna finished at the start of the window, got %+v", anna)
+	}
+	if _, ok := snap.Competitor(2); !ok {
+		t.Error("Bert missing before he was removed")
+	}
+
+	// Versions before the window are dropped, and removed entities once they are gone
+	classes[0].Name = "Men 21"
+	update(base.Add(5 * time.Hour))
+	if len(h.competitors[1]) != 1 {
+		t.Errorf("Expected Anna's last version only, got %d versions", len(h.competitors[1]))
+	}
+	if _, ok := h.competitors[2]; ok {
+		t.Error("Bert still recorded after he was removed before the window")
+	}
+	snap, _ = h.At(base.Add(5 * time.Hour))
+	if anna, _ := snap.Competitor(1); anna.Name != "Anna B" || anna.Status != "5" || snap.Revision() != 5 {
+		t.Errorf("Unexpected current state at revision %d: %+v", snap.Revision(), anna)
+	}
+}
+
+func TestHistory_RecordsEachUpdate(t *testing.T) {
+	base := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	classes := []models.Class{{ID: 1, Name: "H21"}}
+	competitors := []models.Competitor{{ID: 1, Name: "Anna", Status: "1", Class: classes[0]}}
+	appState := state.New()
+	h := newHistory(appState, base, 24*time.Hour)
+
+	// Anna is disqualified and reinstated before the history hears of either update
+	var changes []state.Change
+	appState.OnChange(func(change state.Change) { changes = append(changes, change) })
+	appState.UpdateFromMeOS(nil, nil, classes, nil, competitors, nil)
+	competitors[0].Status = "5"
+	appState.UpdateFromMeOS(nil, nil, classes, nil, competitors, nil)
+	competitors[0].Status = "1"
+	appState.UpdateFromMeOS(nil, nil, classes, nil, competitors, nil)
+
+	for i, change := range changes {
+		h.update(change.Current, base.Add(time.Duration(i+1)*time.Minute))
+	}
+	// A late listener for an older update changes nothing
+	h.update(changes[0].Current, base.Add(time.Hour))
+
+	snap, err := h.At(base.Add(2 * time.Minute))
+	if err != nil {
+		t.Fatalf("At(): %v", err)
+	}
+	if anna, _ := snap.Competitor(1); anna.Status != "5" || snap.Revision() != 2 {
+		t.Errorf("Expected Anna disqualified at revision 2, got %+v at revision %d", anna, snap.Revision())
+	}
+	snap, _ = h.At(base.Add(2 * time.Hour))
+	if anna, _ := snap.Competitor(1); anna.Status != "1" || snap.Revision() != 3 {
+		t.Errorf("Expected Anna reinstated at revision 3, got %+v at revision %d", anna, snap.Revision())
+	}
+}
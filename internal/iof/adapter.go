@@ -0,0 +1,211 @@
+package iof
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/state"
+)
+
+// Adapter loads IOF XML 3.0 StartList and ResultList documents from a file or directory
+// and reloads them whenever they change
+type Adapter struct {
+	path         string
+	pollInterval time.Duration
+	state        *state.State
+
+	mu       sync.RWMutex
+	polling  bool
+	stopChan chan struct{}
+	done     chan struct{} // Closed when the polling goroutine has exited
+	modTimes map[string]time.Time
+}
+
+// NewAdapter creates an adapter watching path, which may be a single XML file or a
+// directory of XML files
+func NewAdapter(path string, pollInterval time.Duration, appState *state.State) *Adapter {
+	return &Adapter{
+		path:         path,
+		pollInterval: pollInterval,
+		state:        appState,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Connect loads the documents currently on disk
+func (a *Adapter) Connect() error {
+	if _, err := os.Stat(a.path); err != nil {
+		return fmt.Errorf("failed to access IOF XML path: %w", err)
+	}
+	return a.reload()
+}
+
+// StartPolling checks the watched files every poll interval and reloads them on change
+func (a *Adapter) StartPolling() error {
+	a.mu.Lock()
+	if a.polling {
+		a.mu.Unlock()
+		return fmt.Errorf("already watching %s", a.path)
+	}
+	a.polling = true
+	a.done = make(chan struct{})
+	a.mu.Unlock()
+
+	go func() {
+		defer close(a.done)
+
+		ticker := time.NewTicker(a.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-a.stopChan:
+				return
+			case <-ticker.C:
+				changed, err := a.changed()
+				if err != nil {
+					logger.ErrorLogger.Printf("Failed to check IOF XML files: %v", err)
+					continue
+				}
+				if !changed {
+					continue
+				}
+				if err := a.reload(); err != nil {
+					logger.ErrorLogger.Printf("Failed to load IOF XML files: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops watching for changes and waits for a reload in progress to finish
+func (a *Adapter) Stop() error {
+	a.mu.Lock()
+	if !a.polling {
+		a.mu.Unlock()
+		return nil
+	}
+	close(a.stopChan)
+	a.polling = false
+	done := a.done
+	a.mu.Unlock()
+
+	// The polling goroutine takes the lock to reload, so wait without holding it
+	<-done
+	return nil
+}
+
+// files returns the XML files to load with their modification times
+func (a *Adapter) files() (map[string]time.Time, error) {
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]time.Time)
+	if !info.IsDir() {
+		files[a.path] = info.ModTime()
+		return files, nil
+	}
+
+	entries, err := os.ReadDir(a.path)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".xml") {
+			continue
+		}
+		entryInfo, err := entry.Info()
+		if err != nil {
+			// The file was removed while listing
+			continue
+		}
+		files[filepath.Join(a.path, entry.Name())] = entryInfo.ModTime()
+	}
+	return files, nil
+}
+
+// changed reports whether files were added, removed or modified since the last load
+func (a *Adapter) changed() (bool, error) {
+	files, err := a.files()
+	if err != nil {
+		return false, err
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if len(files) != len(a.modTimes) {
+		return true, nil
+	}
+	for path, modTime := range files {
+		if previous, ok := a.modTimes[path]; !ok || !previous.Equal(modTime) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reload parses all documents and replaces the state with their merged contents
+func (a *Adapter) reload() error {
+	files, err := a.files()
+	if err != nil {
+		return err
+	}
+
+	// Remember what was loaded even if parsing fails; a file that is still being
+	// written gets a new modification time when it is complete
+	a.mu.Lock()
+	a.modTimes = files
+	a.mu.Unlock()
+
+	// Load older files first so newer documents win
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if files[paths[i]].Equal(files[paths[j]]) {
+			return paths[i] < paths[j]
+		}
+		return files[paths[i]].Before(files[paths[j]])
+	})
+
+	b := newBuilder()
+	loaded := 0
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		handled, err := b.add(data)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		if !handled {
+			logger.DebugLogger.Printf("Skipping %s: not an IOF XML StartList or ResultList", path)
+			continue
+		}
+		loaded++
+	}
+
+	if loaded == 0 {
+		return fmt.Errorf("no IOF XML StartList or ResultList found in %s", a.path)
+	}
+
+	event, controls, classes, clubs, competitors := b.build()
+	a.state.UpdateFromMeOS(event, controls, classes, clubs, competitors, nil)
+
+	logger.InfoLogger.Printf("Loaded %d IOF XML documents with %d classes, %d clubs, %d competitors",
+		loaded, len(classes), len(clubs), len(competitors))
+	return nil
+}
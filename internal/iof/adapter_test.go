@@ -0,0 +1,102 @@
+package iof
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/state"
+	"meos-graphics/internal/testhelpers"
+)
+
+func TestAdapter_LoadsDirectory(t *testing.T) {
+	// Initialize logger for tests
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "startlist.xml"), testhelpers.IOFStartListXML())
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not xml")
+
+	appState := state.New()
+	adapter := NewAdapter(dir, 20*time.Millisecond, appState)
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	if got := len(appState.GetCompetitors()); got != 3 {
+		t.Fatalf("Got %d competitors, want 3", got)
+	}
+	if competitor := appState.GetCompetitor(11); competitor == nil || competitor.Status != "0" {
+		t.Fatalf("Competitor 11 should be loaded from the start list, got %+v", competitor)
+	}
+
+	updates := make(chan struct{}, 10)
+	appState.OnUpdate(func() { updates <- struct{}{} })
+
+	if err := adapter.StartPolling(); err != nil {
+		t.Fatalf("StartPolling() error = %v", err)
+	}
+	defer adapter.Stop()
+
+	// A new result list is picked up on the next check
+	writeFile(t, filepath.Join(dir, "results.xml"), testhelpers.IOFResultListXML())
+
+	select {
+	case <-updates:
+	case <-time.After(2 * time.Second):
+		t.Fatal("State was not updated after adding a result list")
+	}
+
+	if competitor := appState.GetCompetitor(11); competitor == nil || competitor.Status != "1" || competitor.FinishTime == nil {
+		t.Errorf("Competitor 11 should be finished after loading results, got %+v", competitor)
+	}
+}
+
+func TestAdapter_SingleFile(t *testing.T) {
+	// Initialize logger for tests
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "results.xml")
+	writeFile(t, path, testhelpers.IOFResultListXML())
+
+	appState := state.New()
+	if err := NewAdapter(path, time.Second, appState).Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if event := appState.GetEvent(); event == nil || event.Name != "IOF Competition" {
+		t.Errorf("Event not loaded: %+v", event)
+	}
+	if got := len(appState.GetCompetitors()); got != 2 {
+		t.Errorf("Got %d competitors, want 2", got)
+	}
+}
+
+func TestAdapter_ConnectErrors(t *testing.T) {
+	// Initialize logger for tests
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	if err := NewAdapter(filepath.Join(t.TempDir(), "missing"), time.Second, state.New()).Connect(); err == nil {
+		t.Error("Connect() should fail for a missing path")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "entries.xml"), `<EntryList xmlns="http://www.orienteering.org/datastandard/3.0"/>`)
+	if err := NewAdapter(dir, time.Second, state.New()).Connect(); err == nil {
+		t.Error("Connect() should fail when no start or result list is found")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,377 @@
+package iof
+
+import (
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"meos-graphics/internal/models"
+)
+
+// dateTimeLayouts are the accepted formats of xs:dateTime values, with and without zone
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+}
+
+// builder merges IOF XML documents into the application's models
+type builder struct {
+	event *models.Event
+
+	controls     map[int]models.Control
+	classes      map[int]models.Class
+	classOrder   []int
+	classRadio   map[int][]int
+	clubs        map[int]models.Club
+	competitors  map[int]models.Competitor
+	competitorID []int
+
+	startLists  []StartList
+	resultLists []ResultList
+}
+
+func newBuilder() *builder {
+	return &builder{
+		controls:    make(map[int]models.Control),
+		classes:     make(map[int]models.Class),
+		classRadio:  make(map[int][]int),
+		clubs:       make(map[int]models.Club),
+		competitors: make(map[int]models.Competitor),
+	}
+}
+
+// add parses an IOF XML document to be merged by build. Documents other than StartList
+// and ResultList are ignored and reported as not handled.
+func (b *builder) add(data []byte) (bool, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return false, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	switch root.XMLName.Local {
+	case "StartList":
+		var list StartList
+		if err := xml.Unmarshal(data, &list); err != nil {
+			return false, fmt.Errorf("failed to parse StartList: %w", err)
+		}
+		b.startLists = append(b.startLists, list)
+	case "ResultList":
+		var list ResultList
+		if err := xml.Unmarshal(data, &list); err != nil {
+			return false, fmt.Errorf("failed to parse ResultList: %w", err)
+		}
+		b.resultLists = append(b.resultLists, list)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (b *builder) addStartList(list StartList) {
+	b.setEvent(list.Event)
+
+	for _, classStart := range list.ClassStarts {
+		class := b.class(classStart.Class)
+		for _, personStart := range classStart.PersonStarts {
+			competitor := b.competitor(personStart.Person, personStart.Organisation, class)
+			competitor.Status = "0"
+			if len(personStart.Starts) > 0 {
+				start := personStart.Starts[0]
+				competitor.Bib = start.BibNumber
+				competitor.Card = parseInt(start.ControlCard)
+				if startTime, ok := parseDateTime(start.StartTime); ok {
+					competitor.StartTime = startTime
+				}
+			}
+			b.setCompetitor(competitor)
+		}
+	}
+}
+
+func (b *builder) addResultList(list ResultList) {
+	b.setEvent(list.Event)
+
+	for _, classResult := range list.ClassResults {
+		class := b.class(classResult.Class)
+		for _, personResult := range classResult.PersonResults {
+			competitor := b.competitor(personResult.Person, personResult.Organisation, class)
+			if len(personResult.Results) == 0 {
+				b.setCompetitor(competitor)
+				continue
+			}
+
+			result := personResult.Results[0]
+			if result.BibNumber != "" {
+				competitor.Bib = result.BibNumber
+			}
+			if card := parseInt(result.ControlCard); card != 0 {
+				competitor.Card = card
+			}
+			competitor.Status = meosStatus(result.Status)
+			competitor.FinishTime = nil
+			competitor.Splits = []models.Split{}
+
+			startTime, hasStart := parseDateTime(result.StartTime)
+			finishTime, hasFinish := parseDateTime(result.FinishTime)
+
+			// Derive missing start or finish times from the running time
+			if result.Time != nil {
+				runningTime := seconds(*result.Time)
+				if hasStart && !hasFinish && competitor.Status == "1" {
+					finishTime, hasFinish = startTime.Add(runningTime), true
+				} else if !hasStart && hasFinish {
+					startTime, hasStart = finishTime.Add(-runningTime), true
+				}
+			}
+			if hasStart {
+				competitor.StartTime = startTime
+			}
+			if hasFinish {
+				competitor.FinishTime = &finishTime
+			}
+
+			var radio []int
+			for _, split := range result.SplitTimes {
+				if split.Status == SplitStatusAdditional {
+					continue
+				}
+				control := b.control(split.ControlCode)
+				radio = append(radio, control.ID)
+				if split.Status == SplitStatusMissing || split.Time == nil || competitor.StartTime.IsZero() {
+					continue
+				}
+				competitor.Splits = append(competitor.Splits, models.Split{
+					Control:     control,
+					PassingTime: competitor.StartTime.Add(seconds(*split.Time)),
+				})
+			}
+			b.classRadio[class.ID] = mergeControlOrder(b.classRadio[class.ID], radio)
+
+			b.setCompetitor(competitor)
+		}
+	}
+}
+
+func (b *builder) setEvent(event Event) {
+	if b.event == nil {
+		b.event = &models.Event{}
+	}
+	if event.Name != "" {
+		b.event.Name = event.Name
+	}
+	if len(event.Organisers) > 0 && event.Organisers[0].Name != "" {
+		b.event.Organizer = event.Organisers[0].Name
+	}
+	if event.StartTime != nil {
+		if start, ok := parseDate(*event.StartTime); ok {
+			b.event.Start = start
+		}
+	}
+}
+
+func (b *builder) class(class Class) models.Class {
+	id := toID("class", class.ID, class.Name)
+	if _, ok := b.classes[id]; !ok {
+		b.classOrder = append(b.classOrder, id)
+	}
+	result := models.Class{ID: id, Name: class.Name, OrderKey: len(b.classOrder) * 10}
+	if existing, ok := b.classes[id]; ok {
+		result.OrderKey = existing.OrderKey
+	}
+	b.classes[id] = result
+	return result
+}
+
+func (b *builder) club(organisation *Organisation) models.Club {
+	if organisation == nil || (organisation.ID == "" && organisation.Name == "") {
+		return models.Club{}
+	}
+	club := models.Club{
+		ID:   toID("organisation", organisation.ID, organisation.Name),
+		Name: organisation.Name,
+	}
+	if organisation.Country != nil {
+		club.CountryCode = organisation.Country.Code
+	}
+	b.clubs[club.ID] = club
+	return club
+}
+
+func (b *builder) control(code string) models.Control {
+	control := models.Control{ID: toID("control", code, code), Name: code}
+	b.controls[control.ID] = control
+	return control
+}
+
+// competitor returns the competitor already known for the person, or a new one
+func (b *builder) competitor(person Person, organisation *Organisation, class models.Class) models.Competitor {
+	name := strings.TrimSpace(person.Name.Given + " " + person.Name.Family)
+	id := toID("person", person.ID, class.Name+"/"+name)
+
+	competitor, ok := b.competitors[id]
+	if !ok {
+		competitor = models.Competitor{ID: id, Status: "0", Splits: []models.Split{}}
+	}
+	competitor.Name = name
+	competitor.Class = class
+	if club := b.club(organisation); club.ID != 0 || !ok {
+		competitor.Club = club
+	}
+	return competitor
+}
+
+func (b *builder) setCompetitor(competitor models.Competitor) {
+	if _, ok := b.competitors[competitor.ID]; !ok {
+		b.competitorID = append(b.competitorID, competitor.ID)
+	}
+	b.competitors[competitor.ID] = competitor
+}
+
+// build merges the documents, start lists before result lists so results win, and
+// returns the data with references resolved
+func (b *builder) build() (*models.Event, []models.Control, []models.Class, []models.Club, []models.Competitor) {
+	for _, list := range b.startLists {
+		b.addStartList(list)
+	}
+	for _, list := range b.resultLists {
+		b.addResultList(list)
+	}
+
+	controls := make([]models.Control, 0, len(b.controls))
+	for _, control := range b.controls {
+		controls = append(controls, control)
+	}
+	sort.Slice(controls, func(i, j int) bool { return controls[i].ID < controls[j].ID })
+
+	classes := make([]models.Class, 0, len(b.classOrder))
+	classByID := make(map[int]models.Class, len(b.classOrder))
+	for _, id := range b.classOrder {
+		class := b.classes[id]
+		class.RadioControls = []models.Control{}
+		for _, controlID := range b.classRadio[id] {
+			class.RadioControls = append(class.RadioControls, b.controls[controlID])
+		}
+		classes = append(classes, class)
+		classByID[id] = class
+	}
+
+	clubs := make([]models.Club, 0, len(b.clubs))
+	for _, club := range b.clubs {
+		clubs = append(clubs, club)
+	}
+	sort.Slice(clubs, func(i, j int) bool { return clubs[i].ID < clubs[j].ID })
+
+	competitors := make([]models.Competitor, 0, len(b.competitorID))
+	for _, id := range b.competitorID {
+		competitor := b.competitors[id]
+		competitor.Class = classByID[competitor.Class.ID]
+		competitors = append(competitors, competitor)
+	}
+
+	return b.event, controls, classes, clubs, competitors
+}
+
+// mergeControlOrder combines two observed control sequences of a class, keeping the
+// longer one in order and appending controls only seen in the other
+func mergeControlOrder(existing, observed []int) []int {
+	base, extra := existing, observed
+	if len(observed) > len(existing) {
+		base, extra = observed, existing
+	}
+
+	result := append([]int{}, base...)
+	for _, id := range extra {
+		found := false
+		for _, seen := range result {
+			if seen == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// meosStatus maps an IOF result status to the MeOS status code used in the models
+func meosStatus(status string) string {
+	switch status {
+	case StatusOK, StatusFinished:
+		return "1"
+	case StatusMissingPunch:
+		return "3"
+	case StatusDidNotFinish, StatusSportingWithdrawal:
+		return "4"
+	case StatusDisqualified:
+		return "5"
+	case StatusOverTime:
+		return "6"
+	case StatusDidNotStart:
+		return "20"
+	case StatusDidNotEnter, StatusCancelled:
+		return "21"
+	case StatusNotCompeting, StatusMoved, StatusMovedUp:
+		return "99"
+	default:
+		return "0"
+	}
+}
+
+// toID returns the numeric IOF ID, or a stable ID derived from the fallback key when the
+// document has no numeric ID
+func toID(kind, id, fallback string) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(id)); err == nil && n > 0 {
+		return n
+	}
+	key := id
+	if key == "" {
+		key = fallback
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(kind + ":" + key))
+	// Keep derived IDs positive and clear of typical numeric IDs
+	return int(h.Sum32()&0x3fffffff) + 1<<30
+}
+
+func parseInt(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func seconds(s Seconds) time.Duration {
+	return time.Duration(math.Round(float64(s) * float64(time.Second)))
+}
+
+func parseDateTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseDate(d DateAndOptionalTime) (time.Time, bool) {
+	if d.Time == "" {
+		t, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(d.Date), time.Local)
+		return t, err == nil
+	}
+	return parseDateTime(strings.TrimSpace(d.Date) + "T" + strings.TrimSpace(d.Time))
+}
@@ -0,0 +1,144 @@
+package iof
+
+import (
+	"testing"
+	"time"
+
+	"meos-graphics/internal/testhelpers"
+)
+
+func TestBuilder_StartList(t *testing.T) {
+	b := newBuilder()
+	if handled, err := b.add([]byte(testhelpers.IOFStartListXML())); err != nil || !handled {
+		t.Fatalf("add() = %v, %v, want true, nil", handled, err)
+	}
+
+	event, _, classes, clubs, competitors := b.build()
+
+	if event == nil || event.Name != "IOF Competition" || event.Organizer != "IOF Organizer" {
+		t.Fatalf("Unexpected event: %+v", event)
+	}
+	zone := time.FixedZone("", 3600)
+	if want := time.Date(2024, 1, 1, 10, 0, 0, 0, zone); !event.Start.Equal(want) {
+		t.Errorf("Event start = %v, want %v", event.Start, want)
+	}
+
+	if len(classes) != 2 {
+		t.Fatalf("Got %d classes, want 2", len(classes))
+	}
+	if classes[0].ID != 1 || classes[0].Name != "H21" || classes[0].OrderKey >= classes[1].OrderKey {
+		t.Errorf("Classes should keep document order, got %+v", classes)
+	}
+	if classes[1].ID <= 0 {
+		t.Errorf("Class without Id should get a derived positive ID, got %d", classes[1].ID)
+	}
+
+	if len(clubs) != 2 || clubs[0].ID != 5 || clubs[0].CountryCode != "SWE" {
+		t.Errorf("Unexpected clubs: %+v", clubs)
+	}
+
+	if len(competitors) != 3 {
+		t.Fatalf("Got %d competitors, want 3", len(competitors))
+	}
+	john := competitors[0]
+	if john.ID != 11 || john.Name != "John Doe" || john.Bib != "101" || john.Card != 12345 || john.Status != "0" {
+		t.Errorf("Unexpected competitor: %+v", john)
+	}
+	if want := time.Date(2024, 1, 1, 10, 0, 0, 0, zone); !john.StartTime.Equal(want) {
+		t.Errorf("Start time = %v, want %v", john.StartTime, want)
+	}
+	if john.Club.Name != "Test Club 1" || john.Class.Name != "H21" {
+		t.Errorf("References not resolved: %+v", john)
+	}
+	if competitors[2].Club.ID != 0 {
+		t.Errorf("Competitor without organisation should have no club, got %+v", competitors[2].Club)
+	}
+}
+
+func TestBuilder_ResultListOverridesStartList(t *testing.T) {
+	b := newBuilder()
+	// Add the result list first; start lists are still applied before results
+	for _, doc := range []string{testhelpers.IOFResultListXML(), testhelpers.IOFStartListXML()} {
+		if _, err := b.add([]byte(doc)); err != nil {
+			t.Fatalf("add() error = %v", err)
+		}
+	}
+
+	_, controls, classes, _, competitors := b.build()
+
+	if len(controls) != 2 {
+		t.Errorf("Got %d controls, want 2 (additional punches are ignored)", len(controls))
+	}
+	if len(classes[0].RadioControls) != 2 || classes[0].RadioControls[0].Name != "31" || classes[0].RadioControls[1].Name != "32" {
+		t.Errorf("Unexpected radio controls: %+v", classes[0].RadioControls)
+	}
+
+	john := competitors[0]
+	if john.Status != "1" || john.FinishTime == nil {
+		t.Fatalf("John should be finished, got %+v", john)
+	}
+	if got := john.FinishTime.Sub(john.StartTime); got != 834500*time.Millisecond {
+		t.Errorf("Running time = %v, want 13m54.5s", got)
+	}
+	if john.Bib != "101" || john.Card != 12345 {
+		t.Errorf("Start list data should be kept, got bib %q card %d", john.Bib, john.Card)
+	}
+	if len(john.Splits) != 2 || john.Splits[1].PassingTime.Sub(john.StartTime) != 584*time.Second {
+		t.Errorf("Unexpected splits: %+v", john.Splits)
+	}
+
+	jane := competitors[1]
+	if jane.Status != "3" || jane.FinishTime != nil {
+		t.Errorf("Jane should have a missing punch and no finish time, got %+v", jane)
+	}
+	if len(jane.Splits) != 1 || jane.Splits[0].Control.Name != "32" {
+		t.Errorf("Missing split should be skipped, got %+v", jane.Splits)
+	}
+}
+
+func TestBuilder_IgnoresOtherDocuments(t *testing.T) {
+	b := newBuilder()
+
+	handled, err := b.add([]byte(`<EntryList xmlns="http://www.orienteering.org/datastandard/3.0"/>`))
+	if err != nil || handled {
+		t.Errorf("add(EntryList) = %v, %v, want false, nil", handled, err)
+	}
+	if _, err := b.add([]byte(testhelpers.InvalidXML())); err == nil {
+		t.Error("add() should fail for invalid XML")
+	}
+}
+
+func TestMeosStatus(t *testing.T) {
+	tests := map[string]string{
+		StatusOK:           "1",
+		StatusFinished:     "1",
+		StatusMissingPunch: "3",
+		StatusDidNotFinish: "4",
+		StatusDisqualified: "5",
+		StatusOverTime:     "6",
+		StatusDidNotStart:  "20",
+		StatusCancelled:    "21",
+		StatusNotCompeting: "99",
+		StatusActive:       "0",
+		"":                 "0",
+	}
+
+	for status, want := range tests {
+		if got := meosStatus(status); got != want {
+			t.Errorf("meosStatus(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestToID(t *testing.T) {
+	if got := toID("class", "42", "H21"); got != 42 {
+		t.Errorf("toID with numeric ID = %d, want 42", got)
+	}
+	derived := toID("class", "", "H21")
+	if derived <= 0 || derived != toID("class", "", "H21") {
+		t.Errorf("Derived ID should be positive and stable, got %d", derived)
+	}
+	if derived == toID("organisation", "", "H21") {
+		t.Error("Derived IDs should differ between entity kinds")
+	}
+}
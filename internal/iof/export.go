@@ -0,0 +1,374 @@
+package iof
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/version"
+)
+
+// ResultList statuses
+const (
+	ListStatusComplete = "Complete"
+	ListStatusSnapshot = "Snapshot"
+)
+
+// ExportEntry is a competitor with their place in the results
+type ExportEntry struct {
+	Competitor  models.Competitor
+	Position    int           // 0 when not ranked
+	RunningTime time.Duration // 0 when not finished
+	TimeBehind  time.Duration // Time behind the class winner
+}
+
+// ExportTeam is a relay team with its runners and place in the results
+type ExportTeam struct {
+	Team        models.Team
+	StartTime   time.Time      // Team start, or the first leg's start when the team has none
+	Runners     []ExportRunner // In leg order
+	Position    int            // 0 when not ranked
+	RunningTime time.Duration  // 0 when not finished
+	TimeBehind  time.Duration  // Time behind the winning team
+}
+
+// ExportRunner is a member of a relay team running one leg
+type ExportRunner struct {
+	Competitor models.Competitor
+	Leg        int
+	LegOrder   int // Order among the runners of a parallel leg, 0 on legs with one runner
+}
+
+// ExportClass is a class with its competitors and relay teams in result order
+type ExportClass struct {
+	Class   models.Class
+	Entries []ExportEntry // Competitors who do not run for a team
+	Teams   []ExportTeam
+}
+
+// ExportData is the competition data a result or start list is written from
+type ExportData struct {
+	Event   models.Event
+	Classes []ExportClass
+}
+
+// NewResultList builds an IOF XML 3.0 ResultList from the export data. Competitors and
+// teams that have not reached a final status are included as Active or Inactive and make
+// the list a snapshot.
+func NewResultList(data ExportData, createTime time.Time) ResultList {
+	list := ResultList{
+		XMLNS:        Namespace,
+		IOFVersion:   Version,
+		CreateTime:   formatDateTime(createTime),
+		Creator:      creator(),
+		Status:       ListStatusComplete,
+		Event:        exportEvent(data.Event),
+		ClassResults: []ClassResult{},
+	}
+
+	for _, class := range data.Classes {
+		classResult := ClassResult{Class: exportClass(class.Class), PersonResults: []PersonResult{}}
+		for _, entry := range class.Entries {
+			comp := entry.Competitor
+			result := PersonRaceResult{
+				BibNumber:   comp.Bib,
+				StartTime:   formatDateTime(comp.StartTime),
+				Position:    entry.Position,
+				Status:      iofStatus(comp.Status, comp.StartTime, comp.FinishTime, createTime),
+				ControlCard: formatCard(comp.Card),
+			}
+			if comp.FinishTime != nil {
+				result.FinishTime = formatDateTime(*comp.FinishTime)
+				result.Time = toSeconds(comp.FinishTime.Sub(comp.StartTime))
+			}
+			if entry.Position > 0 {
+				result.TimeBehind = toSeconds(entry.TimeBehind)
+			}
+			result.SplitTimes = exportSplits(class.Class, comp, final(result.Status))
+
+			if !final(result.Status) {
+				list.Status = ListStatusSnapshot
+			}
+
+			classResult.PersonResults = append(classResult.PersonResults, PersonResult{
+				Person:       exportPerson(comp),
+				Organisation: exportOrganisation(comp.Club),
+				Results:      []PersonRaceResult{result},
+			})
+		}
+
+		for _, team := range class.Teams {
+			teamResult := exportTeamResult(class.Class, team, createTime)
+			for _, member := range teamResult.Members {
+				result := member.Results[0]
+				if !final(result.Status) || (result.OverallResult != nil && !final(result.OverallResult.Status)) {
+					list.Status = ListStatusSnapshot
+				}
+			}
+			classResult.TeamResults = append(classResult.TeamResults, teamResult)
+		}
+		list.ClassResults = append(list.ClassResults, classResult)
+	}
+
+	return list
+}
+
+// exportTeamResult builds a relay team's result. Each runner has their own leg result, and
+// the runners of the last leg carry the team's overall result.
+func exportTeamResult(class models.Class, team ExportTeam, now time.Time) TeamResult {
+	result := TeamResult{
+		Name:          team.Team.Name,
+		Organisations: exportOrganisations(team.Team.Club),
+		BibNumber:     team.Team.Bib,
+		Members:       []TeamMemberResult{},
+	}
+
+	overall := &OverallResult{Status: iofStatus(team.Team.Status, team.StartTime, team.Team.FinishTime, now)}
+	if team.Team.FinishTime != nil && !team.StartTime.IsZero() {
+		overall.Time = toSeconds(team.Team.FinishTime.Sub(team.StartTime))
+	}
+	if team.Position > 0 {
+		overall.Position = team.Position
+		overall.TimeBehind = toSeconds(team.TimeBehind)
+	}
+
+	for _, runner := range team.Runners {
+		comp := runner.Competitor
+		legResult := TeamMemberRaceResult{
+			Leg:         runner.Leg,
+			LegOrder:    runner.LegOrder,
+			BibNumber:   comp.Bib,
+			StartTime:   formatDateTime(comp.StartTime),
+			Status:      iofStatus(comp.Status, comp.StartTime, comp.FinishTime, now),
+			ControlCard: formatCard(comp.Card),
+		}
+		if comp.FinishTime != nil {
+			legResult.FinishTime = formatDateTime(*comp.FinishTime)
+			legResult.Time = toSeconds(comp.FinishTime.Sub(comp.StartTime))
+		}
+		if runner.Leg == lastLeg(team.Team) {
+			legResult.OverallResult = overall
+		}
+		// The class has the radio controls of all legs together, so controls a runner has
+		// not punched may belong to another leg and are not reported as missing
+		legResult.SplitTimes = exportSplits(class, comp, false)
+
+		result.Members = append(result.Members, TeamMemberResult{
+			Person:       exportPerson(comp),
+			Organisation: exportOrganisation(comp.Club),
+			Results:      []TeamMemberRaceResult{legResult},
+		})
+	}
+	return result
+}
+
+// NewStartList builds an IOF XML 3.0 StartList from the export data, ordered by start time
+func NewStartList(data ExportData, createTime time.Time) StartList {
+	list := StartList{
+		XMLNS:       Namespace,
+		IOFVersion:  Version,
+		CreateTime:  formatDateTime(createTime),
+		Creator:     creator(),
+		Event:       exportEvent(data.Event),
+		ClassStarts: []ClassStart{},
+	}
+
+	for _, class := range data.Classes {
+		entries := append([]ExportEntry{}, class.Entries...)
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Competitor.StartTime.Before(entries[j].Competitor.StartTime)
+		})
+
+		classStart := ClassStart{Class: exportClass(class.Class), PersonStarts: []PersonStart{}}
+		for _, entry := range entries {
+			comp := entry.Competitor
+			classStart.PersonStarts = append(classStart.PersonStarts, PersonStart{
+				Person:       exportPerson(comp),
+				Organisation: exportOrganisation(comp.Club),
+				Starts: []PersonRaceStart{{
+					BibNumber:   comp.Bib,
+					StartTime:   formatDateTime(comp.StartTime),
+					ControlCard: formatCard(comp.Card),
+				}},
+			})
+		}
+
+		teams := append([]ExportTeam{}, class.Teams...)
+		sort.SliceStable(teams, func(i, j int) bool {
+			return teams[i].StartTime.Before(teams[j].StartTime)
+		})
+		for _, team := range teams {
+			teamStart := TeamStart{
+				Name:          team.Team.Name,
+				Organisations: exportOrganisations(team.Team.Club),
+				BibNumber:     team.Team.Bib,
+				Members:       []TeamMemberStart{},
+			}
+			for _, runner := range team.Runners {
+				comp := runner.Competitor
+				teamStart.Members = append(teamStart.Members, TeamMemberStart{
+					Person:       exportPerson(comp),
+					Organisation: exportOrganisation(comp.Club),
+					Starts: []TeamMemberRaceStart{{
+						Leg:         runner.Leg,
+						LegOrder:    runner.LegOrder,
+						BibNumber:   comp.Bib,
+						StartTime:   formatDateTime(comp.StartTime),
+						ControlCard: formatCard(comp.Card),
+					}},
+				})
+			}
+			classStart.TeamStarts = append(classStart.TeamStarts, teamStart)
+		}
+		list.ClassStarts = append(list.ClassStarts, classStart)
+	}
+
+	return list
+}
+
+// lastLeg returns the number of the team's last leg
+func lastLeg(team models.Team) int {
+	last := 0
+	for _, leg := range team.Legs {
+		last = max(last, leg.Number)
+	}
+	return last
+}
+
+// final reports whether an IOF result status will not change any more
+func final(status string) bool {
+	return status != StatusActive && status != StatusInactive
+}
+
+// exportSplits returns the competitor's times at the class radio controls in course order.
+// Controls without a punch are left out, or reported as missing when reportMissing is set.
+func exportSplits(class models.Class, comp models.Competitor, reportMissing bool) []SplitTime {
+
+	var splits []SplitTime
+	for _, control := range class.RadioControls {
+		split := SplitTime{ControlCode: strconv.Itoa(control.ID)}
+		found := false
+		for _, s := range comp.Splits {
+			if s.Control.ID == control.ID {
+				split.Time = toSeconds(s.PassingTime.Sub(comp.StartTime))
+				found = true
+				break
+			}
+		}
+		if !found {
+			if !reportMissing {
+				continue
+			}
+			split.Status = SplitStatusMissing
+		}
+		splits = append(splits, split)
+	}
+	return splits
+}
+
+func exportEvent(event models.Event) Event {
+	result := Event{Name: event.Name}
+	if !event.Start.IsZero() {
+		result.StartTime = &DateAndOptionalTime{
+			Date: event.Start.Format("2006-01-02"),
+			Time: event.Start.Format("15:04:05Z07:00"),
+		}
+	}
+	if event.Organizer != "" {
+		result.Organisers = []Organisation{{Name: event.Organizer}}
+	}
+	return result
+}
+
+func exportClass(class models.Class) Class {
+	return Class{ID: strconv.Itoa(class.ID), Name: class.Name}
+}
+
+func exportPerson(comp models.Competitor) Person {
+	return Person{ID: strconv.Itoa(comp.ID), Name: splitName(comp.Name)}
+}
+
+// exportOrganisations returns the club as a list of organisations, empty without a club
+func exportOrganisations(club models.Club) []Organisation {
+	if organisation := exportOrganisation(club); organisation != nil {
+		return []Organisation{*organisation}
+	}
+	return nil
+}
+
+func exportOrganisation(club models.Club) *Organisation {
+	if club.ID == 0 && club.Name == "" {
+		return nil
+	}
+	organisation := &Organisation{ID: strconv.Itoa(club.ID), Name: club.Name}
+	if club.ID == 0 {
+		organisation.ID = ""
+	}
+	if club.CountryCode != "" {
+		organisation.Country = &Country{Code: club.CountryCode}
+	}
+	return organisation
+}
+
+// splitName splits a full name into given and family names at the last space
+func splitName(name string) PersonName {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndex(name, " "); i > 0 {
+		return PersonName{Given: strings.TrimSpace(name[:i]), Family: name[i+1:]}
+	}
+	return PersonName{Family: name}
+}
+
+// iofStatus maps the MeOS status code of a competitor or team to an IOF result status
+func iofStatus(status string, start time.Time, finish *time.Time, now time.Time) string {
+	switch status {
+	case "1":
+		if finish != nil {
+			return StatusOK
+		}
+	case "3":
+		return StatusMissingPunch
+	case "4":
+		return StatusDidNotFinish
+	case "5":
+		return StatusDisqualified
+	case "6":
+		return StatusOverTime
+	case "20":
+		return StatusDidNotStart
+	case "21":
+		return StatusCancelled
+	case "99":
+		return StatusNotCompeting
+	}
+
+	if !start.IsZero() && !now.Before(start) {
+		return StatusActive
+	}
+	return StatusInactive
+}
+
+func formatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatCard(card int) string {
+	if card == 0 {
+		return ""
+	}
+	return strconv.Itoa(card)
+}
+
+func toSeconds(d time.Duration) *Seconds {
+	s := Seconds(d.Seconds())
+	return &s
+}
+
+func creator() string {
+	return "meos-graphics " + version.Version
+}
@@ -0,0 +1,259 @@
+package iof
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/testhelpers"
+)
+
+func setupExportData() ExportData {
+	radio1 := testhelpers.CreateTestControl(31, "Radio 1")
+	radio2 := testhelpers.CreateTestControl(32, "Radio 2")
+	class := testhelpers.CreateTestClass(1, "Men Elite", 10, radio1, radio2)
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+
+	winner := testhelpers.CreateFinishedCompetitor(1, "John Doe", club, class, 8345)
+	winner.Bib = "101"
+	winner.Splits = []models.Split{
+		testhelpers.CreateTestSplit(radio1, 3020, winner.StartTime),
+		testhelpers.CreateTestSplit(radio2, 5840, winner.StartTime),
+	}
+	mispunch := testhelpers.CreateTestCompetitor(2, "Anna Maria Svensson", club, class)
+	mispunch.Status = "3"
+	mispunch.Splits = []models.Split{testhelpers.CreateTestSplit(radio2, 6120, mispunch.StartTime)}
+	running := testhelpers.CreateTestCompetitor(3, "Mike Johnson", club, class)
+	running.Splits = []models.Split{testhelpers.CreateTestSplit(radio1, 3500, running.StartTime)}
+
+	return ExportData{
+		Event: *testhelpers.CreateTestEvent(),
+		Classes: []ExportClass{{
+			Class: class,
+			Entries: []ExportEntry{
+				{Competitor: winner, Position: 1, RunningTime: winner.FinishTime.Sub(winner.StartTime)},
+				{Competitor: running},
+				{Competitor: mispunch},
+			},
+		}},
+	}
+}
+
+func TestNewResultList(t *testing.T) {
+	data := setupExportData()
+	// The running competitor started at 11:00
+	now := time.Date(2024, 1, 1, 11, 30, 0, 0, time.UTC)
+
+	list := NewResultList(data, now)
+
+	if list.Status != ListStatusSnapshot {
+		t.Errorf("List status = %s, want %s while a competitor is running", list.Status, ListStatusSnapshot)
+	}
+	if list.Event.Name != "Test Competition" || list.Event.StartTime == nil || list.Event.StartTime.Date != "2024-01-01" {
+		t.Errorf("Unexpected event: %+v", list.Event)
+	}
+
+	results := list.ClassResults[0].PersonResults
+	if len(results) != 3 {
+		t.Fatalf("Got %d results, want 3", len(results))
+	}
+
+	winner := results[0].Results[0]
+	if winner.Status != StatusOK || winner.Position != 1 || *winner.Time != 834.5 || *winner.TimeBehind != 0 {
+		t.Errorf("Unexpected winner result: %+v", winner)
+	}
+	if winner.BibNumber != "101" || winner.ControlCard != "100" {
+		t.Errorf("Winner bib/card = %q/%q, want 101/100", winner.BibNumber, winner.ControlCard)
+	}
+
+	mispunch := results[2]
+	if mispunch.Person.Name.Given != "Anna Maria" || mispunch.Person.Name.Family != "Svensson" {
+		t.Errorf("Name split = %+v", mispunch.Person.Name)
+	}
+	if mispunch.Results[0].Status != StatusMissingPunch || mispunch.Results[0].Position != 0 {
+		t.Errorf("Unexpected mispunch result: %+v", mispunch.Results[0])
+	}
+	// Final results report unpunched controls as missing
+	if splits := mispunch.Results[0].SplitTimes; len(splits) != 2 || splits[0].Status != SplitStatusMissing || splits[0].Time != nil {
+		t.Errorf("Unexpected mispunch splits: %+v", splits)
+	}
+
+	running := results[1].Results[0]
+	if running.Status != StatusActive || running.FinishTime != "" {
+		t.Errorf("Unexpected running result: %+v", running)
+	}
+	// Controls the runner hasn't reached yet are left out
+	if len(running.SplitTimes) != 1 || running.SplitTimes[0].ControlCode != "31" {
+		t.Errorf("Unexpected running splits: %+v", running.SplitTimes)
+	}
+}
+
+func TestNewStartList(t *testing.T) {
+	list := NewStartList(setupExportData(), time.Now())
+
+	if len(list.ClassStarts) != 1 || len(list.ClassStarts[0].PersonStarts) != 3 {
+		t.Fatalf("Unexpected start list: %+v", list.ClassStarts)
+	}
+	start := list.ClassStarts[0].PersonStarts[0]
+	if start.Organisation == nil || start.Organisation.Country == nil || start.Organisation.Country.Code != "SWE" {
+		t.Errorf("Unexpected organisation: %+v", start.Organisation)
+	}
+	if start.Starts[0].StartTime != "2024-01-01T11:00:00Z" {
+		t.Errorf("Start time = %q, want 2024-01-01T11:00:00Z", start.Starts[0].StartTime)
+	}
+}
+
+func setupRelayExportData() ExportData {
+	radio := testhelpers.CreateTestControl(31, "Radio 1")
+	class := testhelpers.CreateTestClass(3, "Relay", 30, radio)
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	start := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	first := testhelpers.CreateFinishedCompetitor(11, "Anna Berg", club, class, 36000)
+	first.Splits = []models.Split{testhelpers.CreateTestSplit(radio, 18000, first.StartTime)}
+	// The second leg is run by two runners in parallel
+	second := testhelpers.CreateFinishedCompetitor(12, "Bo Ek", club, class, 30000)
+	second.StartTime = *first.FinishTime
+	secondFinish := second.StartTime.Add(50 * time.Minute)
+	second.FinishTime = &secondFinish
+	third := testhelpers.CreateFinishedCompetitor(13, "Cecilia Lind", club, class, 30000)
+	third.StartTime = *first.FinishTime
+	third.FinishTime = &secondFinish
+
+	finish := secondFinish
+	team := models.Team{
+		ID: 1, Name: "Test Club 1", Bib: "1", Club: club, Class: class, Status: "1",
+		StartTime: start, FinishTime: &finish,
+		Legs: []models.Leg{{Number: 1, CompetitorIDs: []int{11}}, {Number: 2, CompetitorIDs: []int{12, 13}}},
+	}
+	running := testhelpers.CreateTestCompetitor(21, "Dan Ros", club, class)
+	runningTeam := models.Team{
+		ID: 2, Name: "Test Club 2", Bib: "2", Club: club, Class: class, Status: "0", StartTime: start,
+		Legs: []models.Leg{{Number: 1, CompetitorIDs: []int{21}}, {Number: 2}},
+	}
+
+	return ExportData{
+		Event: *testhelpers.CreateTestEvent(),
+		Classes: []ExportClass{{
+			Class: class,
+			Teams: []ExportTeam{
+				{
+					Team: team, StartTime: start, Position: 1, RunningTime: finish.Sub(start),
+					Runners: []ExportRunner{
+						{Competitor: first, Leg: 1},
+						{Competitor: second, Leg: 2, LegOrder: 1},
+						{Competitor: third, Leg: 2, LegOrder: 2},
+					},
+				},
+				{Team: runningTeam, StartTime: start, Runners: []ExportRunner{{Competitor: running, Leg: 1}}},
+			},
+		}},
+	}
+}
+
+func TestNewResultList_Relay(t *testing.T) {
+	list := NewResultList(setupRelayExportData(), time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	if list.Status != ListStatusSnapshot {
+		t.Errorf("List status = %s, want %s while a team is running", list.Status, ListStatusSnapshot)
+	}
+	class := list.ClassResults[0]
+	if len(class.PersonResults) != 0 || len(class.TeamResults) != 2 {
+		t.Fatalf("Expected 2 team results and no person results, got %+v", class)
+	}
+
+	team := class.TeamResults[0]
+	if team.Name != "Test Club 1" || team.BibNumber != "1" || len(team.Organisations) != 1 || len(team.Members) != 3 {
+		t.Fatalf("Unexpected team result: %+v", team)
+	}
+	first := team.Members[0].Results[0]
+	if first.Leg != 1 || first.LegOrder != 0 || first.Status != StatusOK || *first.Time != 3600 || first.OverallResult != nil {
+		t.Errorf("Unexpected first leg result: %+v", first)
+	}
+	if len(first.SplitTimes) != 1 || *first.SplitTimes[0].Time != 1800 {
+		t.Errorf("Unexpected first leg splits: %+v", first.SplitTimes)
+	}
+
+	// The runners of the last leg carry the team's result
+	for i, member := range team.Members[1:] {
+		result := member.Results[0]
+		if result.Leg != 2 || result.LegOrder != i+1 || *result.Time != 3000 {
+			t.Errorf("Unexpected parallel leg result: %+v", result)
+		}
+		overall := result.OverallResult
+		if overall == nil || overall.Status != StatusOK || overall.Position != 1 || *overall.Time != 6600 || *overall.TimeBehind != 0 {
+			t.Errorf("Unexpected overall result: %+v", overall)
+		}
+	}
+
+	running := class.TeamResults[1]
+	if len(running.Members) != 1 || running.Members[0].Results[0].Status != StatusActive {
+		t.Errorf("Unexpected running team: %+v", running)
+	}
+
+	output, err := xml.Marshal(list)
+	if err != nil {
+		t.Fatalf("Failed to marshal result list: %v", err)
+	}
+	for _, element := range []string{"<TeamResult><Name>Test Club 1</Name>", "<TeamMemberResult>", "<Leg>2</Leg><LegOrder>2</LegOrder>", "<OverallResult>"} {
+		if !strings.Contains(string(output), element) {
+			t.Errorf("Result list has no %s", element)
+		}
+	}
+}
+
+func TestNewStartList_Relay(t *testing.T) {
+	list := NewStartList(setupRelayExportData(), time.Now())
+
+	class := list.ClassStarts[0]
+	if len(class.PersonStarts) != 0 || len(class.TeamStarts) != 2 {
+		t.Fatalf("Expected 2 team starts and no person starts, got %+v", class)
+	}
+	team := class.TeamStarts[0]
+	if team.Name != "Test Club 1" || len(team.Members) != 3 {
+		t.Fatalf("Unexpected team start: %+v", team)
+	}
+	if start := team.Members[2].Starts[0]; start.Leg != 2 || start.LegOrder != 2 || start.StartTime != "2024-01-01T12:00:00Z" {
+		t.Errorf("Unexpected member start: %+v", start)
+	}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	data := setupExportData()
+
+	output, err := xml.Marshal(NewResultList(data, time.Now()))
+	if err != nil {
+		t.Fatalf("Failed to marshal result list: %v", err)
+	}
+
+	b := newBuilder()
+	if _, err := b.add(output); err != nil {
+		t.Fatalf("Failed to import exported result list: %v", err)
+	}
+	_, _, classes, _, competitors := b.build()
+
+	if len(classes) != 1 || classes[0].ID != 1 || len(classes[0].RadioControls) != 2 {
+		t.Errorf("Unexpected classes after round trip: %+v", classes)
+	}
+	if len(competitors) != 3 {
+		t.Fatalf("Got %d competitors after round trip, want 3", len(competitors))
+	}
+
+	winner := competitors[0]
+	original := data.Classes[0].Entries[0].Competitor
+	if winner.ID != original.ID || winner.Name != original.Name || winner.Status != "1" {
+		t.Errorf("Winner after round trip = %+v", winner)
+	}
+	if !winner.FinishTime.Equal(*original.FinishTime) || len(winner.Splits) != 2 || !winner.Splits[1].PassingTime.Equal(original.Splits[1].PassingTime) {
+		t.Error("Times changed in round trip")
+	}
+}
+
+func TestSecondsMarshal(t *testing.T) {
+	text, _ := Seconds(1000000.5).MarshalText()
+	if string(text) != "1000000.5" {
+		t.Errorf("MarshalText() = %s, want 1000000.5", text)
+	}
+}
@@ -0,0 +1,242 @@
+package iof
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// Namespace is the XML namespace of IOF XML 3.0 documents
+const Namespace = "http://www.orienteering.org/datastandard/3.0"
+
+// Version is the value of the iofVersion attribute
+const Version = "3.0"
+
+// IOF XML 3.0 result statuses
+const (
+	StatusOK                 = "OK"
+	StatusFinished           = "Finished"
+	StatusMissingPunch       = "MissingPunch"
+	StatusDisqualified       = "Disqualified"
+	StatusDidNotFinish       = "DidNotFinish"
+	StatusActive             = "Active"
+	StatusInactive           = "Inactive"
+	StatusOverTime           = "OverTime"
+	StatusSportingWithdrawal = "SportingWithdrawal"
+	StatusNotCompeting       = "NotCompeting"
+	StatusMoved              = "Moved"
+	StatusMovedUp            = "MovedUp"
+	StatusDidNotStart        = "DidNotStart"
+	StatusDidNotEnter        = "DidNotEnter"
+	StatusCancelled          = "Cancelled"
+)
+
+// Split time statuses for controls that were not punched or are not part of the course
+const (
+	SplitStatusMissing    = "Missing"
+	SplitStatusAdditional = "Additional"
+)
+
+// Seconds is a duration in seconds, written without exponent notation
+type Seconds float64
+
+// MarshalText implements encoding.TextMarshaler
+func (s Seconds) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(s), 'f', -1, 64)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Seconds) UnmarshalText(text []byte) error {
+	v, err := strconv.ParseFloat(strings.TrimSpace(string(text)), 64)
+	if err != nil {
+		return err
+	}
+	*s = Seconds(v)
+	return nil
+}
+
+// ResultList is an IOF XML 3.0 result list document
+type ResultList struct {
+	XMLName      xml.Name      `xml:"ResultList"`
+	XMLNS        string        `xml:"xmlns,attr,omitempty"`
+	IOFVersion   string        `xml:"iofVersion,attr,omitempty"`
+	CreateTime   string        `xml:"createTime,attr,omitempty"`
+	Creator      string        `xml:"creator,attr,omitempty"`
+	Status       string        `xml:"status,attr,omitempty"`
+	Event        Event         `xml:"Event"`
+	ClassResults []ClassResult `xml:"ClassResult"`
+}
+
+// StartList is an IOF XML 3.0 start list document
+type StartList struct {
+	XMLName     xml.Name     `xml:"StartList"`
+	XMLNS       string       `xml:"xmlns,attr,omitempty"`
+	IOFVersion  string       `xml:"iofVersion,attr,omitempty"`
+	CreateTime  string       `xml:"createTime,attr,omitempty"`
+	Creator     string       `xml:"creator,attr,omitempty"`
+	Event       Event        `xml:"Event"`
+	ClassStarts []ClassStart `xml:"ClassStart"`
+}
+
+// Event describes the competition
+type Event struct {
+	ID         string               `xml:"Id,omitempty"`
+	Name       string               `xml:"Name"`
+	StartTime  *DateAndOptionalTime `xml:"StartTime,omitempty"`
+	Organisers []Organisation       `xml:"Organiser,omitempty"`
+}
+
+// DateAndOptionalTime is a date with an optional time of day
+type DateAndOptionalTime struct {
+	Date string `xml:"Date"`
+	Time string `xml:"Time,omitempty"`
+}
+
+// Class is a competition class
+type Class struct {
+	ID        string `xml:"Id,omitempty"`
+	Name      string `xml:"Name"`
+	ShortName string `xml:"ShortName,omitempty"`
+}
+
+// Organisation is a club or other organisation
+type Organisation struct {
+	ID        string   `xml:"Id,omitempty"`
+	Name      string   `xml:"Name"`
+	ShortName string   `xml:"ShortName,omitempty"`
+	Country   *Country `xml:"Country,omitempty"`
+}
+
+// Country of an organisation
+type Country struct {
+	Code string `xml:"code,attr"`
+	Name string `xml:",chardata"`
+}
+
+// Person is a competitor
+type Person struct {
+	ID   string     `xml:"Id,omitempty"`
+	Name PersonName `xml:"Name"`
+}
+
+// PersonName holds the family and given names of a person
+type PersonName struct {
+	Family string `xml:"Family"`
+	Given  string `xml:"Given"`
+}
+
+// ClassResult holds the results of a class. Relay classes have team results.
+type ClassResult struct {
+	Class         Class          `xml:"Class"`
+	PersonResults []PersonResult `xml:"PersonResult"`
+	TeamResults   []TeamResult   `xml:"TeamResult"`
+}
+
+// PersonResult is a competitor's result
+type PersonResult struct {
+	Person       Person             `xml:"Person"`
+	Organisation *Organisation      `xml:"Organisation,omitempty"`
+	Results      []PersonRaceResult `xml:"Result"`
+}
+
+// PersonRaceResult is a competitor's result in a single race
+type PersonRaceResult struct {
+	BibNumber   string      `xml:"BibNumber,omitempty"`
+	StartTime   string      `xml:"StartTime,omitempty"`
+	FinishTime  string      `xml:"FinishTime,omitempty"`
+	Time        *Seconds    `xml:"Time,omitempty"`
+	TimeBehind  *Seconds    `xml:"TimeBehind,omitempty"`
+	Position    int         `xml:"Position,omitempty"`
+	Status      string      `xml:"Status"`
+	SplitTimes  []SplitTime `xml:"SplitTime"`
+	ControlCard string      `xml:"ControlCard,omitempty"`
+}
+
+// TeamResult is a relay team's result
+type TeamResult struct {
+	Name          string             `xml:"Name,omitempty"`
+	Organisations []Organisation     `xml:"Organisation"`
+	BibNumber     string             `xml:"BibNumber,omitempty"`
+	Members       []TeamMemberResult `xml:"TeamMemberResult"`
+}
+
+// TeamMemberResult is the result of a relay team member
+type TeamMemberResult struct {
+	Person       Person                 `xml:"Person"`
+	Organisation *Organisation          `xml:"Organisation,omitempty"`
+	Results      []TeamMemberRaceResult `xml:"Result"`
+}
+
+// TeamMemberRaceResult is a relay team member's result on a leg
+type TeamMemberRaceResult struct {
+	Leg           int            `xml:"Leg"`
+	LegOrder      int            `xml:"LegOrder,omitempty"`
+	BibNumber     string         `xml:"BibNumber,omitempty"`
+	StartTime     string         `xml:"StartTime,omitempty"`
+	FinishTime    string         `xml:"FinishTime,omitempty"`
+	Time          *Seconds       `xml:"Time,omitempty"`
+	Status        string         `xml:"Status"`
+	OverallResult *OverallResult `xml:"OverallResult,omitempty"`
+	SplitTimes    []SplitTime    `xml:"SplitTime"`
+	ControlCard   string         `xml:"ControlCard,omitempty"`
+}
+
+// OverallResult is the team's result at the end of a leg
+type OverallResult struct {
+	Time       *Seconds `xml:"Time,omitempty"`
+	TimeBehind *Seconds `xml:"TimeBehind,omitempty"`
+	Position   int      `xml:"Position,omitempty"`
+	Status     string   `xml:"Status"`
+}
+
+// SplitTime is the time from start to a control
+type SplitTime struct {
+	Status      string   `xml:"status,attr,omitempty"`
+	ControlCode string   `xml:"ControlCode"`
+	Time        *Seconds `xml:"Time,omitempty"`
+}
+
+// ClassStart holds the start list of a class. Relay classes have team starts.
+type ClassStart struct {
+	Class        Class         `xml:"Class"`
+	PersonStarts []PersonStart `xml:"PersonStart"`
+	TeamStarts   []TeamStart   `xml:"TeamStart"`
+}
+
+// PersonStart is a competitor's start
+type PersonStart struct {
+	Person       Person            `xml:"Person"`
+	Organisation *Organisation     `xml:"Organisation,omitempty"`
+	Starts       []PersonRaceStart `xml:"Start"`
+}
+
+// PersonRaceStart is a competitor's start in a single race
+type PersonRaceStart struct {
+	BibNumber   string `xml:"BibNumber,omitempty"`
+	StartTime   string `xml:"StartTime,omitempty"`
+	ControlCard string `xml:"ControlCard,omitempty"`
+}
+
+// TeamStart is a relay team's start
+type TeamStart struct {
+	Name          string            `xml:"Name,omitempty"`
+	Organisations []Organisation    `xml:"Organisation"`
+	BibNumber     string            `xml:"BibNumber,omitempty"`
+	Members       []TeamMemberStart `xml:"TeamMemberStart"`
+}
+
+// TeamMemberStart is the start of a relay team member
+type TeamMemberStart struct {
+	Person       Person                `xml:"Person"`
+	Organisation *Organisation         `xml:"Organisation,omitempty"`
+	Starts       []TeamMemberRaceStart `xml:"Start"`
+}
+
+// TeamMemberRaceStart is a relay team member's start on a leg
+type TeamMemberRaceStart struct {
+	Leg         int    `xml:"Leg"`
+	LegOrder    int    `xml:"LegOrder,omitempty"`
+	BibNumber   string `xml:"BibNumber,omitempty"`
+	StartTime   string `xml:"StartTime,omitempty"`
+	ControlCard string `xml:"ControlCard,omitempty"`
+}
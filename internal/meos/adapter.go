@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -28,9 +29,24 @@ type Adapter struct {
 	config            *Config
 	state             *state.State
 	connected         bool
+	polling           bool
 	mu                sync.RWMutex
 	stopChan          chan struct{}
+	done              chan struct{} // Closed when the polling goroutine has exited
 	currentDifference string
+	// appliedDifference is the difference key of the last response whose data is in the
+	// state. It lags behind currentDifference while a response is being processed.
+	appliedDifference string
+
+	// Connection health, guarded by mu
+	lastSuccess     time.Time
+	lastAttempt     time.Time
+	nextAttempt     time.Time
+	failures        int
+	lastError       string
+	statusCallbacks []func(ConnectionStatus)
+
+	journal *Journal
 }
 
 func NewAdapter(config *Config, appState *state.State) *Adapter {
@@ -43,32 +59,46 @@ func NewAdapter(config *Config, appState *state.State) *Adapter {
 	}
 }
 
-func (a *Adapter) Connect() error {
-	// Get current difference without holding the lock during processing
-	a.mu.RLock()
-	difference := a.currentDifference
-	a.mu.RUnlock()
+// ResumeFrom makes the next poll ask MeOS for the changes since the given difference key,
+// as saved with the state before a restart, instead of the complete competition
+func (a *Adapter) ResumeFrom(difference string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.currentDifference = difference
+	a.appliedDifference = difference
+}
 
-	_, err := a.fetchAndProcessData(difference)
-	if err != nil {
-		return err
-	}
+// AppliedDifference returns the difference key of the last MOP response whose data has
+// been applied to the state, empty before the first one. Unlike the key in Status it
+// never runs ahead of the state, so it is safe to resume from with a saved snapshot
+// taken after reading it.
+func (a *Adapter) AppliedDifference() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.appliedDifference
+}
 
-	a.mu.Lock()
-	a.connected = true
-	a.mu.Unlock()
-	return nil
+// Connect makes a single attempt to fetch data from MeOS. When it fails, StartPolling
+// keeps retrying with backoff until MeOS becomes reachable.
+func (a *Adapter) Connect() error {
+	_, err := a.poll()
+	return err
 }
 
+// StartPolling fetches updates from MeOS every poll interval. After a failed fetch the
+// next attempt is delayed with exponential backoff until the connection recovers.
 func (a *Adapter) StartPolling() error {
-	a.mu.RLock()
-	if !a.connected {
-		a.mu.RUnlock()
-		return fmt.Errorf("not connected to MeOS")
+	a.mu.Lock()
+	if a.polling {
+		a.mu.Unlock()
+		return fmt.Errorf("already polling MeOS")
 	}
-	a.mu.RUnlock()
+	a.polling = true
+	a.done = make(chan struct{})
+	a.mu.Unlock()
 
 	go func() {
+		defer close(a.done)
 		ticker := time.NewTicker(a.config.PollInterval)
 		defer ticker.Stop()
 
@@ -76,16 +106,21 @@ func (a *Adapter) StartPolling() error {
 			select {
 			case <-a.stopChan:
 				return
-			case <-ticker.C:
+			case now := <-ticker.C:
 				a.mu.RLock()
-				difference := a.currentDifference
+				nextAttempt := a.nextAttempt
 				a.mu.RUnlock()
 
-				updated, err := a.fetchAndProcessData(difference)
+				// Still backing off after a failure
+				if now.Before(nextAttempt) {
+					continue
+				}
+
+				updated, err := a.poll()
 				if err != nil {
 					logger.ErrorLogger.Printf("Error fetching/processing data: %v", err)
 				} else if updated {
-					logger.DebugLogger.Printf("Data updated from MeOS (difference: %s)", difference)
+					logger.DebugLogger.Printf("Data updated from MeOS (difference: %s)", a.Status().NextDifference)
 				}
 			}
 		}
@@ -96,15 +131,33 @@ func (a *Adapter) StartPolling() error {
 
 func (a *Adapter) Stop() error {
 	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	if a.connected {
+	var done chan struct{}
+	if a.polling {
 		close(a.stopChan)
-		a.connected = false
+		a.polling = false
+		done = a.done
+	}
+	a.connected = false
+	a.mu.Unlock()
+
+	// The polling goroutine takes the lock to poll, so wait without holding it
+	if done != nil {
+		<-done
 	}
 	return nil
 }
 
+// poll fetches the next difference from MeOS and records the outcome in the connection status
+func (a *Adapter) poll() (bool, error) {
+	a.mu.RLock()
+	difference := a.currentDifference
+	a.mu.RUnlock()
+
+	updated, err := a.fetchAndProcessData(difference)
+	a.recordAttempt(err)
+	return updated, err
+}
+
 func (a *Adapter) fetchAndProcessData(difference string) (bool, error) {
 	protocol := "http"
 	if a.config.HTTPS {
@@ -137,6 +190,36 @@ func (a *Adapter) fetchAndProcessData(difference string) (bool, error) {
 		return false, fmt.Errorf("failed to read response: %w", err)
 	}
 
+	return a.Apply(data)
+}
+
+// RecordTo makes the adapter write every MOP response it receives to the journal
+func (a *Adapter) RecordTo(journal *Journal) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.journal = journal
+}
+
+func (a *Adapter) record(data []byte) {
+	a.mu.RLock()
+	journal := a.journal
+	a.mu.RUnlock()
+
+	if journal == nil {
+		return
+	}
+	if err := journal.Write(time.Now(), data); err != nil {
+		logger.ErrorLogger.Printf("Failed to record MOP response: %v", err)
+	}
+}
+
+// Apply processes a raw MOPComplete or MOPDiff document as if it had been fetched from
+// MeOS, recording it to the journal if one is set. It reports whether the state was updated.
+func (a *Adapter) Apply(data []byte) (bool, error) {
+	// Every response is recorded, including those that change nothing, so the journal holds
+	// exactly what MeOS sent. Replaying an unchanged response is a no-op as its difference
+	// key is already current.
+	a.record(data)
 	return a.processData(data)
 }
 
@@ -151,7 +234,8 @@ func (a *Adapter) processData(data []byte) (bool, error) {
 		return false, fmt.Errorf("failed to parse XML: %w", err)
 	}
 
-	if root.NextDifference == a.currentDifference {
+	// Pushed documents carry no difference key and are always applied
+	if root.NextDifference != "" && root.NextDifference == a.currentDifference {
 		return false, nil
 	}
 
@@ -164,6 +248,14 @@ func (a *Adapter) processData(data []byte) (bool, error) {
 
 	var source interface{}
 	var isMOPComplete bool
+	var deleted deletions
+
+	// The event belongs to the published snapshot, so change a copy. It is published with
+	// the rest of the update so listeners are told when it changes.
+	var event models.Event
+	if current := a.state.GetEvent(); current != nil {
+		event = *current
+	}
 
 	if root.XMLName.Local == "MOPComplete" {
 		var mopComplete MOPComplete
@@ -172,17 +264,12 @@ func (a *Adapter) processData(data []byte) (bool, error) {
 		}
 		source = mopComplete
 		isMOPComplete = true
-		logger.InfoLogger.Printf("Received MOPComplete with %d controls, %d classes, %d clubs, %d competitors",
-			len(mopComplete.Controls), len(mopComplete.Classes), len(mopComplete.Organizations), len(mopComplete.Competitors))
+		logger.InfoLogger.Printf("Received MOPComplete with %d controls, %d classes, %d clubs, %d competitors, %d teams",
+			len(mopComplete.Controls), len(mopComplete.Classes), len(mopComplete.Organizations), len(mopComplete.Competitors), len(mopComplete.Teams))
 
-		if a.state.GetEvent() == nil {
-			a.state.SetEvent(&models.Event{})
-		}
-		event := a.state.GetEvent()
 		event.Name = mopComplete.Competition.Name
 		event.Organizer = mopComplete.Competition.Organizer
 		event.Start = mopComplete.Competition.Time()
-		a.state.SetEvent(event)
 
 	} else if root.XMLName.Local == "MOPDiff" {
 		var mopDiff MOPDiff
@@ -191,14 +278,11 @@ func (a *Adapter) processData(data []byte) (bool, error) {
 		}
 		source = mopDiff
 		isMOPComplete = false
-		logger.DebugLogger.Printf("Received MOPDiff with %d controls, %d classes, %d clubs, %d competitors",
-			len(mopDiff.Controls), len(mopDiff.Classes), len(mopDiff.Organizations), len(mopDiff.Competitors))
+		deleted = collectDeletions(mopDiff)
+		logger.DebugLogger.Printf("Received MOPDiff with %d controls, %d classes, %d clubs, %d competitors, %d teams",
+			len(mopDiff.Controls), len(mopDiff.Classes), len(mopDiff.Organizations), len(mopDiff.Competitors), len(mopDiff.Teams))
 
-		if a.state.GetEvent() == nil {
-			a.state.SetEvent(&models.Event{})
-		}
 		if mopDiff.Competition != nil {
-			event := a.state.GetEvent()
 			if mopDiff.Competition.Name != "" {
 				event.Name = mopDiff.Competition.Name
 			}
@@ -208,7 +292,6 @@ func (a *Adapter) processData(data []byte) (bool, error) {
 			if !mopDiff.Competition.Time().IsZero() {
 				event.Start = mopDiff.Competition.Time()
 			}
-			a.state.SetEvent(event)
 		}
 	} else {
 		return false, fmt.Errorf("unknown XML root element: %s", root.XMLName.Local)
@@ -218,64 +301,108 @@ func (a *Adapter) processData(data []byte) (bool, error) {
 	newControls := a.convertControls(source, isMOPComplete)
 	newClasses := a.convertClasses(source, isMOPComplete)
 	newClubs := a.convertClubs(source, isMOPComplete)
-	newCompetitors := a.convertCompetitors(source, isMOPComplete)
-
-	// Get current state for updating
-	currentEvent := a.state.GetEvent()
-	currentControls := a.state.GetControls()
-	currentClasses := a.state.GetClasses()
-	currentClubs := a.state.GetClubs()
-	currentCompetitors := a.state.GetCompetitors()
+	newCompetitors := a.convertCompetitors(source, isMOPComplete, &event)
+	newTeams := a.convertTeams(source, isMOPComplete, &event)
+
+	// Get current state for updating. The update functions copy the entity lists, but the
+	// entities still share their slices with the published snapshot, so those are copied
+	// before they are changed below.
+	current := a.state.Snapshot()
+
+	// Update entities and drop the ones MeOS has deleted
+	updatedControls := removeEntities(updateEntities(current.Controls(), newControls, isMOPComplete), deleted.controls)
+	updatedClubs := removeEntities(updateEntities(current.Clubs(), newClubs, isMOPComplete), deleted.clubs)
+	updatedClasses := removeEntities(updateEntities(current.Classes(), newClasses, isMOPComplete), deleted.classes)
+	updatedCompetitors := removeEntities(updateCompetitors(current.Competitors(), newCompetitors, isMOPComplete), deleted.competitors)
+	updatedTeams := removeEntities(updateEntities(current.Teams(), newTeams, isMOPComplete), deleted.teams)
+
+	if !deleted.empty() {
+		logger.DebugLogger.Printf("MOPDiff deleted %d controls, %d classes, %d clubs, %d competitors, %d teams",
+			len(deleted.controls), len(deleted.classes), len(deleted.clubs), len(deleted.competitors), len(deleted.teams))
+	}
 
-	// Update entities
-	updatedControls := updateEntities(currentControls, newControls, isMOPComplete)
-	updatedClubs := updateEntities(currentClubs, newClubs, isMOPComplete)
-	updatedClasses := updateEntities(currentClasses, newClasses, isMOPComplete)
-	updatedCompetitors := updateCompetitors(currentCompetitors, newCompetitors, isMOPComplete)
+	controlsByID := indexEntities(updatedControls)
 
 	// Resolve radio controls for classes
 	for i := range updatedClasses {
 		resolvedRadioControls := []models.Control{}
 		for _, rc := range updatedClasses[i].RadioControls {
-			for _, ctrl := range updatedControls {
-				if ctrl.ID == rc.ID {
-					resolvedRadioControls = append(resolvedRadioControls, ctrl)
-					break
-				}
+			if ctrl, ok := controlsByID[rc.ID]; ok {
+				resolvedRadioControls = append(resolvedRadioControls, ctrl)
 			}
 		}
 		updatedClasses[i].RadioControls = resolvedRadioControls
 	}
 
+	clubsByID := indexEntities(updatedClubs)
+	classesByID := indexEntities(updatedClasses)
+
+	// Resolve references for teams and remember which leg each runner belongs to
+	type teamLeg struct{ teamID, leg int }
+	runnerLegs := make(map[int]teamLeg)
+	for i := range updatedTeams {
+		// Drop references to clubs and classes deleted in this diff
+		if deleted.clubs[updatedTeams[i].Club.ID] {
+			updatedTeams[i].Club = models.Club{}
+		}
+		if deleted.classes[updatedTeams[i].Class.ID] {
+			updatedTeams[i].Class = models.Class{}
+		}
+		if club, ok := clubsByID[updatedTeams[i].Club.ID]; ok {
+			updatedTeams[i].Club = club
+		}
+		if class, ok := classesByID[updatedTeams[i].Class.ID]; ok {
+			updatedTeams[i].Class = class
+		}
+		for _, leg := range updatedTeams[i].Legs {
+			for _, competitorID := range leg.CompetitorIDs {
+				runnerLegs[competitorID] = teamLeg{teamID: updatedTeams[i].ID, leg: leg.Number}
+			}
+		}
+	}
+
 	// Resolve references for competitors
 	for i := range updatedCompetitors {
-		// Resolve club reference
-		for _, club := range updatedClubs {
-			if club.ID == updatedCompetitors[i].Club.ID {
-				updatedCompetitors[i].Club = club
-				break
-			}
+		if tl, ok := runnerLegs[updatedCompetitors[i].ID]; ok {
+			updatedCompetitors[i].TeamID = tl.teamID
+			updatedCompetitors[i].Leg = tl.leg
+		} else {
+			updatedCompetitors[i].TeamID = 0
+			updatedCompetitors[i].Leg = 0
 		}
-		// Resolve class reference
-		for _, class := range updatedClasses {
-			if class.ID == updatedCompetitors[i].Class.ID {
-				updatedCompetitors[i].Class = class
-				break
-			}
+
+		// Drop references to clubs and classes deleted in this diff
+		if deleted.clubs[updatedCompetitors[i].Club.ID] {
+			updatedCompetitors[i].Club = models.Club{}
 		}
-		// Resolve split control references
-		for j := range updatedCompetitors[i].Splits {
-			for _, ctrl := range updatedControls {
-				if ctrl.ID == updatedCompetitors[i].Splits[j].Control.ID {
-					updatedCompetitors[i].Splits[j].Control = ctrl
-					break
-				}
+		if deleted.classes[updatedCompetitors[i].Class.ID] {
+			updatedCompetitors[i].Class = models.Class{}
+		}
+
+		// Resolve club, class and split control references
+		if club, ok := clubsByID[updatedCompetitors[i].Club.ID]; ok {
+			updatedCompetitors[i].Club = club
+		}
+		if class, ok := classesByID[updatedCompetitors[i].Class.ID]; ok {
+			updatedCompetitors[i].Class = class
+		}
+		splits := slices.Clone(updatedCompetitors[i].Splits)
+		for j := range splits {
+			if ctrl, ok := controlsByID[splits[j].Control.ID]; ok {
+				splits[j].Control = ctrl
 			}
 		}
+		updatedCompetitors[i].Splits = splits
 	}
 
 	// Update state atomically and notify listeners
-	a.state.UpdateFromMeOS(currentEvent, updatedControls, updatedClasses, updatedClubs, updatedCompetitors)
+	a.state.UpdateFromMeOS(&event, updatedControls, updatedClasses, updatedClubs, updatedCompetitors, updatedTeams)
+
+	if root.NextDifference != "" {
+		a.mu.Lock()
+		a.appliedDifference = root.NextDifference
+		a.mu.Unlock()
+	}
 
 	return true, nil
 }
@@ -284,54 +411,121 @@ func updateEntities[T models.Entity](current, updates []T, isComplete bool) []T
 	if isComplete {
 		return append([]T{}, updates...)
 	}
+	return mergeUpdates(current, updates, func(_, update T) T { return update })
+}
 
-	result := append([]T{}, current...)
+// mergeUpdates replaces the entities that have an update with the result of merge and
+// appends the updates for new entities, in a single pass over current
+func mergeUpdates[T models.Entity](current, updates []T, merge func(existing, update T) T) []T {
+	// Index the last update of each entity
+	pending := make(map[int]int, len(updates))
+	for i, update := range updates {
+		pending[update.GetID()] = i
+	}
 
-	for _, update := range updates {
-		found := false
-		for i, existing := range result {
-			if existing.GetID() == update.GetID() {
-				result[i] = update
-				found = true
-				break
-			}
+	result := make([]T, 0, len(current)+len(updates))
+	for _, existing := range current {
+		if i, ok := pending[existing.GetID()]; ok {
+			delete(pending, existing.GetID())
+			existing = merge(existing, updates[i])
 		}
-
-		if !found {
-			result = append(result, update)
+		result = append(result, existing)
+	}
+	for _, update := range updates {
+		if i, ok := pending[update.GetID()]; ok {
+			delete(pending, update.GetID())
+			result = append(result, updates[i])
 		}
 	}
-
 	return result
 }
 
-func updateCompetitors(current, updates []models.Competitor, isComplete bool) []models.Competitor {
-	if isComplete {
-		return append([]models.Competitor{}, updates...)
+// indexEntities maps entity IDs to the entities
+func indexEntities[T models.Entity](entities []T) map[int]T {
+	index := make(map[int]T, len(entities))
+	for _, entity := range entities {
+		index[entity.GetID()] = entity
 	}
+	return index
+}
 
-	result := append([]models.Competitor{}, current...)
+// removeEntities returns entities without the ones whose ID is in deleted
+func removeEntities[T models.Entity](entities []T, deleted map[int]bool) []T {
+	if len(deleted) == 0 {
+		return entities
+	}
 
-	for _, update := range updates {
-		found := false
-		for i, existing := range result {
-			if existing.GetID() == update.GetID() {
-				// Preserve Card field if update has 0 (not provided in diff)
-				if update.Card == 0 && existing.Card != 0 {
-					update.Card = existing.Card
-				}
-				result[i] = update
-				found = true
-				break
-			}
+	result := make([]T, 0, len(entities))
+	for _, entity := range entities {
+		if !deleted[entity.GetID()] {
+			result = append(result, entity)
 		}
+	}
+	return result
+}
+
+// deletions holds the IDs of the entities a MOPDiff marks as deleted
+type deletions struct {
+	controls    map[int]bool
+	classes     map[int]bool
+	clubs       map[int]bool
+	competitors map[int]bool
+	teams       map[int]bool
+}
+
+func (d deletions) empty() bool {
+	return len(d.controls) == 0 && len(d.classes) == 0 && len(d.clubs) == 0 &&
+		len(d.competitors) == 0 && len(d.teams) == 0
+}
 
-		if !found {
-			result = append(result, update)
+func collectDeletions(diff MOPDiff) deletions {
+	d := deletions{
+		controls:    make(map[int]bool),
+		classes:     make(map[int]bool),
+		clubs:       make(map[int]bool),
+		competitors: make(map[int]bool),
+		teams:       make(map[int]bool),
+	}
+	for _, ctrl := range diff.Controls {
+		if ctrl.Delete {
+			d.controls[parseInt(ctrl.ID)] = true
+		}
+	}
+	for _, cls := range diff.Classes {
+		if cls.Delete {
+			d.classes[parseInt(cls.ID)] = true
+		}
+	}
+	for _, org := range diff.Organizations {
+		if org.Delete {
+			d.clubs[parseInt(org.ID)] = true
 		}
 	}
+	for _, cmp := range diff.Competitors {
+		if cmp.Delete {
+			d.competitors[parseInt(cmp.ID)] = true
+		}
+	}
+	for _, tm := range diff.Teams {
+		if tm.Delete {
+			d.teams[parseInt(tm.ID)] = true
+		}
+	}
+	return d
+}
 
-	return result
+func updateCompetitors(current, updates []models.Competitor, isComplete bool) []models.Competitor {
+	if isComplete {
+		return append([]models.Competitor{}, updates...)
+	}
+
+	return mergeUpdates(current, updates, func(existing, update models.Competitor) models.Competitor {
+		// Preserve Card field if update has 0 (not provided in diff)
+		if update.Card == 0 && existing.Card != 0 {
+			update.Card = existing.Card
+		}
+		return update
+	})
 }
 
 func (a *Adapter) convertControls(source interface{}, isComplete bool) []models.Control {
@@ -373,22 +567,38 @@ func (a *Adapter) convertClubs(source interface{}, isComplete bool) []models.Clu
 	return []models.Club{}
 }
 
-func (a *Adapter) convertCompetitors(source interface{}, isComplete bool) []models.Competitor {
+func (a *Adapter) convertCompetitors(source interface{}, isComplete bool, event *models.Event) []models.Competitor {
 	if isComplete {
 		if complete, ok := source.(MOPComplete); ok {
-			return a.convertCompetitorList(complete.Competitors)
+			return a.convertCompetitorList(complete.Competitors, event)
 		}
 	} else {
 		if diff, ok := source.(MOPDiff); ok {
-			return a.convertCompetitorList(diff.Competitors)
+			return a.convertCompetitorList(diff.Competitors, event)
 		}
 	}
 	return []models.Competitor{}
 }
 
+func (a *Adapter) convertTeams(source interface{}, isComplete bool, event *models.Event) []models.Team {
+	if isComplete {
+		if complete, ok := source.(MOPComplete); ok {
+			return a.convertTeamList(complete.Teams, event)
+		}
+	} else {
+		if diff, ok := source.(MOPDiff); ok {
+			return a.convertTeamList(diff.Teams, event)
+		}
+	}
+	return []models.Team{}
+}
+
 func (a *Adapter) convertControlList(controls []MOPControl) []models.Control {
 	result := make([]models.Control, 0, len(controls))
 	for _, ctrl := range controls {
+		if ctrl.Delete {
+			continue
+		}
 		result = append(result, models.Control{
 			ID:   parseInt(ctrl.ID),
 			Name: ctrl.Name,
@@ -400,6 +610,9 @@ func (a *Adapter) convertControlList(controls []MOPControl) []models.Control {
 func (a *Adapter) convertClassList(classes []MOPClass) []models.Class {
 	result := make([]models.Class, 0, len(classes))
 	for _, cls := range classes {
+		if cls.Delete {
+			continue
+		}
 		class := models.Class{
 			ID:            parseInt(cls.ID),
 			OrderKey:      parseInt(cls.Order),
@@ -408,7 +621,10 @@ func (a *Adapter) convertClassList(classes []MOPClass) []models.Class {
 		}
 
 		if cls.Radio != "" {
-			idStrs := strings.Split(cls.Radio, ",")
+			// Relay classes separate the radio controls of each leg with ';'
+			idStrs := strings.FieldsFunc(cls.Radio, func(r rune) bool {
+				return r == ',' || r == ';'
+			})
 
 			for _, idStr := range idStrs {
 				idStr = strings.TrimSpace(idStr)
@@ -428,6 +644,9 @@ func (a *Adapter) convertClassList(classes []MOPClass) []models.Class {
 func (a *Adapter) convertClubList(orgs []MOPOrg) []models.Club {
 	result := make([]models.Club, 0, len(orgs))
 	for _, org := range orgs {
+		if org.Delete {
+			continue
+		}
 		result = append(result, models.Club{
 			ID:          parseInt(org.ID),
 			CountryCode: org.Nationality,
@@ -437,9 +656,12 @@ func (a *Adapter) convertClubList(orgs []MOPOrg) []models.Club {
 	return result
 }
 
-func (a *Adapter) convertCompetitorList(cmps []MOPCompetitor) []models.Competitor {
+func (a *Adapter) convertCompetitorList(cmps []MOPCompetitor, event *models.Event) []models.Competitor {
 	result := make([]models.Competitor, 0, len(cmps))
 	for _, cmp := range cmps {
+		if cmp.Delete {
+			continue
+		}
 		// Store IDs for now, will be resolved later
 		clubID := parseInt(cmp.Base.Org)
 		club := models.Club{ID: clubID}
@@ -450,6 +672,7 @@ func (a *Adapter) convertCompetitorList(cmps []MOPCompetitor) []models.Competito
 		competitor := models.Competitor{
 			ID:     parseInt(cmp.ID),
 			Card:   parseInt(cmp.Card),
+			Bib:    cmp.Base.Bib,
 			Name:   cmp.Base.Text,
 			Status: cmp.Base.Status,
 			Club:   club,
@@ -457,71 +680,44 @@ func (a *Adapter) convertCompetitorList(cmps []MOPCompetitor) []models.Competito
 			Splits: []models.Split{},
 		}
 
-		startTimeDeciseconds := cmp.StartTime()
-
-		event := a.state.GetEvent()
-		if event != nil && startTimeDeciseconds > 0 {
-			eventStart := event.Start
-			compStartSeconds := eventStart.Hour()*3600 + eventStart.Minute()*60 + eventStart.Second()
-			compStartDeciseconds := compStartSeconds * 10
-
-			if startTimeDeciseconds != compStartDeciseconds {
-				seconds := startTimeDeciseconds / 10
-				hours := (seconds / 3600) % 24
-				minutes := (seconds % 3600) / 60
-				secs := seconds % 60
-
-				nanos := (startTimeDeciseconds % 10) * 100000000
-
-				startTime := time.Date(
-					eventStart.Year(),
-					eventStart.Month(),
-					eventStart.Day(),
-					hours,
-					minutes,
-					secs,
-					nanos,
-					eventStart.Location(),
-				)
-
-				competitor.StartTime = startTime
-
-				runningTimeDeciseconds := cmp.RunningTime()
-				if runningTimeDeciseconds > 0 {
-					runningDuration := decisecondsToTimes(runningTimeDeciseconds)
-					finishTime := startTime.Add(runningDuration)
-					competitor.FinishTime = &finishTime
-				}
+		if startTime, ok := absoluteTime(event, cmp.StartTime()); ok {
+			competitor.StartTime = startTime
 
-				if cmp.Radio != "" {
-					splitPairs := strings.Split(cmp.Radio, ";")
-					for _, pair := range splitPairs {
-						parts := strings.Split(pair, ",")
-						if len(parts) != 2 {
-							continue
-						}
+			runningTimeDeciseconds := cmp.RunningTime()
+			if runningTimeDeciseconds > 0 {
+				runningDuration := decisecondsToTimes(runningTimeDeciseconds)
+				finishTime := startTime.Add(runningDuration)
+				competitor.FinishTime = &finishTime
+			}
 
-						controlID := parseInt(parts[0])
-						splitTimeDeciseconds := parseInt(parts[1])
+			if cmp.Radio != "" {
+				splitPairs := strings.Split(cmp.Radio, ";")
+				for _, pair := range splitPairs {
+					parts := strings.Split(pair, ",")
+					if len(parts) != 2 {
+						continue
+					}
 
-						// Create control with just ID for now
-						control := models.Control{ID: controlID}
+					controlID := parseInt(parts[0])
+					splitTimeDeciseconds := parseInt(parts[1])
 
-						splitDuration := decisecondsToTimes(splitTimeDeciseconds)
-						passingTime := startTime.Add(splitDuration)
+					// Create control with just ID for now
+					control := models.Control{ID: controlID}
 
-						split := models.Split{
-							Control:     control,
-							PassingTime: passingTime,
-						}
+					splitDuration := decisecondsToTimes(splitTimeDeciseconds)
+					passingTime := startTime.Add(splitDuration)
 
-						competitor.Splits = append(competitor.Splits, split)
+					split := models.Split{
+						Control:     control,
+						PassingTime: passingTime,
 					}
 
-					sort.Slice(competitor.Splits, func(i, j int) bool {
-						return competitor.Splits[i].PassingTime.Before(competitor.Splits[j].PassingTime)
-					})
+					competitor.Splits = append(competitor.Splits, split)
 				}
+
+				sort.Slice(competitor.Splits, func(i, j int) bool {
+					return competitor.Splits[i].PassingTime.Before(competitor.Splits[j].PassingTime)
+				})
 			}
 		}
 
@@ -530,6 +726,80 @@ func (a *Adapter) convertCompetitorList(cmps []MOPCompetitor) []models.Competito
 	return result
 }
 
+func (a *Adapter) convertTeamList(tms []MOPTeam, event *models.Event) []models.Team {
+	result := make([]models.Team, 0, len(tms))
+	for _, tm := range tms {
+		if tm.Delete {
+			continue
+		}
+		team := models.Team{
+			ID:     parseInt(tm.ID),
+			Name:   tm.Base.Text,
+			Bib:    tm.Base.Bib,
+			Status: tm.Base.Status,
+			// Store IDs for now, will be resolved later
+			Club:  models.Club{ID: parseInt(tm.Base.Org)},
+			Class: models.Class{ID: parseInt(tm.Base.Class)},
+			Legs:  []models.Leg{},
+		}
+
+		if tm.Runners != "" {
+			for i, leg := range strings.Split(tm.Runners, ";") {
+				teamLeg := models.Leg{Number: i + 1, CompetitorIDs: []int{}}
+				for _, idStr := range strings.Split(leg, ",") {
+					if id := parseInt(strings.TrimSpace(idStr)); id > 0 {
+						teamLeg.CompetitorIDs = append(teamLeg.CompetitorIDs, id)
+					}
+				}
+				team.Legs = append(team.Legs, teamLeg)
+			}
+		}
+
+		if startTime, ok := absoluteTime(event, tm.StartTime()); ok {
+			team.StartTime = startTime
+			if runningTimeDeciseconds := tm.RunningTime(); runningTimeDeciseconds > 0 {
+				finishTime := startTime.Add(decisecondsToTimes(runningTimeDeciseconds))
+				team.FinishTime = &finishTime
+			}
+		}
+
+		result = append(result, team)
+	}
+	return result
+}
+
+// absoluteTime converts a MOP time of day in deciseconds to a time on the event date.
+// A time equal to the event zero time is treated as unset, as MeOS sends it for
+// competitors without an assigned start.
+func absoluteTime(event *models.Event, deciseconds int) (time.Time, bool) {
+	if event == nil || deciseconds <= 0 {
+		return time.Time{}, false
+	}
+
+	eventStart := event.Start
+	compStartSeconds := eventStart.Hour()*3600 + eventStart.Minute()*60 + eventStart.Second()
+	if deciseconds == compStartSeconds*10 {
+		return time.Time{}, false
+	}
+
+	seconds := deciseconds / 10
+	hours := (seconds / 3600) % 24
+	minutes := (seconds % 3600) / 60
+	secs := seconds % 60
+	nanos := (deciseconds % 10) * 100000000
+
+	return time.Date(
+		eventStart.Year(),
+		eventStart.Month(),
+		eventStart.Day(),
+		hours,
+		minutes,
+		secs,
+		nanos,
+		eventStart.Location(),
+	), true
+}
+
 func decisecondsToTimes(deciseconds int) time.Duration {
 	seconds := deciseconds / 10
 	nanoseconds := (deciseconds % 10) * 100000000
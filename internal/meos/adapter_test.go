@@ -3,13 +3,18 @@ package meos
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"meos-graphics/internal/logger"
+	"meos-graphics/internal/persist"
 	"meos-graphics/internal/state"
+	"meos-graphics/internal/testhelpers"
 )
 
 func TestAdapter_PollInterval(t *testing.T) {
@@ -246,3 +251,253 @@ func TestGetStatusDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestAdapter_Backoff(t *testing.T) {
+	adapter := NewAdapter(&Config{PollInterval: time.Second, MaxBackoff: 10 * time.Second}, state.New())
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{50, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := adapter.backoff(tt.failures); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+
+	// A max backoff below the poll interval never makes polling faster
+	adapter.config.MaxBackoff = 100 * time.Millisecond
+	if got := adapter.backoff(3); got != time.Second {
+		t.Errorf("backoff with small MaxBackoff = %v, want %v", got, time.Second)
+	}
+}
+
+func TestAdapter_Reconnect(t *testing.T) {
+	// Initialize logger for tests
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	// Fail the first two requests, then behave like a healthy MeOS server
+	var callCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&callCount, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<MOPComplete nextdifference="abc123">
+    <competition date="2024-01-01" organizer="Test Organizer" zerotime="10:00:00">Test Competition</competition>
+</MOPComplete>`))
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	port, _ := strconv.Atoi(serverURL.Port())
+
+	config := &Config{
+		Hostname:     serverURL.Hostname(),
+		Port:         port,
+		PortStr:      serverURL.Port(),
+		PollInterval: 20 * time.Millisecond,
+		MaxBackoff:   40 * time.Millisecond,
+	}
+	appState := state.New()
+	adapter := NewAdapter(config, appState)
+
+	var mu sync.Mutex
+	var statuses []ConnectionStatus
+	adapter.OnStatusChange(func(status ConnectionStatus) {
+		mu.Lock()
+		statuses = append(statuses, status)
+		mu.Unlock()
+	})
+
+	if err := adapter.Connect(); err == nil {
+		t.Fatal("Connect() should fail while the server returns errors")
+	}
+
+	status := adapter.Status()
+	if status.Connected {
+		t.Error("Status().Connected should be false after a failed Connect()")
+	}
+	if status.ConsecutiveFailures != 1 {
+		t.Errorf("ConsecutiveFailures = %d, want 1", status.ConsecutiveFailures)
+	}
+	if status.LastError == "" || status.NextRetry == nil {
+		t.Error("Failed status should include the last error and next retry time")
+	}
+
+	// Polling must start even though the first connection attempt failed
+	if err := adapter.StartPolling(); err != nil {
+		t.Fatalf("StartPolling() error = %v", err)
+	}
+	defer adapter.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !adapter.Status().Connected && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	status = adapter.Status()
+	if !status.Connected {
+		t.Fatalf("Adapter did not reconnect, status = %+v", status)
+	}
+	if status.ConsecutiveFailures != 0 || status.LastError != "" || status.NextRetry != nil {
+		t.Errorf("Connected status should be reset, got %+v", status)
+	}
+	if status.LastSuccess == nil || status.NextDifference != "abc123" {
+		t.Errorf("Connected status missing success details, got %+v", status)
+	}
+	if event := appState.GetEvent(); event == nil || event.Name != "Test Competition" {
+		t.Error("State should be loaded after reconnecting")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(statuses) != 3 {
+		t.Fatalf("Got %d status notifications, want 3 (two failures, one reconnect)", len(statuses))
+	}
+	if statuses[0].Connected || statuses[1].Connected || !statuses[2].Connected {
+		t.Errorf("Unexpected notification sequence: %+v", statuses)
+	}
+	if statuses[1].ConsecutiveFailures != 2 {
+		t.Errorf("Second notification ConsecutiveFailures = %d, want 2", statuses[1].ConsecutiveFailures)
+	}
+}
+
+func TestAdapter_ResumeFrom(t *testing.T) {
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	var requested atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested.Store(r.URL.Query().Get("difference"))
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<MOPDiff nextdifference="def456"></MOPDiff>`))
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	port, _ := strconv.Atoi(serverURL.Port())
+
+	config := &Config{
+		Hostname:     serverURL.Hostname(),
+		Port:         port,
+		PortStr:      serverURL.Port(),
+		PollInterval: time.Second,
+	}
+	adapter := NewAdapter(config, state.New())
+	adapter.ResumeFrom("abc123")
+
+	if status := adapter.Status(); status.NextDifference != "abc123" {
+		t.Errorf("NextDifference = %q after ResumeFrom, want abc123", status.NextDifference)
+	}
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if got := requested.Load(); got != "abc123" {
+		t.Errorf("Requested difference = %v, want abc123", got)
+	}
+	if status := adapter.Status(); status.NextDifference != "def456" {
+		t.Errorf("NextDifference = %q after the diff, want def456", status.NextDifference)
+	}
+}
+
+func TestAdapter_AppliedDifference(t *testing.T) {
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	appState := state.New()
+	adapter := NewAdapter(&Config{Hostname: "localhost", Port: 2009, PortStr: "2009", PollInterval: time.Second}, appState)
+	path := filepath.Join(t.TempDir(), "state.json")
+	saver := persist.NewSaver(path, time.Hour, appState)
+	saver.TrackDifference(adapter.AppliedDifference)
+
+	if _, err := adapter.Apply([]byte(testhelpers.MOPCompleteXML())); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got := adapter.AppliedDifference(); got != "abc123" {
+		t.Fatalf("AppliedDifference() = %q, want abc123", got)
+	}
+
+	// The key moves on but the data never reaches the state, as when a save runs while a
+	// response is being processed
+	broken := `<?xml version="1.0" encoding="UTF-8"?>
+<MOPDiff nextdifference="def456"><ctrl id="101" delete="maybe"/></MOPDiff>`
+	if _, err := adapter.Apply([]byte(broken)); err == nil {
+		t.Fatal("Apply() of an invalid diff succeeded")
+	}
+	if status := adapter.Status(); status.NextDifference != "def456" {
+		t.Fatalf("NextDifference = %q, want def456", status.NextDifference)
+	}
+
+	// The saved snapshot resumes from the last key whose data it has
+	if err := saver.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	saved, err := persist.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if saved.NextDifference != "abc123" {
+		t.Errorf("Saved difference = %q, want abc123", saved.NextDifference)
+	}
+
+	restored := NewAdapter(adapter.config, state.New())
+	restored.ResumeFrom(saved.NextDifference)
+	if got := restored.AppliedDifference(); got != "abc123" {
+		t.Errorf("AppliedDifference() after ResumeFrom = %q, want abc123", got)
+	}
+}
+
+func TestAdapter_EventChange(t *testing.T) {
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	appState := state.New()
+	adapter := NewAdapter(&Config{Hostname: "localhost", Port: 2009, PortStr: "2009", PollInterval: time.Second}, appState)
+	if _, err := adapter.Apply([]byte(testhelpers.MOPCompleteXML())); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	var changes []state.Change
+	appState.OnChange(func(change state.Change) { changes = append(changes, change) })
+	revision := appState.Revision()
+
+	// A diff that only renames the competition still reaches the listeners
+	renamed := `<?xml version="1.0" encoding="UTF-8"?>
+<MOPDiff nextdifference="def456"><competition date="2024-01-01" zerotime="10:00:00">Renamed</competition></MOPDiff>`
+	if _, err := adapter.Apply([]byte(renamed)); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(changes) != 1 || !changes[0].Event {
+		t.Fatalf("Changes = %+v, want one event change", changes)
+	}
+	if got := appState.Revision(); got != revision+1 {
+		t.Errorf("Revision = %d, want %d", got, revision+1)
+	}
+	event := appState.GetEvent()
+	if event.Name != "Renamed" || event.Organizer != "Test Organizer" {
+		t.Errorf("Event = %q by %q, want Renamed by Test Organizer", event.Name, event.Organizer)
+	}
+}
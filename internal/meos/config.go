@@ -13,6 +13,7 @@ type Config struct {
 	Port         int
 	PortStr      string // Original port string (can be "none")
 	PollInterval time.Duration
+	MaxBackoff   time.Duration // Upper bound for the retry delay after failed polls
 	HTTPS        bool
 }
 
@@ -22,6 +23,7 @@ func NewConfig() *Config {
 		Port:         2009,
 		PortStr:      "2009",
 		PollInterval: 1 * time.Second,
+		MaxBackoff:   30 * time.Second,
 		HTTPS:        false,
 	}
 }
@@ -57,6 +59,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("poll interval too large (maximum 1 hour): %s", c.PollInterval)
 	}
 
+	if c.MaxBackoff < 0 {
+		return fmt.Errorf("max backoff cannot be negative: %s", c.MaxBackoff)
+	}
+
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package meos
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -197,7 +198,7 @@ func TestAdapter_ConvertCompetitors(t *testing.T) {
 		},
 	}
 
-	competitors := adapter.convertCompetitors(mopComplete, true)
+	competitors := adapter.convertCompetitors(mopComplete, true, appState.GetEvent())
 
 	if len(competitors) != 3 {
 		t.Errorf("Number of competitors = %d, want %d", len(competitors), 3)
@@ -365,7 +366,7 @@ func TestRadioTimeParsing(t *testing.T) {
 		},
 	}
 
-	competitors := adapter.convertCompetitors(mopComplete, true)
+	competitors := adapter.convertCompetitors(mopComplete, true, appState.GetEvent())
 
 	// Test first competitor with valid radio times
 	if len(competitors) > 0 {
@@ -497,7 +498,7 @@ func TestAdapter_ConvertWithInvalidIDs(t *testing.T) {
 	controls := adapter.convertControls(mopComplete, true)
 	classes := adapter.convertClasses(mopComplete, true)
 	clubs := adapter.convertClubs(mopComplete, true)
-	competitors := adapter.convertCompetitors(mopComplete, true)
+	competitors := adapter.convertCompetitors(mopComplete, true, appState.GetEvent())
 
 	// Verify that invalid IDs are converted to 0
 	if len(controls) > 0 && controls[0].ID != 0 {
@@ -521,3 +522,20 @@ func TestAdapter_ConvertWithInvalidIDs(t *testing.T) {
 		t.Errorf("Valid class ID = %d, want %d", classes[1].ID, 1)
 	}
 }
+
+func BenchmarkUpdateCompetitors(b *testing.B) {
+	current := make([]models.Competitor, 3000)
+	for i := range current {
+		current[i] = models.Competitor{ID: i + 1, Card: 100000 + i, Name: fmt.Sprintf("Competitor %d", i+1)}
+	}
+	updates := make([]models.Competitor, 500)
+	for i := range updates {
+		// Every other update is for a new competitor
+		updates[i] = models.Competitor{ID: i*12 + 1, Status: "1"}
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = updateCompetitors(current, updates, false)
+	}
+}
@@ -0,0 +1,78 @@
+package meos
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a single raw MOP response as received from MeOS
+type JournalEntry struct {
+	Time time.Time `json:"time"`
+	Data string    `json:"data"`
+}
+
+// Journal records raw MOP responses as JSON lines so a race can be replayed later
+type Journal struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// OpenJournal opens a journal file for recording, appending to it if it already exists
+func OpenJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	return &Journal{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Write appends a response received at the given time to the journal
+func (j *Journal) Write(at time.Time, data []byte) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.encoder.Encode(JournalEntry{Time: at, Data: string(data)}); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// ReadJournal reads all entries from a journal in the order they were recorded
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	var entries []JournalEntry
+
+	scanner := bufio.NewScanner(r)
+	// A MOPComplete for a large event easily exceeds the default token size
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid journal entry on line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return entries, nil
+}
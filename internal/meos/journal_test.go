@@ -0,0 +1,126 @@
+package meos
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/state"
+	"meos-graphics/internal/testhelpers"
+)
+
+func TestJournal_WriteAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "race.journal")
+
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal() error = %v", err)
+	}
+
+	first := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(5 * time.Second)
+	if err := journal.Write(first, []byte(testhelpers.MOPCompleteXML())); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := journal.Write(second, []byte(testhelpers.MOPDiffXML())); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := journal.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	defer file.Close()
+
+	entries, err := ReadJournal(file)
+	if err != nil {
+		t.Fatalf("ReadJournal() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Got %d entries, want 2", len(entries))
+	}
+	if !entries[0].Time.Equal(first) || !entries[1].Time.Equal(second) {
+		t.Errorf("Entry times = %v, %v, want %v, %v", entries[0].Time, entries[1].Time, first, second)
+	}
+	if entries[0].Data != testhelpers.MOPCompleteXML() {
+		t.Error("First entry data does not match the recorded MOPComplete")
+	}
+}
+
+func TestReadJournal_Invalid(t *testing.T) {
+	_, err := ReadJournal(strings.NewReader("{\"time\":\"2024-01-01T10:00:00Z\",\"data\":\"<MOPComplete/>\"}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ReadJournal() error = %v, want error mentioning line 2", err)
+	}
+}
+
+func TestAdapter_RecordTo(t *testing.T) {
+	// Initialize logger for tests
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(testhelpers.MOPCompleteXML()))
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+	port, _ := strconv.Atoi(serverURL.Port())
+
+	path := filepath.Join(t.TempDir(), "race.journal")
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal() error = %v", err)
+	}
+
+	adapter := NewAdapter(&Config{
+		Hostname:     serverURL.Hostname(),
+		Port:         port,
+		PortStr:      serverURL.Port(),
+		PollInterval: time.Second,
+	}, state.New())
+	adapter.RecordTo(journal)
+
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	// The same nextdifference again does not change anything but is still recorded
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	journal.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	defer file.Close()
+
+	entries, err := ReadJournal(file)
+	if err != nil {
+		t.Fatalf("ReadJournal() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Got %d entries, want 2", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Data != testhelpers.MOPCompleteXML() {
+			t.Errorf("Recorded data of entry %d does not match the MeOS response", i)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package meos
+
+import (
+	"time"
+)
+
+// ConnectionStatus describes the health of the link to the MeOS information server
+type ConnectionStatus struct {
+	Connected           bool       `json:"connected"`
+	LastSuccess         *time.Time `json:"lastSuccess,omitempty"`
+	LastAttempt         *time.Time `json:"lastAttempt,omitempty"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	NextDifference      string     `json:"nextDifference"`
+	NextRetry           *time.Time `json:"nextRetry,omitempty"` // Set while backing off after failures
+}
+
+// Status returns a snapshot of the adapter's connection status
+func (a *Adapter) Status() ConnectionStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.statusLocked()
+}
+
+// OnStatusChange registers a callback to be called when the connection goes up or down
+// and on every failed attempt to reach MeOS
+func (a *Adapter) OnStatusChange(callback func(ConnectionStatus)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.statusCallbacks = append(a.statusCallbacks, callback)
+}
+
+func (a *Adapter) statusLocked() ConnectionStatus {
+	status := ConnectionStatus{
+		Connected:           a.connected,
+		ConsecutiveFailures: a.failures,
+		LastError:           a.lastError,
+		NextDifference:      a.currentDifference,
+	}
+	if !a.lastSuccess.IsZero() {
+		lastSuccess := a.lastSuccess
+		status.LastSuccess = &lastSuccess
+	}
+	if !a.lastAttempt.IsZero() {
+		lastAttempt := a.lastAttempt
+		status.LastAttempt = &lastAttempt
+	}
+	if !a.nextAttempt.IsZero() {
+		nextRetry := a.nextAttempt
+		status.NextRetry = &nextRetry
+	}
+	return status
+}
+
+// recordAttempt updates the connection status after a fetch from MeOS and notifies
+// listeners when the link changed state or the attempt failed
+func (a *Adapter) recordAttempt(err error) {
+	now := time.Now()
+
+	a.mu.Lock()
+	wasConnected := a.connected
+	a.lastAttempt = now
+	if err == nil {
+		a.connected = true
+		a.failures = 0
+		a.lastError = ""
+		a.lastSuccess = now
+		a.nextAttempt = time.Time{}
+	} else {
+		a.connected = false
+		a.failures++
+		a.lastError = err.Error()
+		a.nextAttempt = now.Add(a.backoff(a.failures))
+	}
+	status := a.statusLocked()
+	callbacks := make([]func(ConnectionStatus), len(a.statusCallbacks))
+	copy(callbacks, a.statusCallbacks)
+	a.mu.Unlock()
+
+	if err == nil && wasConnected {
+		return
+	}
+	for _, cb := range callbacks {
+		cb(status)
+	}
+}
+
+// backoff returns how long to wait before retrying after the given number of
+// consecutive failures: the poll interval doubled per failure, capped at MaxBackoff
+func (a *Adapter) backoff(failures int) time.Duration {
+	delay := a.config.PollInterval
+	maxBackoff := a.config.MaxBackoff
+	if maxBackoff < delay {
+		maxBackoff = delay
+	}
+	for i := 1; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
@@ -14,8 +14,11 @@ type MOPComplete struct {
 	Classes        []MOPClass      `xml:"cls"`
 	Organizations  []MOPOrg        `xml:"org"`
 	Competitors    []MOPCompetitor `xml:"cmp"`
+	Teams          []MOPTeam       `xml:"tm"`
 }
 
+// MOPDiff holds the changes since the previous difference key. Entities removed in
+// MeOS are sent as empty elements with delete="true".
 type MOPDiff struct {
 	XMLName        xml.Name `xml:"MOPDiff"`
 	NextDifference string   `xml:"nextdifference,attr"`
@@ -24,6 +27,7 @@ type MOPDiff struct {
 	Classes        []MOPClass      `xml:"cls"`
 	Organizations  []MOPOrg        `xml:"org"`
 	Competitors    []MOPCompetitor `xml:"cmp"`
+	Teams          []MOPTeam       `xml:"tm"`
 }
 
 type MOPCompetition struct {
@@ -37,12 +41,14 @@ type MOPCompetition struct {
 type MOPControl struct {
 	XMLName xml.Name `xml:"ctrl"`
 	ID      string   `xml:"id,attr"`
+	Delete  bool     `xml:"delete,attr,omitempty"`
 	Name    string   `xml:",chardata"`
 }
 
 type MOPClass struct {
 	XMLName xml.Name `xml:"cls"`
 	ID      string   `xml:"id,attr"`
+	Delete  bool     `xml:"delete,attr,omitempty"`
 	Order   string   `xml:"ord,attr"`
 	Radio   string   `xml:"radio,attr"`
 	Name    string   `xml:",chardata"`
@@ -51,6 +57,7 @@ type MOPClass struct {
 type MOPOrg struct {
 	XMLName     xml.Name `xml:"org"`
 	ID          string   `xml:"id,attr"`
+	Delete      bool     `xml:"delete,attr,omitempty"`
 	Nationality string   `xml:"nat,attr,omitempty"`
 	Name        string   `xml:",chardata"`
 }
@@ -62,17 +69,29 @@ type MOPBase struct {
 	Status      string   `xml:"stat,attr"`
 	StartTime   string   `xml:"st,attr"`
 	RunningTime string   `xml:"rt,attr"`
+	Bib         string   `xml:"bib,attr,omitempty"`
 	Text        string   `xml:",chardata"`
 }
 
 type MOPCompetitor struct {
 	XMLName xml.Name `xml:"cmp"`
 	ID      string   `xml:"id,attr"`
+	Delete  bool     `xml:"delete,attr,omitempty"`
 	Card    string   `xml:"card,attr"`
 	Base    MOPBase
 	Radio   string `xml:"radio"`
 }
 
+// MOPTeam is a relay team. Runners lists the competitor IDs per leg: legs are
+// separated by ';' and parallel runners within a leg by ','.
+type MOPTeam struct {
+	XMLName xml.Name `xml:"tm"`
+	ID      string   `xml:"id,attr"`
+	Delete  bool     `xml:"delete,attr,omitempty"`
+	Base    MOPBase
+	Runners string `xml:"r"`
+}
+
 func (c *MOPCompetitor) StartTime() int {
 	return parseInt(c.Base.StartTime)
 }
@@ -81,6 +100,14 @@ func (c *MOPCompetitor) RunningTime() int {
 	return parseInt(c.Base.RunningTime)
 }
 
+func (t *MOPTeam) StartTime() int {
+	return parseInt(t.Base.StartTime)
+}
+
+func (t *MOPTeam) RunningTime() int {
+	return parseInt(t.Base.RunningTime)
+}
+
 func parseInt(s string) int {
 	if s == "" {
 		return 0
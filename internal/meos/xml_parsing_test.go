@@ -2,9 +2,16 @@ package meos
 
 import (
 	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/models"
 	"meos-graphics/internal/state"
 	"meos-graphics/internal/testhelpers"
 )
@@ -341,6 +348,52 @@ func TestAdapter_ProcessData_MOPDiff(t *testing.T) {
 	}
 }
 
+func TestAdapter_ProcessData_KeepsOlderSnapshots(t *testing.T) {
+	config := &Config{
+		Hostname:     "localhost",
+		Port:         2009,
+		PortStr:      "2009",
+		PollInterval: 1 * time.Second,
+	}
+	appState := state.New()
+	adapter := NewAdapter(config, appState)
+
+	if _, err := adapter.processData([]byte(testhelpers.MOPCompleteXML())); err != nil {
+		t.Fatalf("Initial processData failed: %v", err)
+	}
+	old := appState.Snapshot()
+
+	diff := `<?xml version="1.0" encoding="UTF-8"?>
+<MOPDiff nextdifference="ren456">
+    <competition date="2024-01-01" organizer="Test Organizer" zerotime="10:00:00">Renamed Competition</competition>
+    <ctrl id="101">Renamed</ctrl>
+</MOPDiff>`
+	if _, err := adapter.processData([]byte(diff)); err != nil {
+		t.Fatalf("processData for diff failed: %v", err)
+	}
+
+	// The older snapshot is unchanged
+	comp, _ := old.Competitor(1)
+	if name := comp.Splits[0].Control.Name; name != "Control 1" {
+		t.Errorf("Older snapshot split control = %q, want %q", name, "Control 1")
+	}
+	if name := old.Event().Name; name != "Test Competition" {
+		t.Errorf("Older snapshot event name = %q, want %q", name, "Test Competition")
+	}
+
+	// The new snapshot has the renamed control, and the change is detected
+	comp, _ = appState.Snapshot().Competitor(1)
+	if name := comp.Splits[0].Control.Name; name != "Renamed" {
+		t.Errorf("Split control = %q, want %q", name, "Renamed")
+	}
+	if name := appState.GetEvent().Name; name != "Renamed Competition" {
+		t.Errorf("Event name = %q, want %q", name, "Renamed Competition")
+	}
+	if change := state.Diff(old, appState.Snapshot()); !change.Event || !slices.Contains(change.Competitors, 1) {
+		t.Errorf("Expected the event and competitor 1 changed, got %+v", change)
+	}
+}
+
 func TestAdapter_ProcessData_NoUpdate(t *testing.T) {
 	config := &Config{
 		Hostname:     "localhost",
@@ -403,3 +456,221 @@ func TestAdapter_ProcessData_UnknownRootElement(t *testing.T) {
 		t.Errorf("Unexpected error message: %v", err)
 	}
 }
+
+func TestAdapter_ProcessData_Relay(t *testing.T) {
+	appState := state.New()
+	adapter := NewAdapter(NewConfig(), appState)
+
+	if _, err := adapter.processData([]byte(testhelpers.MOPRelayXML())); err != nil {
+		t.Fatalf("processData failed: %v", err)
+	}
+
+	teams := appState.GetTeams()
+	if len(teams) != 2 {
+		t.Fatalf("Number of teams = %d, want %d", len(teams), 2)
+	}
+
+	team := appState.GetTeam(10)
+	if team == nil {
+		t.Fatal("Team 10 should exist")
+	}
+	if team.Name != "Test Club 1 Team" {
+		t.Errorf("Team name = %q, want %q", team.Name, "Test Club 1 Team")
+	}
+	if team.Bib != "1" {
+		t.Errorf("Team bib = %q, want %q", team.Bib, "1")
+	}
+	if team.Club.Name != "Test Club 1" {
+		t.Errorf("Team club = %q, want %q", team.Club.Name, "Test Club 1")
+	}
+	if team.Class.Name != "Relay" {
+		t.Errorf("Team class = %q, want %q", team.Class.Name, "Relay")
+	}
+	if team.FinishTime == nil || team.FinishTime.Sub(team.StartTime) != 2*time.Hour {
+		t.Errorf("Team running time = %v, want %v", team.FinishTime, 2*time.Hour)
+	}
+	if len(team.Legs) != 2 {
+		t.Fatalf("Number of legs = %d, want %d", len(team.Legs), 2)
+	}
+	if team.Legs[1].Number != 2 || len(team.Legs[1].CompetitorIDs) != 1 || team.Legs[1].CompetitorIDs[0] != 102 {
+		t.Errorf("Leg 2 = %+v, want competitor 102", team.Legs[1])
+	}
+
+	runner := appState.GetCompetitor(102)
+	if runner == nil {
+		t.Fatal("Competitor 102 should exist")
+	}
+	if runner.TeamID != 10 || runner.Leg != 2 {
+		t.Errorf("Competitor 102 team/leg = %d/%d, want 10/2", runner.TeamID, runner.Leg)
+	}
+
+	// Relay classes list the radio controls of all legs
+	classes := appState.GetClasses()
+	if len(classes) != 1 || len(classes[0].RadioControls) != 2 {
+		t.Errorf("Relay class radio controls = %+v, want 2 controls", classes)
+	}
+}
+
+func TestXMLParsing_ParallelLegs(t *testing.T) {
+	appState := state.New()
+	appState.SetEvent(&models.Event{Start: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	adapter := NewAdapter(NewConfig(), appState)
+
+	teams := adapter.convertTeamList([]MOPTeam{
+		{ID: "1", Base: MOPBase{Class: "1", Text: "Team"}, Runners: "1;2,3;4"},
+	}, appState.GetEvent())
+
+	if len(teams) != 1 || len(teams[0].Legs) != 3 {
+		t.Fatalf("Legs = %+v, want 3 legs", teams)
+	}
+	if len(teams[0].Legs[1].CompetitorIDs) != 2 {
+		t.Errorf("Parallel leg runners = %v, want 2", teams[0].Legs[1].CompetitorIDs)
+	}
+}
+
+func TestXMLParsing_DeleteMarkers(t *testing.T) {
+	var mopDiff MOPDiff
+	if err := xml.Unmarshal([]byte(testhelpers.MOPDiffWithDeletionsXML()), &mopDiff); err != nil {
+		t.Fatalf("Failed to parse MOPDiff: %v", err)
+	}
+
+	if len(mopDiff.Controls) != 1 || !mopDiff.Controls[0].Delete {
+		t.Errorf("Control delete marker not parsed: %+v", mopDiff.Controls)
+	}
+	if len(mopDiff.Classes) != 1 || !mopDiff.Classes[0].Delete {
+		t.Errorf("Class delete marker not parsed: %+v", mopDiff.Classes)
+	}
+	if len(mopDiff.Organizations) != 1 || !mopDiff.Organizations[0].Delete {
+		t.Errorf("Organization delete marker not parsed: %+v", mopDiff.Organizations)
+	}
+	if len(mopDiff.Competitors) != 1 || !mopDiff.Competitors[0].Delete {
+		t.Errorf("Competitor delete marker not parsed: %+v", mopDiff.Competitors)
+	}
+
+	d := collectDeletions(mopDiff)
+	if !d.controls[102] || !d.classes[2] || !d.clubs[2] || !d.competitors[1] {
+		t.Errorf("Deletions = %+v, want control 102, class 2, club 2 and competitor 1", d)
+	}
+}
+
+func TestAdapter_ProcessData_Deletions(t *testing.T) {
+	appState := state.New()
+	adapter := NewAdapter(NewConfig(), appState)
+
+	if _, err := adapter.processData([]byte(testhelpers.MOPCompleteXML())); err != nil {
+		t.Fatalf("processData MOPComplete failed: %v", err)
+	}
+
+	updates := 0
+	appState.OnUpdate(func() { updates++ })
+
+	updated, err := adapter.processData([]byte(testhelpers.MOPDiffWithDeletionsXML()))
+	if err != nil {
+		t.Fatalf("processData MOPDiff failed: %v", err)
+	}
+	if !updated {
+		t.Error("Expected data to be updated")
+	}
+	if updates != 1 {
+		t.Errorf("Update notifications = %d, want 1", updates)
+	}
+
+	if len(appState.GetControls()) != 4 {
+		t.Errorf("Number of controls = %d, want 4", len(appState.GetControls()))
+	}
+	if len(appState.GetClasses()) != 1 {
+		t.Errorf("Number of classes = %d, want 1", len(appState.GetClasses()))
+	}
+	if len(appState.GetClubs()) != 1 {
+		t.Errorf("Number of clubs = %d, want 1", len(appState.GetClubs()))
+	}
+	if appState.GetCompetitor(1) != nil {
+		t.Error("Deleted competitor 1 should be removed")
+	}
+
+	// Radio controls of remaining classes no longer include the deleted control
+	for _, ctrl := range appState.GetClasses()[0].RadioControls {
+		if ctrl.ID == 102 {
+			t.Error("Deleted control 102 should not be a radio control")
+		}
+	}
+
+	// Jane Smith's club was deleted, Mike Johnson's class was deleted
+	jane := appState.GetCompetitor(2)
+	if jane == nil || jane.Club.ID != 0 || jane.Club.Name != "" {
+		t.Errorf("Competitor 2 club = %+v, want empty club", jane)
+	}
+	mike := appState.GetCompetitor(3)
+	if mike == nil || mike.Class.ID != 0 {
+		t.Errorf("Competitor 3 class = %+v, want empty class", mike)
+	}
+	if len(appState.GetCompetitorsByClass(2)) != 0 {
+		t.Error("No competitors should remain in deleted class 2")
+	}
+}
+
+func TestRemoveEntities(t *testing.T) {
+	controls := []models.Control{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	result := removeEntities(controls, map[int]bool{2: true, 4: true})
+	if len(result) != 2 || result[0].ID != 1 || result[1].ID != 3 {
+		t.Errorf("removeEntities = %+v, want controls 1 and 3", result)
+	}
+
+	if result := removeEntities(controls, nil); len(result) != 3 {
+		t.Errorf("removeEntities with no deletions = %+v, want all controls", result)
+	}
+}
+
+// largeMOPXML returns a MOPComplete with 3000 competitors in 30 classes and 200 clubs
+// and a MOPDiff that gives the first diffSize of them a finish time
+func largeMOPXML(diffSize int) (string, string) {
+	var complete, diff strings.Builder
+	complete.WriteString(`<MOPComplete nextdifference="large1">`)
+	complete.WriteString(`<competition date="2024-01-01" organizer="Test Organizer" zerotime="10:00:00">Large Competition</competition>`)
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(&complete, `<ctrl id="%d">Control %d</ctrl>`, 101+i, i+1)
+	}
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&complete, `<cls id="%d" ord="%d" radio="101,102,103,104,105">Class %d</cls>`, i, i*10, i)
+	}
+	for i := 1; i <= 200; i++ {
+		fmt.Fprintf(&complete, `<org id="%d" nat="SWE">Club %d</org>`, i, i)
+	}
+	diff.WriteString(`<MOPDiff nextdifference="large2">`)
+	for i := 1; i <= 3000; i++ {
+		fmt.Fprintf(&complete, `<cmp id="%d" card="%d"><base org="%d" cls="%d" stat="0" st="%d" rt="">Competitor %d</base><radio>101,300;102,600</radio></cmp>`,
+			i, 100000+i, i%200+1, i%30+1, 36000+i*10, i)
+		if i <= diffSize {
+			fmt.Fprintf(&diff, `<cmp id="%d" card="%d"><base org="%d" cls="%d" stat="1" st="%d" rt="1800">Competitor %d</base><radio>101,300;102,600;103,900;104,1200;105,1500</radio></cmp>`,
+				i, 100000+i, i%200+1, i%30+1, 36000+i*10, i)
+		}
+	}
+	complete.WriteString(`</MOPComplete>`)
+	diff.WriteString(`</MOPDiff>`)
+	return complete.String(), diff.String()
+}
+
+func BenchmarkAdapter_ProcessData_LargeEventDiff(b *testing.B) {
+	discard := log.New(io.Discard, "", 0)
+	logger.InfoLogger, logger.ErrorLogger, logger.DebugLogger = discard, discard, discard
+
+	for _, diffSize := range []int{50, 1000} {
+		b.Run(fmt.Sprintf("%d_changed", diffSize), func(b *testing.B) {
+			complete, diff := largeMOPXML(diffSize)
+			adapter := NewAdapter(NewConfig(), state.New())
+			if _, err := adapter.processData([]byte(complete)); err != nil {
+				b.Fatalf("processData failed: %v", err)
+			}
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				// Reset the difference key so the same diff is applied every iteration
+				adapter.currentDifference = "large1"
+				if _, err := adapter.processData([]byte(diff)); err != nil {
+					b.Fatalf("processData failed: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -45,6 +45,7 @@ func (c Club) GetID() int {
 type Competitor struct {
 	ID         int
 	Card       int
+	Bib        string
 	Club       Club
 	Class      Class
 	Status     string
@@ -52,6 +53,8 @@ type Competitor struct {
 	FinishTime *time.Time
 	Name       string
 	Splits     []Split
+	TeamID     int // 0 when not part of a relay team
+	Leg        int // 1-based relay leg, 0 when not part of a relay team
 }
 
 func (c Competitor) GetID() int {
@@ -62,3 +65,25 @@ type Split struct {
 	Control     Control
 	PassingTime time.Time
 }
+
+type Team struct {
+	ID         int
+	Name       string
+	Bib        string
+	Club       Club
+	Class      Class
+	Status     string
+	StartTime  time.Time
+	FinishTime *time.Time
+	Legs       []Leg
+}
+
+func (t Team) GetID() int {
+	return t.ID
+}
+
+// Leg lists the competitors running one relay leg. Parallel legs have more than one runner.
+type Leg struct {
+	Number        int
+	CompetitorIDs []int
+}
@@ -0,0 +1,382 @@
+package overrides
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+// ErrCompetitorNotFound is returned when overriding a competitor the state does not have
+var ErrCompetitorNotFound = errors.New("competitor not found")
+
+// statusCodes are the MeOS status codes an override can set
+var statusCodes = map[string]bool{
+	"0": true, "1": true, "3": true, "4": true, "5": true, "6": true, "20": true, "21": true, "99": true,
+}
+
+// Override replaces parts of a competitor's data from MeOS until it is cleared. Fields
+// left unset keep the value from MeOS, including later changes to it.
+type Override struct {
+	CompetitorID   int       `json:"competitorId"`
+	Name           *string   `json:"name,omitempty"`
+	ClubID         *int      `json:"clubId,omitempty"`
+	Status         *string   `json:"status,omitempty"`         // MeOS status code, such as "5" for disqualified
+	Hidden         bool      `json:"hidden,omitempty"`         // Leaves the competitor out of every list
+	TimeAdjustment int       `json:"timeAdjustment,omitempty"` // Seconds added to the running time at the finish
+	Reason         string    `json:"reason,omitempty"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// Empty reports whether the override changes nothing
+func (o Override) Empty() bool {
+	return o.Name == nil && o.ClubID == nil && o.Status == nil && !o.Hidden && o.TimeAdjustment == 0
+}
+
+// apply returns the competitor with the override applied
+func (o Override) apply(comp models.Competitor, snap *state.Snapshot) models.Competitor {
+	if o.Name != nil {
+		comp.Name = *o.Name
+	}
+	if o.ClubID != nil {
+		if club, ok := snap.Club(*o.ClubID); ok {
+			comp.Club = club
+		}
+	}
+	if o.Status != nil {
+		comp.Status = *o.Status
+	}
+	if finish, ok := o.adjustedFinish(comp); ok {
+		comp.FinishTime = &finish
+	}
+	return comp
+}
+
+// adjustedFinish returns the competitor's finish time with the time adjustment added. It
+// reports false when there is no adjustment, the competitor has not finished, or the
+// adjusted finish would not be after the start, as when MeOS has since changed the times.
+func (o Override) adjustedFinish(comp models.Competitor) (time.Time, bool) {
+	if o.TimeAdjustment == 0 || comp.FinishTime == nil {
+		return time.Time{}, false
+	}
+	finish := comp.FinishTime.Add(time.Duration(o.TimeAdjustment) * time.Second)
+	if !comp.StartTime.IsZero() && !finish.After(comp.StartTime) {
+		return time.Time{}, false
+	}
+	return finish, true
+}
+
+// Store holds the overrides set through the admin API. They live in memory only and are
+// layered on top of the state when it is read, so the data from MeOS is never modified.
+type Store struct {
+	state *state.State
+
+	mu        sync.Mutex
+	overrides map[int]Override
+	version   uint64
+	callbacks []func(state.Change)
+
+	// The state snapshot with the overrides applied, rebuilt when either changes
+	applied        *state.Snapshot
+	appliedFrom    *state.Snapshot
+	appliedVersion uint64
+}
+
+// New creates an empty store for the competitors of the given state
+func New(appState *state.State) *Store {
+	return &Store{
+		state:     appState,
+		overrides: make(map[int]Override),
+	}
+}
+
+// OnChange registers a callback to be called with the affected classes, clubs and
+// competitors when an override is set or cleared
+func (s *Store) OnChange(callback func(state.Change)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacks = append(s.callbacks, callback)
+}
+
+// Version returns a number that increases with every change to the overrides
+func (s *Store) Version() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// List returns all overrides ordered by competitor ID
+func (s *Store) List() []Override {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Override, 0, len(s.overrides))
+	for _, o := range s.overrides {
+		list = append(list, o)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CompetitorID < list[j].CompetitorID })
+	return list
+}
+
+// Get returns the override of a competitor
+func (s *Store) Get(competitorID int) (Override, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.overrides[competitorID]
+	return o, ok
+}
+
+// Set replaces the override of a competitor. The competitor and any club it names must
+// exist in the state, the status must be a known MeOS status code, and a time adjustment
+// needs a finished competitor and must keep the finish after the start.
+func (s *Store) Set(o Override) (Override, error) {
+	snap := s.state.Snapshot()
+	comp, ok := snap.Competitor(o.CompetitorID)
+	if !ok {
+		return Override{}, ErrCompetitorNotFound
+	}
+	if o.Empty() {
+		return Override{}, fmt.Errorf("override changes nothing")
+	}
+	if o.ClubID != nil {
+		if _, ok := snap.Club(*o.ClubID); !ok {
+			return Override{}, fmt.Errorf("club %d not found", *o.ClubID)
+		}
+	}
+	if o.Status != nil && !statusCodes[*o.Status] {
+		return Override{}, fmt.Errorf("unknown status code %q", *o.Status)
+	}
+	if o.TimeAdjustment != 0 {
+		if comp.FinishTime == nil {
+			return Override{}, fmt.Errorf("competitor %d has not finished, so there is no time to adjust", comp.ID)
+		}
+		if _, ok := o.adjustedFinish(comp); !ok {
+			return Override{}, fmt.Errorf("a time adjustment of %d seconds puts the finish before the start", o.TimeAdjustment)
+		}
+	}
+	o.UpdatedAt = time.Now()
+
+	s.mu.Lock()
+	previous := s.overrides[o.CompetitorID]
+	s.overrides[o.CompetitorID] = o
+	s.version++
+	s.mu.Unlock()
+
+	s.notifyChange(snap, []models.Competitor{comp}, previous, o)
+	return o, nil
+}
+
+// Delete clears the override of a competitor and reports whether there was one
+func (s *Store) Delete(competitorID int) bool {
+	s.mu.Lock()
+	previous, ok := s.overrides[competitorID]
+	if ok {
+		delete(s.overrides, competitorID)
+		s.version++
+	}
+	s.mu.Unlock()
+
+	if ok {
+		snap := s.state.Snapshot()
+		var competitors []models.Competitor
+		if comp, found := snap.Competitor(competitorID); found {
+			competitors = append(competitors, comp)
+		}
+		s.notifyChange(snap, competitors, previous)
+	}
+	return ok
+}
+
+// Clear removes every override and returns how many there were
+func (s *Store) Clear() int {
+	s.mu.Lock()
+	cleared := make([]Override, 0, len(s.overrides))
+	for _, o := range s.overrides {
+		cleared = append(cleared, o)
+	}
+	if len(cleared) > 0 {
+		s.overrides = make(map[int]Override)
+		s.version++
+	}
+	s.mu.Unlock()
+
+	if len(cleared) > 0 {
+		snap := s.state.Snapshot()
+		competitors := make([]models.Competitor, 0, len(cleared))
+		for _, o := range cleared {
+			if comp, ok := snap.Competitor(o.CompetitorID); ok {
+				competitors = append(competitors, comp)
+			}
+		}
+		s.notifyChange(snap, competitors, cleared...)
+	}
+	return len(cleared)
+}
+
+// Apply returns the snapshot with the overrides applied: hidden competitors are left out,
+// also from the legs of their relay teams, and the others carry the overridden fields.
+// Relay teams follow the status and time overrides of their runners. Overrides of
+// competitors that are no longer in the snapshot are kept in case they come back.
+func (s *Store) Apply(snap *state.Snapshot) *state.Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.overrides) == 0 {
+		return snap
+	}
+	if s.appliedFrom == snap && s.appliedVersion == s.version {
+		return s.applied
+	}
+
+	competitors := make([]models.Competitor, 0, len(snap.Competitors()))
+	overridden := make(map[int]models.Competitor)
+	for _, comp := range snap.Competitors() {
+		o, ok := s.overrides[comp.ID]
+		switch {
+		case !ok:
+			competitors = append(competitors, comp)
+		case !o.Hidden:
+			comp = o.apply(comp, snap)
+			overridden[comp.ID] = comp
+			competitors = append(competitors, comp)
+		}
+	}
+
+	teams := make([]models.Team, 0, len(snap.Teams()))
+	for _, team := range snap.Teams() {
+		teams = append(teams, s.applyToTeam(s.withoutHidden(team), snap, overridden))
+	}
+
+	s.applied = snap.WithCompetitorsAndTeams(competitors, teams)
+	s.appliedFrom = snap
+	s.appliedVersion = s.version
+	return s.applied
+}
+
+// withoutHidden returns the team with its hidden runners left out of the legs. The caller
+// must hold the lock.
+func (s *Store) withoutHidden(team models.Team) models.Team {
+	hidden := false
+	for _, leg := range team.Legs {
+		for _, id := range leg.CompetitorIDs {
+			hidden = hidden || s.overrides[id].Hidden
+		}
+	}
+	if !hidden {
+		return team
+	}
+
+	// The legs belong to the state's snapshot, so build new ones
+	legs := make([]models.Leg, 0, len(team.Legs))
+	for _, leg := range team.Legs {
+		runners := make([]int, 0, len(leg.CompetitorIDs))
+		for _, id := range leg.CompetitorIDs {
+			if !s.overrides[id].Hidden {
+				runners = append(runners, id)
+			}
+		}
+		legs = append(legs, models.Leg{Number: leg.Number, CompetitorIDs: runners})
+	}
+	team.Legs = legs
+	return team
+}
+
+// applyToTeam returns the team with the status and time overrides of its runners applied.
+// The first runner, in leg order, overridden to a failing status fails a team that MeOS
+// has not failed, with a non-start after the first leg counting as not finished. Time
+// adjustments are added to the team's finish. The caller must hold the lock.
+func (s *Store) applyToTeam(team models.Team, snap *state.Snapshot, overridden map[int]models.Competitor) models.Team {
+	status := ""
+	var adjustment time.Duration
+	for i, leg := range team.Legs {
+		for _, id := range leg.CompetitorIDs {
+			comp, ok := overridden[id]
+			if !ok {
+				continue
+			}
+			if raw, ok := snap.Competitor(id); ok && raw.FinishTime != nil && comp.FinishTime != nil {
+				adjustment += comp.FinishTime.Sub(*raw.FinishTime)
+			}
+			if status == "" && s.overrides[id].Status != nil && failedStatus(comp.Status) {
+				status = comp.Status
+				if i > 0 && notStarted(status) {
+					status = "4"
+				}
+			}
+		}
+	}
+
+	if status != "" && !failedStatus(team.Status) {
+		team.Status = status
+	}
+	if adjustment != 0 && team.FinishTime != nil {
+		finish := team.FinishTime.Add(adjustment)
+		team.FinishTime = &finish
+	}
+	return team
+}
+
+// failedStatus reports whether a MeOS status code keeps a competitor or team out of the
+// results
+func failedStatus(status string) bool {
+	switch status {
+	case "3", "4", "5", "6", "20", "21", "99":
+		return true
+	}
+	return false
+}
+
+// notStarted reports whether a MeOS status code means the competitor never started
+func notStarted(status string) bool {
+	return status == "20" || status == "21" || status == "99"
+}
+
+// notifyChange tells the listeners which classes, clubs and competitors the given
+// overrides affected, both before and after the change
+func (s *Store) notifyChange(snap *state.Snapshot, competitors []models.Competitor, overrides ...Override) {
+	classes := map[int]bool{}
+	clubs := map[int]bool{}
+	ids := map[int]bool{}
+	for _, comp := range competitors {
+		ids[comp.ID] = true
+		classes[comp.Class.ID] = true
+		clubs[comp.Club.ID] = true
+	}
+	for _, o := range overrides {
+		if o.ClubID != nil {
+			clubs[*o.ClubID] = true
+		}
+	}
+
+	change := state.Change{
+		Revision:    snap.Revision(),
+		Classes:     sortedKeys(classes),
+		Competitors: sortedKeys(ids),
+		Clubs:       sortedKeys(clubs),
+	}
+
+	s.mu.Lock()
+	callbacks := make([]func(state.Change), len(s.callbacks))
+	copy(callbacks, s.callbacks)
+	s.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(change)
+	}
+}
+
+func sortedKeys(set map[int]bool) []int {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]int, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
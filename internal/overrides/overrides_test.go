@@ -0,0 +1,223 @@
+package overrides
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+func newTestState() *state.State {
+	appState := state.New()
+
+	class := models.Class{ID: 1, Name: "H21"}
+	clubs := []models.Club{{ID: 1, Name: "OK Pan"}, {ID: 2, Name: "OK Ravinen"}}
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	finish := start.Add(30 * time.Minute)
+
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Anna", Class: class, Club: clubs[0], Status: "1", StartTime: start, FinishTime: &finish},
+		{ID: 2, Name: "Bo", Class: class, Club: clubs[0], Status: "0", StartTime: start},
+	}
+	appState.UpdateFromMeOS(&models.Event{Name: "Test"}, nil, []models.Class{class}, clubs, competitors, nil)
+	return appState
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestStore_Apply(t *testing.T) {
+	appState := newTestState()
+	store := New(appState)
+
+	snap := appState.Snapshot()
+	if store.Apply(snap) != snap {
+		t.Error("Apply without overrides should return the snapshot itself")
+	}
+
+	if _, err := store.Set(Override{CompetitorID: 1, Name: ptr("Anna S"), ClubID: ptr(2), Status: ptr("5"), TimeAdjustment: -60}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := store.Set(Override{CompetitorID: 2, Hidden: true}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	applied := store.Apply(snap)
+	if applied.Revision() != snap.Revision() {
+		t.Errorf("Revision = %d, want %d", applied.Revision(), snap.Revision())
+	}
+	if _, ok := applied.Competitor(2); ok {
+		t.Error("Hidden competitor is still in the snapshot")
+	}
+	if got := len(applied.CompetitorsByClass(1)); got != 1 {
+		t.Errorf("Class has %d competitors, want 1", got)
+	}
+
+	comp, ok := applied.Competitor(1)
+	if !ok {
+		t.Fatal("Overridden competitor missing")
+	}
+	if comp.Name != "Anna S" || comp.Club.Name != "OK Ravinen" || comp.Status != "5" {
+		t.Errorf("Competitor = %q, %q, %q, want Anna S, OK Ravinen, 5", comp.Name, comp.Club.Name, comp.Status)
+	}
+	if got := comp.FinishTime.Sub(comp.StartTime); got != 29*time.Minute {
+		t.Errorf("Running time = %s, want 29m", got)
+	}
+
+	// The state itself is untouched
+	if raw, _ := snap.Competitor(1); raw.Name != "Anna" || raw.FinishTime.Sub(raw.StartTime) != 30*time.Minute {
+		t.Errorf("Override changed the state: %+v", raw)
+	}
+
+	if store.Apply(snap) != applied {
+		t.Error("Apply should reuse the result until the state or the overrides change")
+	}
+	store.Delete(2)
+	if _, ok := store.Apply(snap).Competitor(2); !ok {
+		t.Error("Competitor still hidden after deleting the override")
+	}
+}
+
+func TestStore_Set(t *testing.T) {
+	store := New(newTestState())
+
+	var changes []state.Change
+	store.OnChange(func(change state.Change) { changes = append(changes, change) })
+
+	tests := []struct {
+		name     string
+		override Override
+		wantErr  bool
+	}{
+		{"unknown competitor", Override{CompetitorID: 99, Hidden: true}, true},
+		{"nothing overridden", Override{CompetitorID: 1}, true},
+		{"unknown club", Override{CompetitorID: 1, ClubID: ptr(99)}, true},
+		{"unknown status", Override{CompetitorID: 1, Status: ptr("7")}, true},
+		{"finish before the start", Override{CompetitorID: 1, TimeAdjustment: -1800}, true},
+		{"adjustment without a finish", Override{CompetitorID: 2, TimeAdjustment: 60}, true},
+		{"valid", Override{CompetitorID: 1, ClubID: ptr(2), Reason: "Wrong club in MeOS"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Set(tt.override)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := store.Set(Override{CompetitorID: 99, Hidden: true}); !errors.Is(err, ErrCompetitorNotFound) {
+		t.Errorf("Set() error = %v, want ErrCompetitorNotFound", err)
+	}
+
+	if got := store.List(); len(got) != 1 || got[0].Reason != "Wrong club in MeOS" || got[0].UpdatedAt.IsZero() {
+		t.Errorf("List() = %+v", got)
+	}
+	if store.Version() != 1 {
+		t.Errorf("Version() = %d, want 1", store.Version())
+	}
+
+	// Listeners hear about the competitor's class and both its old and new club
+	if len(changes) != 1 {
+		t.Fatalf("Got %d changes, want 1", len(changes))
+	}
+	change := changes[0]
+	if len(change.Classes) != 1 || change.Classes[0] != 1 || len(change.Competitors) != 1 || change.Competitors[0] != 1 {
+		t.Errorf("Change = %+v", change)
+	}
+	if len(change.Clubs) != 2 {
+		t.Errorf("Change clubs = %v, want [1 2]", change.Clubs)
+	}
+
+	if store.Delete(2) {
+		t.Error("Delete() of a competitor without override = true")
+	}
+	if n := store.Clear(); n != 1 || len(store.List()) != 0 || len(changes) != 2 {
+		t.Errorf("Clear() = %d, %d left, %d changes", n, len(store.List()), len(changes))
+	}
+}
+
+func TestStore_ApplyHidesRelayRunners(t *testing.T) {
+	appState := state.New()
+	class := models.Class{ID: 1, Name: "Relay"}
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Anna", Class: class, Status: "0", StartTime: start, TeamID: 1, Leg: 1},
+		{ID: 2, Name: "Bo", Class: class, Status: "0", TeamID: 1, Leg: 2},
+	}
+	teams := []models.Team{{
+		ID: 1, Name: "Team", Class: class, Status: "0", StartTime: start,
+		Legs: []models.Leg{{Number: 1, CompetitorIDs: []int{1}}, {Number: 2, CompetitorIDs: []int{2}}},
+	}}
+	appState.UpdateFromMeOS(&models.Event{Name: "Test"}, nil, []models.Class{class}, nil, competitors, teams)
+
+	store := New(appState)
+	if _, err := store.Set(Override{CompetitorID: 2, Hidden: true}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	snap := appState.Snapshot()
+	team, _ := store.Apply(snap).Team(1)
+	if len(team.Legs) != 2 || len(team.Legs[0].CompetitorIDs) != 1 || len(team.Legs[1].CompetitorIDs) != 0 {
+		t.Errorf("Legs = %+v, want the hidden runner left out of leg 2", team.Legs)
+	}
+	if raw, _ := snap.Team(1); len(raw.Legs[1].CompetitorIDs) != 1 {
+		t.Errorf("Override changed the state's team: %+v", raw.Legs)
+	}
+}
+
+func TestStore_ApplyRelayTeamFollowsRunners(t *testing.T) {
+	appState := state.New()
+	class := models.Class{ID: 1, Name: "Relay"}
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	changeover := start.Add(40 * time.Minute)
+	finish := start.Add(90 * time.Minute)
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Anna", Class: class, Status: "1", StartTime: start, FinishTime: &changeover, TeamID: 1, Leg: 1},
+		{ID: 2, Name: "Bo", Class: class, Status: "1", StartTime: changeover, FinishTime: &finish, TeamID: 1, Leg: 2},
+	}
+	teams := []models.Team{{
+		ID: 1, Name: "Team", Class: class, Status: "1", StartTime: start, FinishTime: &finish,
+		Legs: []models.Leg{{Number: 1, CompetitorIDs: []int{1}}, {Number: 2, CompetitorIDs: []int{2}}},
+	}}
+	appState.UpdateFromMeOS(&models.Event{Name: "Test"}, nil, []models.Class{class}, nil, competitors, teams)
+	store := New(appState)
+
+	// A time adjustment on any leg moves the team's finish
+	if _, err := store.Set(Override{CompetitorID: 1, TimeAdjustment: 60}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	team, _ := store.Apply(appState.Snapshot()).Team(1)
+	if team.Status != "1" || team.FinishTime == nil || !team.FinishTime.Equal(finish.Add(time.Minute)) {
+		t.Errorf("Team = %s %v, want OK with the finish a minute later", team.Status, team.FinishTime)
+	}
+
+	tests := []struct {
+		name   string
+		id     int
+		status string
+		want   string
+	}{
+		{"disqualified runner", 2, "5", "5"},
+		{"not started on the first leg", 1, "20", "20"},
+		{"not started on a later leg", 2, "20", "4"},
+		{"approved runner", 2, "1", "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.Clear()
+			if _, err := store.Set(Override{CompetitorID: tt.id, Status: ptr(tt.status)}); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if team, _ := store.Apply(appState.Snapshot()).Team(1); team.Status != tt.want {
+				t.Errorf("Team status = %s, want %s", team.Status, tt.want)
+			}
+		})
+	}
+
+	if raw, _ := appState.Snapshot().Team(1); raw.Status != "1" || !raw.FinishTime.Equal(finish) {
+		t.Errorf("Override changed the state's team: %s %v", raw.Status, raw.FinishTime)
+	}
+}
@@ -0,0 +1,200 @@
+package persist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+// formatVersion is increased when the file layout changes in a way older files cannot be
+// read with
+const formatVersion = 1
+
+// File is the content of a state snapshot file
+type File struct {
+	Version  int       `json:"version"`
+	SavedAt  time.Time `json:"savedAt"`
+	Revision uint64    `json:"revision"` // State revision at the time of saving
+
+	// NextDifference is the MOP difference key to resume polling MeOS from, empty when the
+	// data did not come from polling
+	NextDifference string `json:"nextDifference,omitempty"`
+
+	Event       *models.Event       `json:"event"`
+	Controls    []models.Control    `json:"controls"`
+	Classes     []models.Class      `json:"classes"`
+	Clubs       []models.Club       `json:"clubs"`
+	Competitors []models.Competitor `json:"competitors"`
+	Teams       []models.Team       `json:"teams"`
+}
+
+// FromSnapshot captures the data of a state snapshot
+func FromSnapshot(snap *state.Snapshot, nextDifference string) File {
+	return File{
+		Version:        formatVersion,
+		SavedAt:        time.Now(),
+		Revision:       snap.Revision(),
+		NextDifference: nextDifference,
+		Event:          snap.Event(),
+		Controls:       snap.Controls(),
+		Classes:        snap.Classes(),
+		Clubs:          snap.Clubs(),
+		Competitors:    snap.Competitors(),
+		Teams:          snap.Teams(),
+	}
+}
+
+// Restore replaces the state's data with the saved data
+func (f *File) Restore(appState *state.State) {
+	appState.UpdateFromMeOS(f.Event, f.Controls, f.Classes, f.Clubs, f.Competitors, f.Teams)
+}
+
+// Save writes the file to path. It writes to a temporary file next to it first and renames
+// that over the old file, so a crash while saving never leaves a truncated snapshot behind.
+func Save(path string, file File) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(file); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load reads a snapshot file saved with Save. The error wraps os.ErrNotExist when there is
+// no file yet.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	if file.Version != formatVersion {
+		return nil, fmt.Errorf("snapshot %s has unsupported version %d", path, file.Version)
+	}
+	return &file, nil
+}
+
+// Saver periodically saves the state to a snapshot file whenever it has changed
+type Saver struct {
+	path     string
+	interval time.Duration
+	state    *state.State
+
+	mu             sync.Mutex
+	nextDifference func() string
+	savedRevision  uint64
+	savedKey       string
+	saved          bool
+
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+// NewSaver creates a saver writing the state to path at most once per interval
+func NewSaver(path string, interval time.Duration, appState *state.State) *Saver {
+	return &Saver{
+		path:     path,
+		interval: interval,
+		state:    appState,
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// TrackDifference makes the saver store the MOP difference key returned by the function
+// with every snapshot, and save again when only the key has moved on. The key must only
+// move on once its data is in the state; it is read before the state, so a snapshot is
+// never saved with a key ahead of it.
+func (s *Saver) TrackDifference(nextDifference func() string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextDifference = nextDifference
+}
+
+// MarkSaved records that the state at the given revision is already on disk, as after
+// restoring it, so it is not written again until it changes
+func (s *Saver) MarkSaved(revision uint64, nextDifference string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.savedRevision, s.savedKey, s.saved = revision, nextDifference, true
+}
+
+// Run saves the state every interval until Stop is called
+func (s *Saver) Run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if err := s.Save(); err != nil {
+				logger.ErrorLogger.Printf("Failed to save state snapshot: %v", err)
+			}
+		}
+	}
+}
+
+// Stop ends Run and saves the state one last time
+func (s *Saver) Stop() error {
+	close(s.stopChan)
+	<-s.done
+	return s.Save()
+}
+
+// Save writes the state to the snapshot file if it changed since the last save
+func (s *Saver) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Read the key first: an update applied in between only makes the snapshot newer than
+	// the key, and resuming from it fetches that update again
+	key := ""
+	if s.nextDifference != nil {
+		key = s.nextDifference()
+	}
+	snap := s.state.Snapshot()
+	if s.saved && snap.Revision() == s.savedRevision && key == s.savedKey {
+		return nil
+	}
+	// Nothing worth keeping has arrived yet, and an older snapshot may still be useful
+	if !s.saved && snap.Revision() == 0 {
+		return nil
+	}
+
+	if err := Save(s.path, FromSnapshot(snap, key)); err != nil {
+		return err
+	}
+	s.savedRevision, s.savedKey, s.saved = snap.Revision(), key, true
+	logger.DebugLogger.Printf("Saved state snapshot at revision %d to %s", snap.Revision(), s.path)
+	return nil
+}
@@ -0,0 +1,149 @@
+package persist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+func newTestState() *state.State {
+	appState := state.New()
+
+	radio := models.Control{ID: 31, Name: "Radio"}
+	class := models.Class{ID: 1, Name: "H21", RadioControls: []models.Control{radio}}
+	club := models.Club{ID: 1, Name: "OK Pan", CountryCode: "DEN"}
+	start := time.Date(2024, 5, 4, 10, 0, 0, 0, time.Local)
+	finish := start.Add(30 * time.Minute)
+
+	appState.UpdateFromMeOS(
+		&models.Event{Name: "Spring Cup", Organizer: "OK Pan", Start: start},
+		[]models.Control{radio},
+		[]models.Class{class},
+		[]models.Club{club},
+		[]models.Competitor{{
+			ID: 1, Name: "Runner", Card: 500001, Club: club, Class: class, Status: "1",
+			StartTime: start, FinishTime: &finish,
+			Splits: []models.Split{{Control: radio, PassingTime: start.Add(10 * time.Minute)}},
+		}},
+		[]models.Team{{ID: 1, Name: "Team", Club: club, Class: class, Legs: []models.Leg{{Number: 1, CompetitorIDs: []int{1}}}}},
+	)
+	return appState
+}
+
+func TestSaveAndRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	original := newTestState()
+
+	if err := Save(path, FromSnapshot(original.Snapshot(), "abc123")); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if saved.NextDifference != "abc123" {
+		t.Errorf("NextDifference = %q, want abc123", saved.NextDifference)
+	}
+
+	restored := state.New()
+	saved.Restore(restored)
+
+	if event := restored.GetEvent(); event == nil || event.Name != "Spring Cup" || !event.Start.Equal(original.GetEvent().Start) {
+		t.Errorf("Restored event = %+v, want Spring Cup", event)
+	}
+	comp := restored.GetCompetitor(1)
+	if comp == nil {
+		t.Fatal("Restored state is missing the competitor")
+	}
+	if comp.FinishTime == nil || !comp.FinishTime.Equal(*original.GetCompetitor(1).FinishTime) {
+		t.Errorf("Restored finish time = %v", comp.FinishTime)
+	}
+	if len(comp.Splits) != 1 || comp.Splits[0].Control.Name != "Radio" {
+		t.Errorf("Restored splits = %+v", comp.Splits)
+	}
+	if teams := restored.GetTeams(); len(teams) != 1 || len(teams[0].Legs) != 1 {
+		t.Errorf("Restored teams = %+v", teams)
+	}
+	if classes := restored.GetClasses(); len(classes) != 1 || len(classes[0].RadioControls) != 1 {
+		t.Errorf("Restored classes = %+v", classes)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Load(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() of a missing file error = %v, want os.ErrNotExist", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	os.WriteFile(invalid, []byte("{"), 0644)
+	if _, err := Load(invalid); err == nil {
+		t.Error("Load() of an invalid file should fail")
+	}
+
+	future := filepath.Join(dir, "future.json")
+	os.WriteFile(future, []byte(`{"version": 99}`), 0644)
+	if _, err := Load(future); err == nil {
+		t.Error("Load() of an unsupported version should fail")
+	}
+}
+
+func TestSaver(t *testing.T) {
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	appState := state.New()
+	key := "zero"
+	saver := NewSaver(path, time.Hour, appState)
+	saver.TrackDifference(func() string { return key })
+
+	// An empty state is not worth overwriting an older snapshot with
+	if err := saver.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Save() wrote the empty state, stat error = %v", err)
+	}
+
+	appState.UpdateFromMeOS(&models.Event{Name: "Spring Cup"}, nil, nil, nil, nil, nil)
+	key = "abc"
+	if err := saver.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if saved.Event == nil || saved.Event.Name != "Spring Cup" || saved.NextDifference != "abc" {
+		t.Errorf("Saved %+v, want Spring Cup at difference abc", saved)
+	}
+
+	// Unchanged state is not written again
+	os.Remove(path)
+	if err := saver.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Save() wrote an unchanged state")
+	}
+
+	// A new difference key alone is saved on stop
+	key = "def"
+	go saver.Run()
+	if err := saver.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if saved, err = Load(path); err != nil || saved.NextDifference != "def" {
+		t.Errorf("Stop() saved %+v (error %v), want difference def", saved, err)
+	}
+}
@@ -0,0 +1,192 @@
+package push
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/subtle"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/meos"
+	"meos-graphics/internal/state"
+)
+
+// Status values understood by MeOS in a MOPStatus reply
+const (
+	StatusOK     = "OK"
+	StatusBadPwd = "BADPWD"
+	StatusNoZip  = "NOZIP"
+	StatusError  = "ERROR"
+)
+
+// maxBodySize limits how much data a single push may contain
+const maxBodySize = 64 << 20
+
+// MOPStatus is the reply MeOS expects after posting MOP data
+type MOPStatus struct {
+	XMLName xml.Name `xml:"MOPStatus"`
+	Status  string   `xml:"status,attr"`
+}
+
+// Status describes the documents received from MeOS
+type Status struct {
+	Received     int        `json:"received"`
+	Rejected     int        `json:"rejected"`
+	LastReceived *time.Time `json:"lastReceived,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+}
+
+// Receiver accepts MOP documents posted by MeOS's online results module and applies them
+// through the same conversion path as the polling adapter
+type Receiver struct {
+	mop    *meos.Adapter
+	secret string
+
+	// applyMu serializes documents so diffs are applied in the order they arrive
+	applyMu sync.Mutex
+
+	mu           sync.RWMutex
+	received     int
+	rejected     int
+	lastReceived time.Time
+	lastError    string
+}
+
+// NewReceiver creates a receiver that only accepts pushes carrying the shared secret
+func NewReceiver(appState *state.State, secret string) *Receiver {
+	return &Receiver{
+		mop:    meos.NewAdapter(meos.NewConfig(), appState),
+		secret: secret,
+	}
+}
+
+// Adapter returns the MeOS adapter used to apply pushed documents
+func (r *Receiver) Adapter() *meos.Adapter {
+	return r.mop
+}
+
+// Connect does nothing as MeOS connects to us
+func (r *Receiver) Connect() error {
+	logger.InfoLogger.Println("Waiting for MeOS to push MOP data")
+	return nil
+}
+
+// StartPolling does nothing as data arrives through HandlePush
+func (r *Receiver) StartPolling() error {
+	return nil
+}
+
+// Stop does nothing as the receiver holds no background resources
+func (r *Receiver) Stop() error {
+	return nil
+}
+
+// Status returns a snapshot of the receiver's counters
+func (r *Receiver) Status() Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status := Status{
+		Received:  r.received,
+		Rejected:  r.rejected,
+		LastError: r.lastError,
+	}
+	if !r.lastReceived.IsZero() {
+		lastReceived := r.lastReceived
+		status.LastReceived = &lastReceived
+	}
+	return status
+}
+
+// HandlePush godoc
+// @Summary Receive pushed MOP data
+// @Description Accepts a MOPComplete or MOPDiff document posted by MeOS's online results module. The shared secret is passed in the pwd header. Zipped documents are supported. MeOS reads the outcome from the MOPStatus reply (OK, BADPWD, NOZIP or ERROR).
+// @Tags push
+// @Accept xml
+// @Produce xml
+// @Param pwd header string true "Shared secret"
+// @Param competition header string false "MeOS competition ID"
+// @Success 200 {object} MOPStatus
+// @Router /mop [post]
+func (r *Receiver) HandlePush(c *gin.Context) {
+	pwd := c.GetHeader("pwd")
+	if subtle.ConstantTimeCompare([]byte(pwd), []byte(r.secret)) != 1 {
+		logger.ErrorLogger.Printf("Rejected MOP push from %s: bad password", c.ClientIP())
+		r.reject("bad password")
+		c.XML(http.StatusOK, MOPStatus{Status: StatusBadPwd})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize))
+	if err != nil {
+		r.reject(err.Error())
+		c.XML(http.StatusOK, MOPStatus{Status: StatusError})
+		return
+	}
+
+	data, err := unzip(body)
+	if err != nil {
+		logger.ErrorLogger.Printf("Rejected MOP push: %v", err)
+		r.reject(err.Error())
+		c.XML(http.StatusOK, MOPStatus{Status: StatusNoZip})
+		return
+	}
+
+	r.applyMu.Lock()
+	updated, err := r.mop.Apply(data)
+	r.applyMu.Unlock()
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to apply pushed MOP data: %v", err)
+		r.reject(err.Error())
+		c.XML(http.StatusOK, MOPStatus{Status: StatusError})
+		return
+	}
+
+	r.mu.Lock()
+	r.received++
+	r.lastReceived = time.Now()
+	r.lastError = ""
+	r.mu.Unlock()
+
+	if updated {
+		logger.DebugLogger.Printf("Applied pushed MOP data (competition %s, %d bytes)", c.GetHeader("competition"), len(data))
+	}
+	c.XML(http.StatusOK, MOPStatus{Status: StatusOK})
+}
+
+func (r *Receiver) reject(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rejected++
+	r.lastError = reason
+}
+
+// unzip returns the first file of a zip archive, or the data unchanged if it is not zipped
+func unzip(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte("PK")) {
+		return data, nil
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	if len(archive.File) == 0 {
+		return nil, fmt.Errorf("empty zip archive")
+	}
+
+	file, err := archive.File[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zipped document: %w", err)
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, maxBodySize))
+}
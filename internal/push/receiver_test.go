@@ -0,0 +1,136 @@
+package push
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/state"
+	"meos-graphics/internal/testhelpers"
+)
+
+func setupReceiver(t *testing.T) (*Receiver, *state.State, *gin.Engine) {
+	t.Helper()
+
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	appState := state.New()
+	receiver := NewReceiver(appState, "s3cret")
+
+	router := gin.New()
+	router.POST("/mop", receiver.HandlePush)
+	return receiver, appState, router
+}
+
+func post(t *testing.T, router *gin.Engine, pwd string, body []byte) string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/mop", bytes.NewReader(body))
+	req.Header.Set("pwd", pwd)
+	req.Header.Set("competition", "1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var status MOPStatus
+	if err := xml.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to parse MOPStatus reply %q: %v", w.Body.String(), err)
+	}
+	return status.Status
+}
+
+func TestReceiver_AppliesPushedData(t *testing.T) {
+	receiver, appState, router := setupReceiver(t)
+
+	if got := post(t, router, "s3cret", []byte(testhelpers.MOPCompleteXML())); got != StatusOK {
+		t.Fatalf("MOPComplete push status = %s, want %s", got, StatusOK)
+	}
+	if got := len(appState.GetCompetitors()); got != 3 {
+		t.Errorf("Got %d competitors, want 3", got)
+	}
+
+	// Diffs pushed by MeOS carry no difference key and must all be applied
+	diff := strings.Replace(testhelpers.MOPDiffXML(), ` nextdifference="def456"`, "", 1)
+	if got := post(t, router, "s3cret", []byte(diff)); got != StatusOK {
+		t.Fatalf("First MOPDiff push status = %s, want %s", got, StatusOK)
+	}
+	deletion := `<?xml version="1.0" encoding="UTF-8"?><MOPDiff><cmp id="4" delete="true"/></MOPDiff>`
+	if got := post(t, router, "s3cret", []byte(deletion)); got != StatusOK {
+		t.Fatalf("Second MOPDiff push status = %s, want %s", got, StatusOK)
+	}
+
+	if got := len(appState.GetCompetitors()); got != 3 {
+		t.Errorf("Got %d competitors after diffs, want 3", got)
+	}
+	for _, competitor := range appState.GetCompetitors() {
+		if competitor.ID == 2 && competitor.Status != "1" {
+			t.Errorf("Competitor 2 status = %s, want 1", competitor.Status)
+		}
+	}
+
+	status := receiver.Status()
+	if status.Received != 3 || status.Rejected != 0 || status.LastReceived == nil {
+		t.Errorf("Unexpected receiver status: %+v", status)
+	}
+}
+
+func TestReceiver_RejectsBadPassword(t *testing.T) {
+	receiver, appState, router := setupReceiver(t)
+
+	if got := post(t, router, "wrong", []byte(testhelpers.MOPCompleteXML())); got != StatusBadPwd {
+		t.Errorf("Push status = %s, want %s", got, StatusBadPwd)
+	}
+	if got := post(t, router, "", []byte(testhelpers.MOPCompleteXML())); got != StatusBadPwd {
+		t.Errorf("Push without password status = %s, want %s", got, StatusBadPwd)
+	}
+	if appState.GetEvent() != nil {
+		t.Error("State should not be updated by rejected pushes")
+	}
+	if status := receiver.Status(); status.Rejected != 2 || status.Received != 0 {
+		t.Errorf("Unexpected receiver status: %+v", status)
+	}
+}
+
+func TestReceiver_InvalidData(t *testing.T) {
+	_, _, router := setupReceiver(t)
+
+	if got := post(t, router, "s3cret", []byte(testhelpers.InvalidXML())); got != StatusError {
+		t.Errorf("Invalid XML push status = %s, want %s", got, StatusError)
+	}
+	if got := post(t, router, "s3cret", []byte("PK not really a zip")); got != StatusNoZip {
+		t.Errorf("Broken zip push status = %s, want %s", got, StatusNoZip)
+	}
+}
+
+func TestReceiver_ZippedData(t *testing.T) {
+	_, appState, router := setupReceiver(t)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	file, err := archive.Create("mop.xml")
+	if err != nil {
+		t.Fatalf("Failed to create zip entry: %v", err)
+	}
+	file.Write([]byte(testhelpers.MOPCompleteXML()))
+	archive.Close()
+
+	if got := post(t, router, "s3cret", buf.Bytes()); got != StatusOK {
+		t.Fatalf("Zipped push status = %s, want %s", got, StatusOK)
+	}
+	if event := appState.GetEvent(); event == nil || event.Name != "Test Competition" {
+		t.Error("Zipped MOPComplete should be applied")
+	}
+}
@@ -0,0 +1,204 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/meos"
+	"meos-graphics/internal/state"
+)
+
+// Status describes how far a replay has progressed
+type Status struct {
+	Journal    string     `json:"journal"`
+	Speed      float64    `json:"speed"`
+	Position   int        `json:"position"`
+	Total      int        `json:"total"`
+	RecordedAt *time.Time `json:"recordedAt,omitempty"` // Recording time of the last applied entry
+	Finished   bool       `json:"finished"`
+}
+
+// Adapter feeds a recorded MOP journal back through the MeOS conversion path. It also
+// keeps the replayed time, which starts at the recording time of the first entry and runs
+// at the replay speed, so anything that compares against the clock sees the race as it
+// was recorded.
+type Adapter struct {
+	path    string
+	speed   float64
+	mop     *meos.Adapter
+	entries []meos.JournalEntry
+
+	mu       sync.RWMutex
+	position int
+	started  time.Time // When the first entry was applied
+	playing  bool
+	stopChan chan struct{}
+	done     chan struct{} // Closed when the playback goroutine has exited
+}
+
+// NewAdapter creates a replay of the journal at path. A speed of 1 replays in real time,
+// higher values replay faster.
+func NewAdapter(path string, speed float64, appState *state.State) *Adapter {
+	return &Adapter{
+		path:     path,
+		speed:    speed,
+		mop:      meos.NewAdapter(meos.NewConfig(), appState),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Connect loads the journal and applies its first entry
+func (a *Adapter) Connect() error {
+	if a.speed <= 0 {
+		return fmt.Errorf("replay speed must be positive: %g", a.speed)
+	}
+
+	file, err := os.Open(a.path)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	entries, err := meos.ReadJournal(file)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("journal %s is empty", a.path)
+	}
+
+	a.mu.Lock()
+	a.entries = entries
+	a.position = 0
+	a.started = time.Now()
+	a.mu.Unlock()
+
+	logger.InfoLogger.Printf("Loaded %d journal entries recorded from %s to %s",
+		len(entries), entries[0].Time.Format(time.RFC3339), entries[len(entries)-1].Time.Format(time.RFC3339))
+
+	a.applyNext()
+	return nil
+}
+
+// StartPolling applies the remaining entries with the recorded delays divided by the speed
+func (a *Adapter) StartPolling() error {
+	a.mu.Lock()
+	if a.playing {
+		a.mu.Unlock()
+		return fmt.Errorf("replay already running")
+	}
+	if len(a.entries) == 0 {
+		a.mu.Unlock()
+		return fmt.Errorf("no journal loaded")
+	}
+	a.playing = true
+	a.done = make(chan struct{})
+	done := a.done
+	a.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		for {
+			delay, ok := a.nextDelay()
+			if !ok {
+				logger.InfoLogger.Println("Replay finished")
+				return
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-a.stopChan:
+				timer.Stop()
+				return
+			case <-timer.C:
+				a.applyNext()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop halts the replay and waits for the entry being applied, if any
+func (a *Adapter) Stop() error {
+	a.mu.Lock()
+	if !a.playing {
+		a.mu.Unlock()
+		return nil
+	}
+	close(a.stopChan)
+	a.playing = false
+	done := a.done
+	a.mu.Unlock()
+
+	// The playback goroutine takes the lock to apply entries, so wait without holding it
+	<-done
+	return nil
+}
+
+// Now returns the replayed time: the recording time of the first entry plus the time
+// since it was applied, multiplied by the speed. Before the journal is loaded it is the
+// current time.
+func (a *Adapter) Now() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if len(a.entries) == 0 {
+		return time.Now()
+	}
+	return a.entries[0].Time.Add(time.Duration(float64(time.Since(a.started)) * a.speed))
+}
+
+// Status returns the current replay progress
+func (a *Adapter) Status() Status {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	status := Status{
+		Journal:  a.path,
+		Speed:    a.speed,
+		Position: a.position,
+		Total:    len(a.entries),
+		Finished: len(a.entries) > 0 && a.position >= len(a.entries),
+	}
+	if a.position > 0 {
+		recordedAt := a.entries[a.position-1].Time
+		status.RecordedAt = &recordedAt
+	}
+	return status
+}
+
+// nextDelay returns how long to wait before the next entry is due, which is when the
+// replayed time reaches its recording time
+func (a *Adapter) nextDelay() (time.Duration, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.position == 0 || a.position >= len(a.entries) {
+		return 0, false
+	}
+	offset := a.entries[a.position].Time.Sub(a.entries[0].Time)
+	due := a.started.Add(time.Duration(float64(offset) / a.speed))
+	return max(time.Until(due), 0), true
+}
+
+func (a *Adapter) applyNext() {
+	a.mu.Lock()
+	if a.position >= len(a.entries) {
+		a.mu.Unlock()
+		return
+	}
+	entry := a.entries[a.position]
+	a.position++
+	position := a.position
+	a.mu.Unlock()
+
+	if _, err := a.mop.Apply([]byte(entry.Data)); err != nil {
+		logger.ErrorLogger.Printf("Failed to apply journal entry %d (recorded %s): %v",
+			position, entry.Time.Format(time.RFC3339), err)
+	}
+}
@@ -0,0 +1,200 @@
+package replay
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"meos-graphics/internal/logger"
+	"meos-graphics/internal/meos"
+	"meos-graphics/internal/state"
+	"meos-graphics/internal/testhelpers"
+)
+
+// writeJournal records the given responses one recorded second apart
+func writeJournal(t *testing.T, responses ...string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "race.journal")
+	journal, err := meos.OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal() error = %v", err)
+	}
+	defer journal.Close()
+
+	recorded := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, response := range responses {
+		if err := journal.Write(recorded, []byte(response)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		recorded = recorded.Add(time.Second)
+	}
+	return path
+}
+
+func TestAdapter_Replay(t *testing.T) {
+	// Initialize logger for tests
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	path := writeJournal(t, testhelpers.MOPCompleteXML(), testhelpers.MOPDiffXML())
+	appState := state.New()
+
+	// One recorded second takes 10ms at 100x speed
+	adapter := NewAdapter(path, 100, appState)
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	// The first entry is applied immediately
+	if got := len(appState.GetCompetitors()); got != 3 {
+		t.Fatalf("Got %d competitors after Connect(), want 3", got)
+	}
+	status := adapter.Status()
+	if status.Position != 1 || status.Total != 2 || status.Finished {
+		t.Errorf("Status after Connect() = %+v", status)
+	}
+
+	if err := adapter.StartPolling(); err != nil {
+		t.Fatalf("StartPolling() error = %v", err)
+	}
+	defer adapter.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !adapter.Status().Finished && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	status = adapter.Status()
+	if !status.Finished {
+		t.Fatalf("Replay did not finish, status = %+v", status)
+	}
+	if status.RecordedAt == nil || !status.RecordedAt.Equal(time.Date(2024, 1, 1, 10, 0, 1, 0, time.UTC)) {
+		t.Errorf("RecordedAt = %v, want time of last entry", status.RecordedAt)
+	}
+	if got := len(appState.GetCompetitors()); got != 4 {
+		t.Errorf("Got %d competitors after replay, want 4", got)
+	}
+}
+
+func TestAdapter_ReplaySkipsUnchanged(t *testing.T) {
+	// Initialize logger for tests
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	// MeOS answers with the same document until something changes
+	path := writeJournal(t, testhelpers.MOPCompleteXML(), testhelpers.MOPCompleteXML(), testhelpers.MOPDiffXML())
+	appState := state.New()
+
+	adapter := NewAdapter(path, 100, appState)
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	revision := appState.Snapshot().Revision()
+
+	adapter.applyNext()
+	if got := appState.Snapshot().Revision(); got != revision {
+		t.Errorf("Unchanged entry moved the revision from %d to %d", revision, got)
+	}
+
+	adapter.applyNext()
+	if got := len(appState.GetCompetitors()); got != 4 {
+		t.Errorf("Got %d competitors after replay, want 4", got)
+	}
+}
+
+func TestAdapter_ReplayRespectsSpeed(t *testing.T) {
+	// Initialize logger for tests
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	path := writeJournal(t, testhelpers.MOPCompleteXML(), testhelpers.MOPDiffXML())
+
+	// At real time the diff is due one second after the first entry
+	adapter := NewAdapter(path, 1, state.New())
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if err := adapter.StartPolling(); err != nil {
+		t.Fatalf("StartPolling() error = %v", err)
+	}
+	defer adapter.Stop()
+
+	time.Sleep(200 * time.Millisecond)
+	if status := adapter.Status(); status.Position != 1 {
+		t.Errorf("Position after 200ms = %d, want 1", status.Position)
+	}
+}
+
+func TestAdapter_ConnectErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		speed float64
+	}{
+		{"missing file", filepath.Join(t.TempDir(), "missing.journal"), 1},
+		{"empty journal", writeJournal(t), 1},
+		{"zero speed", writeJournal(t, testhelpers.MOPCompleteXML()), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewAdapter(tt.path, tt.speed, state.New()).Connect(); err == nil {
+				t.Error("Connect() should fail")
+			}
+		})
+	}
+}
+
+func TestAdapter_Now(t *testing.T) {
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	path := writeJournal(t, testhelpers.MOPCompleteXML(), testhelpers.MOPDiffXML())
+	adapter := NewAdapter(path, 60, state.New())
+	if before := adapter.Now(); time.Since(before) > time.Minute {
+		t.Errorf("Now() before Connect() = %v, want the current time", before)
+	}
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	// The replayed time starts at the first recording and runs a minute per second
+	recorded := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	time.Sleep(100 * time.Millisecond)
+	if elapsed := adapter.Now().Sub(recorded); elapsed < 6*time.Second || elapsed > 30*time.Second {
+		t.Errorf("Replayed time after 100ms = %v after the recording, want about 6s", elapsed)
+	}
+}
+
+func TestAdapter_StopWaitsForPlayback(t *testing.T) {
+	if err := logger.Init(); err != nil {
+		t.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	path := writeJournal(t, testhelpers.MOPCompleteXML(), testhelpers.MOPDiffXML())
+	adapter := NewAdapter(path, 1, state.New())
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if err := adapter.StartPolling(); err != nil {
+		t.Fatalf("StartPolling() error = %v", err)
+	}
+
+	done := adapter.done
+	if err := adapter.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	select {
+	case <-done:
+	default:
+		t.Error("Stop() returned before the playback goroutine exited")
+	}
+	if err := adapter.Stop(); err != nil {
+		t.Errorf("Second Stop() error = %v", err)
+	}
+}
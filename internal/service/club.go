@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"meos-graphics/internal/models"
+)
+
+// ClubInfo represents a club and how many competitors it has entered
+type ClubInfo struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Country     string `json:"country,omitempty"`
+	Competitors int    `json:"competitors"`
+}
+
+// ClubCompetitor represents a club member's current status and result in their class
+type ClubCompetitor struct {
+	CompetitorID int    `json:"competitorId"`
+	Name         string `json:"name"`
+	Bib          string `json:"bib,omitempty"`
+	ClassID      int    `json:"classId"`
+	Class        string `json:"class"`
+	Status       string `json:"status"`              // Status description, including running and waiting to start
+	StartTime    string `json:"startTime,omitempty"` // Formatted as HH:mm:ss
+	RunningTime  string `json:"runningTime,omitempty"`
+	Position     int    `json:"position,omitempty"`   // Position in the class results
+	Difference   string `json:"difference,omitempty"` // Formatted duration from the winner
+	Overridden   bool   `json:"overridden,omitempty"` // Set when an admin has overridden the competitor's data
+}
+
+// ClubDetail represents a club with all of its competitors across the classes
+type ClubDetail struct {
+	ID          int              `json:"id"`
+	Name        string           `json:"name"`
+	Country     string           `json:"country,omitempty"`
+	Competitors []ClubCompetitor `json:"competitors"`
+}
+
+// GetClubs returns all clubs with at least one competitor, sorted by name
+func (s *Service) GetClubs() []ClubInfo {
+	snap := s.snapshot()
+
+	entries := make(map[int]int)
+	for _, comp := range snap.Competitors() {
+		entries[comp.Club.ID]++
+	}
+
+	clubs := []ClubInfo{}
+	for _, club := range snap.Clubs() {
+		if entries[club.ID] == 0 {
+			continue
+		}
+		clubs = append(clubs, ClubInfo{
+			ID:          club.ID,
+			Name:        club.Name,
+			Country:     club.CountryCode,
+			Competitors: entries[club.ID],
+		})
+	}
+
+	sort.Slice(clubs, func(i, j int) bool {
+		if clubs[i].Name == clubs[j].Name {
+			return clubs[i].ID < clubs[j].ID
+		}
+		return clubs[i].Name < clubs[j].Name
+	})
+	return clubs
+}
+
+// GetClub returns a club with its competitors, sorted by class order key and then by
+// position, with the competitors without a result by start time
+func (s *Service) GetClub(clubID int) (*ClubDetail, error) {
+	snap := s.snapshot()
+	club, ok := snap.Club(clubID)
+	if !ok {
+		return nil, fmt.Errorf("club not found")
+	}
+	now := s.Now()
+
+	type member struct {
+		entry    ClubCompetitor
+		orderKey int
+		start    time.Time
+	}
+	var members []member
+
+	for _, comp := range snap.Competitors() {
+		if comp.Club.ID != clubID {
+			continue
+		}
+		class, _ := snap.Class(comp.Class.ID)
+
+		entry := ClubCompetitor{
+			CompetitorID: comp.ID,
+			Name:         comp.Name,
+			Bib:          comp.Bib,
+			ClassID:      comp.Class.ID,
+			Class:        comp.Class.Name,
+			Status:       statusDescription(comp, now),
+			Overridden:   s.overridden(comp.ID),
+		}
+		if class.Name != "" {
+			entry.Class = class.Name
+		}
+		if !comp.StartTime.IsZero() {
+			entry.StartTime = comp.StartTime.Format("15:04:05")
+		}
+		if comp.FinishTime != nil {
+			entry.RunningTime = FormatDuration(comp.FinishTime.Sub(comp.StartTime))
+		}
+		entry.Position, entry.Difference = finishPosition(comp, snap.CompetitorsByClass(comp.Class.ID))
+
+		members = append(members, member{entry: entry, orderKey: class.OrderKey, start: comp.StartTime})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if a.orderKey != b.orderKey {
+			return a.orderKey < b.orderKey
+		}
+		if a.entry.ClassID != b.entry.ClassID {
+			return a.entry.ClassID < b.entry.ClassID
+		}
+		if (a.entry.Position == 0) != (b.entry.Position == 0) {
+			return a.entry.Position != 0
+		}
+		if a.entry.Position != b.entry.Position {
+			return a.entry.Position < b.entry.Position
+		}
+		if !a.start.Equal(b.start) {
+			return a.start.Before(b.start)
+		}
+		return a.entry.Name < b.entry.Name
+	})
+
+	detail := &ClubDetail{
+		ID:          club.ID,
+		Name:        club.Name,
+		Country:     club.CountryCode,
+		Competitors: make([]ClubCompetitor, len(members)),
+	}
+	for i, m := range members {
+		detail.Competitors[i] = m.entry
+	}
+	return detail, nil
+}
+
+// finishPosition returns a competitor's position among the approved finishers of their
+// class, shared on equal times, and the time behind the winner. The position is 0 for
+// competitors without an approved finish.
+func finishPosition(comp models.Competitor, competitors []models.Competitor) (int, string) {
+	runTime, ok := elapsedAt(comp, finishControlID)
+	if !ok {
+		return 0, ""
+	}
+
+	position := 1
+	best := runTime
+	for _, other := range competitors {
+		if t, ok := elapsedAt(other, finishControlID); ok {
+			if t < runTime {
+				position++
+			}
+			best = min(best, t)
+		}
+	}
+	if position == 1 {
+		return position, ""
+	}
+	return position, "+" + FormatDuration(runTime-best)
+}
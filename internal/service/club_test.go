@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+func setupClubState(now time.Time) *state.State {
+	appState := state.New()
+
+	elite := models.Class{ID: 1, Name: "H21", OrderKey: 1}
+	junior := models.Class{ID: 2, Name: "H16", OrderKey: 2}
+	pan := models.Club{ID: 1, Name: "OK Pan", CountryCode: "DEN"}
+	other := models.Club{ID: 2, Name: "Another Club"}
+	empty := models.Club{ID: 3, Name: "Empty Club"}
+
+	start := now.Add(-time.Hour)
+	fast := start.Add(30 * time.Minute)
+	slow := start.Add(35 * time.Minute)
+	slowest := start.Add(40 * time.Minute)
+
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Winner", Class: elite, Club: other, Status: "1", StartTime: start, FinishTime: &fast},
+		{ID: 2, Name: "Second", Class: elite, Club: pan, Status: "1", StartTime: start, FinishTime: &slow},
+		{ID: 3, Name: "Third", Class: elite, Club: pan, Status: "1", StartTime: start, FinishTime: &slowest},
+		{ID: 4, Name: "Later", Class: elite, Club: pan, Status: "0", StartTime: now.Add(time.Hour)},
+		{ID: 5, Name: "Mispunch", Class: junior, Club: pan, Status: "3", StartTime: start, FinishTime: &fast},
+	}
+
+	appState.UpdateFromMeOS(nil, nil, []models.Class{elite, junior}, []models.Club{pan, other, empty}, competitors, nil)
+	return appState
+}
+
+func TestGetClubs(t *testing.T) {
+	svc := New(setupClubState(time.Now()))
+
+	clubs := svc.GetClubs()
+	require.Len(t, clubs, 2)
+	assert.Equal(t, "Another Club", clubs[0].Name)
+	assert.Equal(t, 1, clubs[0].Competitors)
+	assert.Equal(t, "OK Pan", clubs[1].Name)
+	assert.Equal(t, "DEN", clubs[1].Country)
+	assert.Equal(t, 4, clubs[1].Competitors)
+}
+
+func TestGetClub(t *testing.T) {
+	svc := New(setupClubState(time.Now()))
+
+	club, err := svc.GetClub(1)
+	require.NoError(t, err)
+	assert.Equal(t, "OK Pan", club.Name)
+	require.Len(t, club.Competitors, 4)
+
+	// Sorted by class, then by position with those without a result last
+	assert.Equal(t, "Second", club.Competitors[0].Name)
+	assert.Equal(t, 2, club.Competitors[0].Position)
+	assert.Equal(t, "+5:00.0", club.Competitors[0].Difference)
+	assert.Equal(t, "35:00.0", club.Competitors[0].RunningTime)
+	assert.Equal(t, "Third", club.Competitors[1].Name)
+	assert.Equal(t, 3, club.Competitors[1].Position)
+	assert.Equal(t, "Later", club.Competitors[2].Name)
+	assert.Zero(t, club.Competitors[2].Position)
+	assert.Equal(t, "Waiting to Start", club.Competitors[2].Status)
+	assert.Equal(t, "H16", club.Competitors[3].Class)
+	assert.Zero(t, club.Competitors[3].Position)
+
+	_, err = svc.GetClub(999)
+	assert.Error(t, err)
+}
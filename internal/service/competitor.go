@@ -0,0 +1,157 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"meos-graphics/internal/i18n"
+	"meos-graphics/internal/models"
+)
+
+// CompetitorSplit represents a competitor's time at one radio control or the finish
+type CompetitorSplit struct {
+	ControlID      int    `json:"controlId"` // -1 for the finish
+	ControlName    string `json:"controlName"`
+	PassingTime    string `json:"passingTime"` // Clock time, formatted as HH:mm:ss
+	ElapsedTime    string `json:"elapsedTime"`
+	Position       int    `json:"position,omitempty"`       // Rank at the control
+	TimeDifference string `json:"timeDifference,omitempty"` // Formatted duration from the best time at the control
+	LegTime        string `json:"legTime,omitempty"`        // Time from the previous control, or the start for the first control
+	LegPosition    int    `json:"legPosition,omitempty"`
+	LegDifference  string `json:"legDifference,omitempty"` // Time lost to the best leg time
+	BestLeg        bool   `json:"bestLeg,omitempty"`
+}
+
+// CompetitorDetail represents everything known about a single competitor
+type CompetitorDetail struct {
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	Bib         string            `json:"bib,omitempty"`
+	Card        int               `json:"card,omitempty"`
+	ClubID      int               `json:"clubId"`
+	Club        string            `json:"club"`
+	ClassID     int               `json:"classId"`
+	Class       string            `json:"class"`
+	Status      string            `json:"status"`               // Status description, including running and waiting to start
+	StartTime   string            `json:"startTime,omitempty"`  // Formatted as HH:mm:ss
+	FinishTime  string            `json:"finishTime,omitempty"` // Formatted as HH:mm:ss
+	RunningTime string            `json:"runningTime,omitempty"`
+	Position    int               `json:"position,omitempty"`   // Current position in the class results
+	Difference  string            `json:"difference,omitempty"` // Formatted duration from the winner
+	Splits      []CompetitorSplit `json:"splits"`
+	Prediction  *Prediction       `json:"prediction,omitempty"` // Predicted finish while running
+	Overridden  bool              `json:"overridden,omitempty"` // Set when an admin has overridden the competitor's data
+}
+
+// GetCompetitor returns the details of a single competitor, with the splits ranked within
+// the class
+func (s *Service) GetCompetitor(competitorID int) (*CompetitorDetail, error) {
+	snap := s.snapshot()
+	comp, ok := snap.Competitor(competitorID)
+	if !ok {
+		return nil, fmt.Errorf("competitor not found")
+	}
+	now := s.Now()
+
+	detail := &CompetitorDetail{
+		ID:         comp.ID,
+		Name:       comp.Name,
+		Bib:        comp.Bib,
+		Card:       comp.Card,
+		ClubID:     comp.Club.ID,
+		Club:       comp.Club.Name,
+		ClassID:    comp.Class.ID,
+		Class:      comp.Class.Name,
+		Status:     statusDescription(comp, now),
+		Splits:     []CompetitorSplit{},
+		Overridden: s.overridden(comp.ID),
+	}
+	if !comp.StartTime.IsZero() {
+		detail.StartTime = comp.StartTime.Format("15:04:05")
+	}
+	if comp.FinishTime != nil {
+		detail.FinishTime = comp.FinishTime.Format("15:04:05")
+		detail.RunningTime = FormatDuration(comp.FinishTime.Sub(comp.StartTime))
+	}
+
+	class, ok := snap.Class(comp.Class.ID)
+	if !ok {
+		return detail, nil
+	}
+	competitors := snap.CompetitorsByClass(class.ID)
+
+	detail.Position, detail.Difference = finishPosition(comp, competitors)
+
+	if p, ok := predictClass(class, competitors, now)[comp.ID]; ok {
+		detail.Prediction = &p.Prediction
+	}
+
+	// A class without a name has no splits, as with GetSplits
+	if class.Name == "" {
+		return detail, nil
+	}
+	detail.Class = class.Name
+
+	for _, standing := range s.classSplits(snap, class).Splits {
+		for _, entry := range standing.Standings {
+			if entry.CompetitorID != comp.ID || entry.ElapsedTime == nil {
+				continue
+			}
+			split := CompetitorSplit{
+				ControlID:      standing.ControlID,
+				ControlName:    standing.ControlName,
+				ElapsedTime:    *entry.ElapsedTime,
+				Position:       entry.Position,
+				TimeDifference: valueOf(entry.TimeDifference),
+				LegTime:        valueOf(entry.LegTime),
+				LegPosition:    entry.LegPosition,
+				LegDifference:  valueOf(entry.LegDifference),
+				BestLeg:        entry.BestLeg,
+			}
+			if passing, ok := passingTime(comp, standing.ControlID); ok {
+				split.PassingTime = passing.Format("15:04:05")
+			}
+			detail.Splits = append(detail.Splits, split)
+			break
+		}
+	}
+
+	return detail, nil
+}
+
+// statusDescription describes a competitor's status, telling competitors who are waiting
+// to start from those out on the course
+func statusDescription(comp models.Competitor, now time.Time) string {
+	switch {
+	case (comp.Status == "0" || comp.Status == "20") && !comp.StartTime.IsZero() && now.Before(comp.StartTime):
+		return i18n.GetInstance().GetStatusDescription("1000")
+	case running(comp, now):
+		return i18n.GetInstance().GetStatusDescription("1001")
+	default:
+		return i18n.GetInstance().GetStatusDescription(comp.Status)
+	}
+}
+
+// passingTime returns when the competitor passed a radio control, or finished for
+// finishControlID
+func passingTime(comp models.Competitor, controlID int) (time.Time, bool) {
+	if controlID == finishControlID {
+		if comp.FinishTime == nil {
+			return time.Time{}, false
+		}
+		return *comp.FinishTime, true
+	}
+	for _, split := range comp.Splits {
+		if split.Control.ID == controlID {
+			return split.PassingTime, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCompetitor(t *testing.T) {
+	svc := New(setupSpeakerState(time.Now()))
+
+	leader, err := svc.GetCompetitor(1)
+	require.NoError(t, err)
+	assert.Equal(t, "Leader", leader.Name)
+	assert.Equal(t, "Club A", leader.Club)
+	assert.Equal(t, "H21", leader.Class)
+	assert.Equal(t, "30:00.0", leader.RunningTime)
+	assert.Equal(t, 1, leader.Position)
+	assert.Empty(t, leader.Difference)
+	assert.Nil(t, leader.Prediction)
+
+	require.Len(t, leader.Splits, 2)
+	assert.Equal(t, "Radio", leader.Splits[0].ControlName)
+	assert.Equal(t, "10:00.0", leader.Splits[0].ElapsedTime)
+	assert.Equal(t, 1, leader.Splits[0].Position)
+	assert.True(t, leader.Splits[0].BestLeg)
+	assert.Equal(t, finishControlID, leader.Splits[1].ControlID)
+	assert.Equal(t, "20:00.0", leader.Splits[1].LegTime)
+	assert.Equal(t, leader.FinishTime, leader.Splits[1].PassingTime)
+}
+
+func TestGetCompetitor_Running(t *testing.T) {
+	svc := New(setupSpeakerState(time.Now()))
+
+	slow, err := svc.GetCompetitor(2)
+	require.NoError(t, err)
+	assert.Equal(t, "Running", slow.Status)
+	assert.Zero(t, slow.Position)
+	assert.Empty(t, slow.RunningTime)
+
+	require.Len(t, slow.Splits, 1)
+	assert.Equal(t, 2, slow.Splits[0].Position)
+	assert.Equal(t, "+2:00.0", slow.Splits[0].TimeDifference)
+	assert.Equal(t, 2, slow.Splits[0].LegPosition)
+
+	require.NotNil(t, slow.Prediction)
+	assert.Equal(t, "36:00.0", slow.Prediction.PredictedTime)
+	assert.Equal(t, 2, slow.Prediction.VirtualPosition)
+
+	waiting, err := svc.GetCompetitor(4)
+	require.NoError(t, err)
+	assert.Equal(t, "Waiting to Start", waiting.Status)
+	assert.Empty(t, waiting.Splits)
+}
+
+func TestGetCompetitor_UnnamedClass(t *testing.T) {
+	appState := setupSpeakerState(time.Now())
+	appState.Lock()
+	for i := range appState.Classes {
+		if appState.Classes[i].ID == 1 {
+			appState.Classes[i].Name = ""
+		}
+	}
+	appState.Unlock()
+
+	// A class without a name has no splits, but the competitor is still found
+	leader, err := New(appState).GetCompetitor(1)
+	require.NoError(t, err)
+	assert.Equal(t, "Leader", leader.Name)
+	assert.Equal(t, 1, leader.Position)
+	assert.Empty(t, leader.Splits)
+}
+
+func TestGetCompetitor_NotFound(t *testing.T) {
+	svc := New(setupSpeakerState(time.Now()))
+
+	_, err := svc.GetCompetitor(999)
+	assert.Error(t, err)
+}
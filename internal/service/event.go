@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"meos-graphics/internal/models"
+)
+
+// EventInfo represents the event details
+type EventInfo struct {
+	Name        string `json:"name"`
+	Organizer   string `json:"organizer,omitempty"`
+	Date        string `json:"date,omitempty"`      // Formatted as YYYY-MM-DD
+	StartTime   string `json:"startTime,omitempty"` // Formatted as HH:mm:ss
+	Classes     int    `json:"classes"`
+	Clubs       int    `json:"clubs"`
+	Competitors int    `json:"competitors"`
+}
+
+// ControlClass represents a class using a radio control
+type ControlClass struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// ControlInfo represents a radio control, how many competitors have passed it and which
+// classes use it
+type ControlInfo struct {
+	ID       int            `json:"id"`
+	Name     string         `json:"name"`
+	Passings int            `json:"passings"`
+	Classes  []ControlClass `json:"classes"` // Sorted by order key
+}
+
+// GetEvent returns the event details with the number of classes, clubs and competitors
+func (s *Service) GetEvent() (*EventInfo, error) {
+	snap := s.snapshot()
+	event := snap.Event()
+	if event == nil {
+		return nil, fmt.Errorf("event not found")
+	}
+
+	info := &EventInfo{
+		Name:        event.Name,
+		Organizer:   event.Organizer,
+		Classes:     len(snap.Classes()),
+		Clubs:       len(snap.Clubs()),
+		Competitors: len(snap.Competitors()),
+	}
+	if !event.Start.IsZero() {
+		info.Date = event.Start.Format("2006-01-02")
+		info.StartTime = event.Start.Format("15:04:05")
+	}
+	return info, nil
+}
+
+// GetControls returns all radio controls sorted by ID, including those only listed in a
+// class's course
+func (s *Service) GetControls() []ControlInfo {
+	snap := s.snapshot()
+
+	controls := make(map[int]*ControlInfo)
+	for _, ctrl := range snap.Controls() {
+		controls[ctrl.ID] = &ControlInfo{ID: ctrl.ID, Name: ctrl.Name, Classes: []ControlClass{}}
+	}
+
+	classes := append([]models.Class{}, snap.Classes()...)
+	sort.SliceStable(classes, func(i, j int) bool { return classes[i].OrderKey < classes[j].OrderKey })
+	for _, class := range classes {
+		// Relay classes list the radio controls of every leg, so a control can come up more
+		// than once
+		seen := make(map[int]bool)
+		for _, ctrl := range class.RadioControls {
+			if seen[ctrl.ID] {
+				continue
+			}
+			seen[ctrl.ID] = true
+
+			info, ok := controls[ctrl.ID]
+			if !ok {
+				info = &ControlInfo{ID: ctrl.ID, Name: ctrl.Name, Classes: []ControlClass{}}
+				controls[ctrl.ID] = info
+			}
+			info.Classes = append(info.Classes, ControlClass{ID: class.ID, Name: class.Name})
+		}
+	}
+
+	for _, comp := range snap.Competitors() {
+		for _, split := range comp.Splits {
+			if info, ok := controls[split.Control.ID]; ok {
+				info.Passings++
+			}
+		}
+	}
+
+	result := make([]ControlInfo, 0, len(controls))
+	for _, info := range controls {
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+func TestGetEvent(t *testing.T) {
+	appState := state.New()
+	svc := New(appState)
+
+	_, err := svc.GetEvent()
+	assert.Error(t, err)
+
+	event := &models.Event{Name: "Spring Cup", Organizer: "OK Pan", Start: time.Date(2024, 5, 4, 10, 30, 0, 0, time.Local)}
+	club := models.Club{ID: 1, Name: "OK Pan"}
+	class := models.Class{ID: 1, Name: "H21"}
+	appState.UpdateFromMeOS(event, nil, []models.Class{class}, []models.Club{club},
+		[]models.Competitor{{ID: 1, Name: "Runner", Class: class, Club: club}}, nil)
+
+	info, err := svc.GetEvent()
+	require.NoError(t, err)
+	assert.Equal(t, "Spring Cup", info.Name)
+	assert.Equal(t, "OK Pan", info.Organizer)
+	assert.Equal(t, "2024-05-04", info.Date)
+	assert.Equal(t, "10:30:00", info.StartTime)
+	assert.Equal(t, 1, info.Classes)
+	assert.Equal(t, 1, info.Clubs)
+	assert.Equal(t, 1, info.Competitors)
+}
+
+func TestGetControls(t *testing.T) {
+	appState := state.New()
+
+	shared := models.Control{ID: 31, Name: "Radio 1"}
+	long := models.Control{ID: 32, Name: "Radio 2"}
+	unused := models.Control{ID: 40, Name: "Spare"}
+	h21 := models.Class{ID: 1, Name: "H21", OrderKey: 2, RadioControls: []models.Control{shared, long}}
+	d21 := models.Class{ID: 2, Name: "D21", OrderKey: 1, RadioControls: []models.Control{shared}}
+
+	start := time.Now().Add(-time.Hour)
+	competitors := []models.Competitor{
+		{ID: 1, Class: h21, StartTime: start, Splits: []models.Split{
+			{Control: shared, PassingTime: start.Add(10 * time.Minute)},
+			{Control: long, PassingTime: start.Add(20 * time.Minute)},
+		}},
+		{ID: 2, Class: d21, StartTime: start, Splits: []models.Split{
+			{Control: shared, PassingTime: start.Add(12 * time.Minute)},
+		}},
+	}
+	appState.UpdateFromMeOS(nil, []models.Control{shared, long, unused}, []models.Class{h21, d21}, nil, competitors, nil)
+
+	controls := New(appState).GetControls()
+	require.Len(t, controls, 3)
+
+	assert.Equal(t, "Radio 1", controls[0].Name)
+	assert.Equal(t, 2, controls[0].Passings)
+	assert.Equal(t, []ControlClass{{ID: 2, Name: "D21"}, {ID: 1, Name: "H21"}}, controls[0].Classes)
+
+	assert.Equal(t, 1, controls[1].Passings)
+	assert.Equal(t, []ControlClass{{ID: 1, Name: "H21"}}, controls[1].Classes)
+
+	assert.Equal(t, "Spare", controls[2].Name)
+	assert.Zero(t, controls[2].Passings)
+	assert.Empty(t, controls[2].Classes)
+}
+
+func TestGetControls_RelayLegs(t *testing.T) {
+	appState := state.New()
+
+	// Both legs pass the same radio control
+	radio := models.Control{ID: 31, Name: "Radio 1"}
+	relay := models.Class{ID: 1, Name: "Relay", RadioControls: []models.Control{radio, radio}}
+	appState.UpdateFromMeOS(nil, []models.Control{radio}, []models.Class{relay}, nil, nil, nil)
+
+	controls := New(appState).GetControls()
+	require.Len(t, controls, 1)
+	assert.Equal(t, []ControlClass{{ID: 1, Name: "Relay"}}, controls[0].Classes)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"meos-graphics/internal/iof"
+	"meos-graphics/internal/models"
+)
+
+// GetExportData returns all classes sorted by order key, or a single class when classID
+// is not 0. Ranked competitors and teams come first in each class, followed by the rest by
+// start time. Relay runners are only listed with their teams.
+func (s *Service) GetExportData(classID int) (*iof.ExportData, error) {
+	snap := s.snapshot()
+	data := &iof.ExportData{Classes: []iof.ExportClass{}}
+	if event := snap.Event(); event != nil {
+		data.Event = *event
+	}
+
+	classes := append([]models.Class{}, snap.Classes()...)
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].OrderKey < classes[j].OrderKey
+	})
+
+	var competitors map[int]models.Competitor
+	for _, class := range classes {
+		if classID != 0 && class.ID != classID {
+			continue
+		}
+
+		var individuals []models.Competitor
+		for _, comp := range snap.CompetitorsByClass(class.ID) {
+			if comp.TeamID == 0 {
+				individuals = append(individuals, comp)
+			}
+		}
+		exportClass := iof.ExportClass{Class: class, Entries: exportEntries(individuals)}
+		if teams := snap.TeamsByClass(class.ID); len(teams) > 0 {
+			if competitors == nil {
+				competitors = make(map[int]models.Competitor)
+				for _, comp := range snap.Competitors() {
+					competitors[comp.ID] = comp
+				}
+			}
+			exportClass.Teams = exportTeams(teams, competitors)
+		}
+		data.Classes = append(data.Classes, exportClass)
+	}
+
+	if classID != 0 && len(data.Classes) == 0 {
+		return nil, fmt.Errorf("class not found")
+	}
+	return data, nil
+}
+
+// exportEntries lists approved finishers in result order, followed by the rest by start time
+func exportEntries(competitors []models.Competitor) []iof.ExportEntry {
+	var finished, unranked []models.Competitor
+	for _, comp := range competitors {
+		if approvedFinish(comp.Status, comp.FinishTime) {
+			finished = append(finished, comp)
+		} else {
+			unranked = append(unranked, comp)
+		}
+	}
+
+	ranked := rankFinishers(finished, competitorRunningTime, func(comp models.Competitor) string { return comp.Name })
+	entries := make([]iof.ExportEntry, 0, len(competitors))
+	for _, r := range ranked {
+		entries = append(entries, iof.ExportEntry{
+			Competitor:  r.entry,
+			Position:    r.position,
+			RunningTime: r.runningTime,
+			TimeBehind:  r.runningTime - ranked[0].runningTime,
+		})
+	}
+
+	sort.SliceStable(unranked, func(i, j int) bool {
+		return unranked[i].StartTime.Before(unranked[j].StartTime)
+	})
+	for _, comp := range unranked {
+		entries = append(entries, iof.ExportEntry{Competitor: comp})
+	}
+	return entries
+}
+
+// exportTeams lists approved teams in result order, followed by the rest by start time
+func exportTeams(teams []models.Team, competitors map[int]models.Competitor) []iof.ExportTeam {
+	var finished, unranked []models.Team
+	for _, team := range teams {
+		if approvedFinish(team.Status, team.FinishTime) {
+			finished = append(finished, team)
+		} else {
+			unranked = append(unranked, team)
+		}
+	}
+
+	ranked := rankFinishers(finished, func(team models.Team) time.Duration {
+		return teamRunningTime(team, competitors)
+	}, func(team models.Team) string { return team.Name })
+	entries := make([]iof.ExportTeam, 0, len(teams))
+	for _, r := range ranked {
+		entry := exportTeam(r.entry, competitors)
+		entry.Position = r.position
+		entry.RunningTime = r.runningTime
+		entry.TimeBehind = r.runningTime - ranked[0].runningTime
+		entries = append(entries, entry)
+	}
+
+	rest := make([]iof.ExportTeam, 0, len(unranked))
+	for _, team := range unranked {
+		rest = append(rest, exportTeam(team, competitors))
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return rest[i].StartTime.Before(rest[j].StartTime)
+	})
+	return append(entries, rest...)
+}
+
+// exportTeam returns a team with its runners in leg order
+func exportTeam(team models.Team, competitors map[int]models.Competitor) iof.ExportTeam {
+	entry := iof.ExportTeam{Team: team, StartTime: teamStart(team, teamLegProgress(team, competitors))}
+	for _, leg := range team.Legs {
+		for i, id := range leg.CompetitorIDs {
+			comp, ok := competitors[id]
+			if !ok {
+				continue
+			}
+			runner := iof.ExportRunner{Competitor: comp, Leg: leg.Number}
+			if len(leg.CompetitorIDs) > 1 {
+				runner.LegOrder = i + 1
+			}
+			entry.Runners = append(entry.Runners, runner)
+		}
+	}
+	return entry
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+	"meos-graphics/internal/testhelpers"
+)
+
+func TestGetExportData(t *testing.T) {
+	appState := state.New()
+	class1 := testhelpers.CreateTestClass(1, "Men Elite", 20)
+	class2 := testhelpers.CreateTestClass(2, "Women Elite", 10)
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+
+	competitors := []models.Competitor{
+		testhelpers.CreateTestCompetitor(1, "Waiting Runner", club, class1),
+		testhelpers.CreateFinishedCompetitor(2, "Bertil Berg", club, class1, 9000),
+		testhelpers.CreateFinishedCompetitor(3, "Anna Andersson", club, class1, 9000),
+		testhelpers.CreateFinishedCompetitor(4, "Fast Runner", club, class1, 8000),
+		testhelpers.CreateFinishedCompetitor(5, "Other Class", club, class2, 8000),
+	}
+	appState.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, []models.Class{class1, class2}, []models.Club{club}, competitors, nil)
+
+	svc := New(appState)
+
+	data, err := svc.GetExportData(0)
+	require.NoError(t, err)
+	assert.Equal(t, "Test Competition", data.Event.Name)
+	require.Len(t, data.Classes, 2)
+	assert.Equal(t, "Women Elite", data.Classes[0].Class.Name, "classes should be sorted by order key")
+
+	entries := data.Classes[1].Entries
+	require.Len(t, entries, 4)
+	assert.Equal(t, "Fast Runner", entries[0].Competitor.Name)
+	assert.Equal(t, 1, entries[0].Position)
+
+	// Tied competitors share the position and are ordered by name
+	assert.Equal(t, "Anna Andersson", entries[1].Competitor.Name)
+	assert.Equal(t, 2, entries[1].Position)
+	assert.Equal(t, 2, entries[2].Position)
+	assert.Equal(t, 100*time.Second, entries[2].TimeBehind)
+
+	assert.Equal(t, "Waiting Runner", entries[3].Competitor.Name)
+	assert.Equal(t, 0, entries[3].Position)
+
+	// The export ranks exactly like the class results
+	results, err := svc.GetResults(1)
+	require.NoError(t, err)
+	for i, entry := range entries[:3] {
+		assert.Equal(t, results[i].Name, entry.Competitor.Name)
+		assert.Equal(t, results[i].Position, entry.Position)
+	}
+
+	single, err := svc.GetExportData(2)
+	require.NoError(t, err)
+	require.Len(t, single.Classes, 1)
+	assert.Equal(t, 2, single.Classes[0].Class.ID)
+
+	_, err = svc.GetExportData(99)
+	assert.Error(t, err)
+}
+
+func TestGetExportData_Relay(t *testing.T) {
+	svc := New(setupRelayState())
+
+	data, err := svc.GetExportData(1)
+	require.NoError(t, err)
+	require.Len(t, data.Classes, 1)
+
+	// Relay runners are only listed with their teams
+	class := data.Classes[0]
+	assert.Empty(t, class.Entries)
+	require.Len(t, class.Teams, 2)
+
+	winner := class.Teams[0]
+	assert.Equal(t, "Club A 1", winner.Team.Name)
+	assert.Equal(t, 1, winner.Position)
+	assert.Equal(t, 110*time.Minute, winner.RunningTime)
+	require.Len(t, winner.Runners, 2)
+	assert.Equal(t, "Runner A2", winner.Runners[1].Competitor.Name)
+	assert.Equal(t, 2, winner.Runners[1].Leg)
+	assert.Equal(t, 0, winner.Runners[1].LegOrder)
+
+	running := class.Teams[1]
+	assert.Equal(t, "Club B 1", running.Team.Name)
+	assert.Equal(t, 0, running.Position)
+	assert.Equal(t, time.Duration(0), running.RunningTime)
+}
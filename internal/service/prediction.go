@@ -0,0 +1,187 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+// predictionReferences is how many of the fastest competitors a prediction compares with
+const predictionReferences = 3
+
+// Prediction represents the predicted finish of a running competitor
+type Prediction struct {
+	CompetitorID    int    `json:"competitorId"`
+	Name            string `json:"name"`
+	Club            string `json:"club"`
+	LastControl     string `json:"lastControl"`          // Last radio control passed, which the prediction is based on
+	PredictedTime   string `json:"predictedTime"`        // Formatted predicted running time
+	PredictedFinish string `json:"predictedFinish"`      // Predicted clock time at the finish, formatted as HH:mm:ss
+	VirtualPosition int    `json:"virtualPosition"`      // Position among the finishers at the predicted time
+	Overridden      bool   `json:"overridden,omitempty"` // Set when an admin has overridden the competitor's data
+}
+
+// GetPredictions returns the predicted finish times and virtual positions of the running
+// competitors of a class who have passed a radio control, fastest first
+func (s *Service) GetPredictions(classID int) ([]Prediction, error) {
+	snap := s.snapshot()
+	class, ok := snap.Class(classID)
+	if !ok {
+		return nil, fmt.Errorf("class not found")
+	}
+	return s.classPredictions(snap, class), nil
+}
+
+// classPredictions returns the predictions of a class in a snapshot, fastest first
+func (s *Service) classPredictions(snap *state.Snapshot, class models.Class) []Prediction {
+	var predictions []prediction
+	for _, p := range predictClass(class, snap.CompetitorsByClass(class.ID), s.Now()) {
+		p.Overridden = s.overridden(p.CompetitorID)
+		predictions = append(predictions, p)
+	}
+	sort.Slice(predictions, func(i, j int) bool {
+		if predictions[i].elapsed == predictions[j].elapsed {
+			return predictions[i].Name < predictions[j].Name
+		}
+		return predictions[i].elapsed < predictions[j].elapsed
+	})
+
+	result := make([]Prediction, len(predictions))
+	for i, p := range predictions {
+		result[i] = p.Prediction
+	}
+	return result
+}
+
+// prediction is a Prediction with the unformatted predicted running time
+type prediction struct {
+	Prediction
+	elapsed time.Duration
+}
+
+// predictClass predicts the finish of every running competitor of a class who has passed a
+// radio control, keyed by competitor ID
+func predictClass(class models.Class, competitors []models.Competitor, now time.Time) map[int]prediction {
+	var finishTimes []time.Duration
+	for _, comp := range competitors {
+		if elapsed, ok := elapsedAt(comp, finishControlID); ok {
+			finishTimes = append(finishTimes, elapsed)
+		}
+	}
+
+	predictions := make(map[int]prediction)
+	for _, comp := range competitors {
+		if !running(comp, now) {
+			continue
+		}
+		elapsed, last, ok := predictTime(class, competitors, comp, finishControlID)
+		if !ok || last < 0 {
+			continue
+		}
+
+		position := 1
+		for _, t := range finishTimes {
+			if t < elapsed {
+				position++
+			}
+		}
+		predictions[comp.ID] = prediction{
+			Prediction: Prediction{
+				CompetitorID:    comp.ID,
+				Name:            comp.Name,
+				Club:            comp.Club.Name,
+				LastControl:     class.RadioControls[last].Name,
+				PredictedTime:   FormatDuration(elapsed),
+				PredictedFinish: comp.StartTime.Add(elapsed).Format("15:04:05"),
+				VirtualPosition: position,
+			},
+			elapsed: elapsed,
+		}
+	}
+	return predictions
+}
+
+// predictTime predicts a competitor's running time at a radio control, or at the finish
+// for finishControlID. It takes the fastest competitors at the target who also passed the
+// last radio control the competitor passed before it, and scales their times at the
+// target by how the competitor's time at that control compares to theirs. The returned
+// index of that control in the course is -1 when the competitor has not passed any, in
+// which case they are assumed to run at the pace of the fastest.
+func predictTime(class models.Class, competitors []models.Competitor, comp models.Competitor, target int) (time.Duration, int, bool) {
+	// Only controls before the target count
+	controls := class.RadioControls
+	for i, ctrl := range controls {
+		if ctrl.ID == target {
+			controls = controls[:i]
+			break
+		}
+	}
+
+	last := -1
+	var lastElapsed time.Duration
+	for i, ctrl := range controls {
+		if elapsed, ok := elapsedAt(comp, ctrl.ID); ok {
+			last, lastElapsed = i, elapsed
+		}
+	}
+
+	type reference struct {
+		atTarget time.Duration
+		atLast   time.Duration
+	}
+	var references []reference
+	for _, other := range competitors {
+		if other.ID == comp.ID {
+			continue
+		}
+		atTarget, ok := elapsedAt(other, target)
+		if !ok {
+			continue
+		}
+		ref := reference{atTarget: atTarget}
+		if last >= 0 {
+			if ref.atLast, ok = elapsedAt(other, controls[last].ID); !ok || ref.atLast <= 0 {
+				continue
+			}
+		}
+		references = append(references, ref)
+	}
+	if len(references) == 0 {
+		return 0, last, false
+	}
+
+	sort.Slice(references, func(i, j int) bool { return references[i].atTarget < references[j].atTarget })
+	if len(references) > predictionReferences {
+		references = references[:predictionReferences]
+	}
+
+	var total time.Duration
+	for _, ref := range references {
+		if last < 0 {
+			total += ref.atTarget
+		} else {
+			total += time.Duration(float64(ref.atTarget) * float64(lastElapsed) / float64(ref.atLast))
+		}
+	}
+	return total / time.Duration(len(references)), last, true
+}
+
+// elapsedAt returns the competitor's running time at a radio control, or their approved
+// finish time for finishControlID
+func elapsedAt(comp models.Competitor, controlID int) (time.Duration, bool) {
+	if controlID == finishControlID {
+		if comp.Status == "1" && comp.FinishTime != nil {
+			return comp.FinishTime.Sub(comp.StartTime), true
+		}
+		return 0, false
+	}
+	for _, split := range comp.Splits {
+		if split.Control.ID == controlID {
+			return split.PassingTime.Sub(comp.StartTime), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+func TestGetPredictions(t *testing.T) {
+	appState := state.New()
+	svc := New(appState)
+
+	now := time.Now()
+	start := now.Add(-2 * time.Hour)
+	radio1 := models.Control{ID: 31, Name: "Radio 1"}
+	radio2 := models.Control{ID: 32, Name: "Radio 2"}
+	class := models.Class{ID: 1, Name: "H21", RadioControls: []models.Control{radio1, radio2}}
+
+	finished := func(id int, name string, r1, r2, finish time.Duration) models.Competitor {
+		finishTime := start.Add(finish)
+		return models.Competitor{
+			ID: id, Name: name, Class: class, Status: "1", StartTime: start, FinishTime: &finishTime,
+			Splits: []models.Split{
+				{Control: radio1, PassingTime: start.Add(r1)},
+				{Control: radio2, PassingTime: start.Add(r2)},
+			},
+		}
+	}
+
+	competitors := []models.Competitor{
+		finished(1, "First", 10*time.Minute, 20*time.Minute, 40*time.Minute),
+		finished(2, "Second", 11*time.Minute, 22*time.Minute, 44*time.Minute),
+		finished(3, "Third", 12*time.Minute, 24*time.Minute, 48*time.Minute),
+		finished(4, "Fourth", 15*time.Minute, 30*time.Minute, 60*time.Minute),
+		{
+			// 10% slower than the three fastest at radio 2, so on pace for 44:00
+			ID: 5, Name: "Chaser", Class: class, Status: "0", StartTime: now.Add(-30 * time.Minute),
+			Splits: []models.Split{
+				{Control: radio1, PassingTime: now.Add(-19 * time.Minute)},
+				{Control: radio2, PassingTime: now.Add(-30 * time.Minute).Add(22 * time.Minute)},
+			},
+		},
+		{
+			// Running, but has not passed a radio control yet
+			ID: 6, Name: "Fresh", Class: class, Status: "0", StartTime: now.Add(-5 * time.Minute),
+		},
+	}
+	appState.UpdateFromMeOS(nil, []models.Control{radio1, radio2}, []models.Class{class}, nil, competitors, nil)
+
+	predictions, err := svc.GetPredictions(1)
+	require.NoError(t, err)
+	require.Len(t, predictions, 1)
+	assert.Equal(t, "Chaser", predictions[0].Name)
+	assert.Equal(t, "Radio 2", predictions[0].LastControl)
+	assert.Equal(t, "44:00.0", predictions[0].PredictedTime)
+	// Ties with Second, so shares second place
+	assert.Equal(t, 2, predictions[0].VirtualPosition)
+
+	// The results show the prediction for the running competitor
+	results, err := svc.GetResults(1)
+	require.NoError(t, err)
+	var chaser, fresh ResultEntry
+	for _, result := range results {
+		switch result.Name {
+		case "Chaser":
+			chaser = result
+		case "Fresh":
+			fresh = result
+		}
+	}
+	assert.Equal(t, "44:00.0", chaser.PredictedTime)
+	assert.Equal(t, 2, chaser.VirtualPosition)
+	assert.Empty(t, fresh.PredictedTime)
+
+	splits, err := svc.GetSplits(1)
+	require.NoError(t, err)
+	assert.Equal(t, predictions, splits.Predictions)
+
+	_, err = svc.GetPredictions(99)
+	assert.Error(t, err)
+}
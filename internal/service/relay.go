@@ -0,0 +1,457 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"meos-graphics/internal/i18n"
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+// TeamLegResult represents one leg of a relay team's result
+type TeamLegResult struct {
+	Leg            int      `json:"leg"`
+	Runners        []string `json:"runners"`
+	Status         string   `json:"status,omitempty"`
+	LegTime        string   `json:"legTime,omitempty"`        // Formatted duration of the leg
+	ElapsedTime    string   `json:"elapsedTime,omitempty"`    // Formatted team time at the end of the leg
+	ChangeoverTime string   `json:"changeoverTime,omitempty"` // Clock time the leg was completed, formatted as HH:mm:ss
+}
+
+// TeamResultEntry represents a relay team's result
+type TeamResultEntry struct {
+	Position    int             `json:"position,omitempty"`
+	Name        string          `json:"name"`
+	Bib         string          `json:"bib,omitempty"`
+	Club        string          `json:"club"`
+	Status      string          `json:"status"`
+	RunningTime string          `json:"runningTime,omitempty"` // Formatted duration string
+	Difference  string          `json:"difference,omitempty"`  // Formatted duration from leader
+	Legs        []TeamLegResult `json:"legs"`
+}
+
+// LegStandingEntry represents a team's standing at the end of a relay leg
+type LegStandingEntry struct {
+	Position       int      `json:"position,omitempty"`
+	TeamID         int      `json:"teamId"`
+	Team           string   `json:"team"`
+	Club           string   `json:"club"`
+	Runners        []string `json:"runners"`
+	Status         string   `json:"status,omitempty"`
+	ElapsedTime    string   `json:"elapsedTime,omitempty"` // Formatted team time at the end of the leg
+	Difference     string   `json:"difference,omitempty"`  // Formatted duration from leader
+	LegTime        string   `json:"legTime,omitempty"`
+	LegPosition    int      `json:"legPosition,omitempty"`
+	ChangeoverTime string   `json:"changeoverTime,omitempty"` // Formatted as HH:mm:ss
+}
+
+// LegStandingsResponse represents the standings after one relay leg
+type LegStandingsResponse struct {
+	ClassName string             `json:"className"`
+	Leg       int                `json:"leg"`
+	Legs      int                `json:"legs"` // Total number of legs in the class
+	Standings []LegStandingEntry `json:"standings"`
+}
+
+// ChangeoverEntry represents a handover from one relay leg to the next
+type ChangeoverEntry struct {
+	Team        string   `json:"team"`
+	Club        string   `json:"club"`
+	Leg         int      `json:"leg"` // Leg that was completed
+	Incoming    []string `json:"incoming"`
+	Outgoing    []string `json:"outgoing"`
+	Time        string   `json:"time"`        // Clock time of the changeover, formatted as HH:mm:ss
+	ElapsedTime string   `json:"elapsedTime"` // Formatted team time at the changeover
+	Position    int      `json:"position,omitempty"`
+}
+
+// legProgress is the computed state of a single relay leg for a team
+type legProgress struct {
+	runners  []string
+	status   string     // MeOS status code of the leg, "" while in progress
+	start    time.Time  // Earliest start among the leg's runners
+	finish   *time.Time // Set once every runner on the leg has finished
+	failed   bool
+	complete bool
+}
+
+// GetTeamResults returns the relay team results for a specific class
+func (s *Service) GetTeamResults(classID int) ([]TeamResultEntry, error) {
+	snap := s.snapshot()
+	teams := snap.TeamsByClass(classID)
+	competitors := competitorIndex(snap)
+	currentTime := s.Now()
+	translator := i18n.GetInstance()
+
+	var finished, failed, running, waiting, dns []models.Team
+	for _, team := range teams {
+		// Teams without a start of their own start with their first leg
+		start := teamStart(team, teamLegProgress(team, competitors))
+		switch {
+		case approvedFinish(team.Status, team.FinishTime):
+			finished = append(finished, team)
+		case team.Status == "3" || team.Status == "4" || team.Status == "5" || team.Status == "6":
+			failed = append(failed, team)
+		case team.Status == "20" || team.Status == "21" || team.Status == "99" || start.IsZero():
+			dns = append(dns, team)
+		case currentTime.Before(start):
+			waiting = append(waiting, team)
+		default:
+			// Teams that have crossed the line but are not yet approved stay with the teams
+			// still out, where having completed every leg puts them first
+			running = append(running, team)
+		}
+	}
+
+	results := []TeamResultEntry{}
+	ranked := rankFinishers(finished, func(team models.Team) time.Duration {
+		return teamRunningTime(team, competitors)
+	}, func(team models.Team) string { return team.Name })
+	for i, r := range ranked {
+		entry := teamResultEntry(r.entry, competitors, translator.GetStatusDescription("1"))
+		entry.Position = r.position
+		entry.RunningTime = FormatDuration(r.runningTime)
+		if i > 0 {
+			entry.Difference = "+" + FormatDuration(r.runningTime-ranked[0].runningTime)
+		}
+		results = append(results, entry)
+	}
+
+	for _, team := range failed {
+		results = append(results, teamResultEntry(team, competitors, translator.GetStatusDescription(team.Status)))
+	}
+
+	// Running teams are ordered by how far they have come, then by time at their last changeover
+	sort.SliceStable(running, func(i, j int) bool {
+		doneI, elapsedI := completedLegs(running[i], competitors)
+		doneJ, elapsedJ := completedLegs(running[j], competitors)
+		if doneI != doneJ {
+			return doneI > doneJ
+		}
+		if elapsedI != elapsedJ {
+			return elapsedI < elapsedJ
+		}
+		return running[i].StartTime.Before(running[j].StartTime)
+	})
+	for _, team := range running {
+		results = append(results, teamResultEntry(team, competitors, translator.GetStatusDescription("1001")))
+	}
+
+	for _, team := range waiting {
+		results = append(results, teamResultEntry(team, competitors, translator.GetStatusDescription("1000")))
+	}
+
+	for _, team := range dns {
+		status := team.Status
+		if status != "21" && status != "99" {
+			status = "20"
+		}
+		results = append(results, teamResultEntry(team, competitors, translator.GetStatusDescription(status)))
+	}
+
+	return results, nil
+}
+
+// GetLegStandings returns the team standings at the end of a relay leg
+func (s *Service) GetLegStandings(classID, leg int) (*LegStandingsResponse, error) {
+	return legStandings(s.snapshot(), classID, leg)
+}
+
+// legStandings returns the team standings at the end of a relay leg in a snapshot
+func legStandings(snap *state.Snapshot, classID, leg int) (*LegStandingsResponse, error) {
+	class, ok := snap.Class(classID)
+	if !ok {
+		return nil, fmt.Errorf("class not found")
+	}
+
+	teams := snap.TeamsByClass(classID)
+	numLegs := legCount(teams)
+	if leg < 1 || leg > numLegs {
+		return nil, fmt.Errorf("leg %d not found", leg)
+	}
+
+	competitors := competitorIndex(snap)
+	translator := i18n.GetInstance()
+
+	type standingEntry struct {
+		team    models.Team
+		legs    []legProgress
+		elapsed time.Duration
+		legTime time.Duration
+	}
+
+	var ranked []standingEntry
+	var unranked []LegStandingEntry
+	for _, team := range teams {
+		legs := teamLegProgress(team, competitors)
+		if len(legs) < leg {
+			continue
+		}
+		current := legs[leg-1]
+
+		// A team is only ranked after a leg if every leg up to it was completed without faults
+		failedStatus := ""
+		for _, lp := range legs[:leg] {
+			if lp.failed {
+				failedStatus = lp.status
+				break
+			}
+		}
+		if failedStatus != "" {
+			unranked = append(unranked, LegStandingEntry{
+				TeamID:  team.ID,
+				Team:    team.Name,
+				Club:    team.Club.Name,
+				Runners: current.runners,
+				Status:  translator.GetStatusDescription(failedStatus),
+			})
+			continue
+		}
+		if !current.complete {
+			continue
+		}
+
+		ranked = append(ranked, standingEntry{
+			team:    team,
+			legs:    legs,
+			elapsed: current.finish.Sub(teamStart(team, legs)),
+			legTime: current.finish.Sub(current.start),
+		})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].elapsed == ranked[j].elapsed {
+			return ranked[i].team.Name < ranked[j].team.Name
+		}
+		return ranked[i].elapsed < ranked[j].elapsed
+	})
+
+	// Rank the leg times separately
+	legTimes := make([]time.Duration, len(ranked))
+	for i, entry := range ranked {
+		legTimes[i] = entry.legTime
+	}
+	sort.Slice(legTimes, func(i, j int) bool { return legTimes[i] < legTimes[j] })
+
+	response := &LegStandingsResponse{
+		ClassName: class.Name,
+		Leg:       leg,
+		Legs:      numLegs,
+		Standings: []LegStandingEntry{},
+	}
+
+	position := 1
+	for i, entry := range ranked {
+		if i > 0 && entry.elapsed != ranked[i-1].elapsed {
+			position = i + 1
+		}
+		current := entry.legs[leg-1]
+
+		standing := LegStandingEntry{
+			Position:       position,
+			TeamID:         entry.team.ID,
+			Team:           entry.team.Name,
+			Club:           entry.team.Club.Name,
+			Runners:        current.runners,
+			Status:         translator.GetStatusDescription("1"),
+			ElapsedTime:    FormatDuration(entry.elapsed),
+			LegTime:        FormatDuration(entry.legTime),
+			LegPosition:    sort.Search(len(legTimes), func(k int) bool { return legTimes[k] >= entry.legTime }) + 1,
+			ChangeoverTime: current.finish.Format("15:04:05"),
+		}
+		if i > 0 {
+			standing.Difference = "+" + FormatDuration(entry.elapsed-ranked[0].elapsed)
+		}
+		response.Standings = append(response.Standings, standing)
+	}
+	response.Standings = append(response.Standings, unranked...)
+
+	return response, nil
+}
+
+// GetChangeovers returns all completed relay changeovers for a class in chronological order
+func (s *Service) GetChangeovers(classID int) ([]ChangeoverEntry, error) {
+	snap := s.snapshot()
+	if _, ok := snap.Class(classID); !ok {
+		return nil, fmt.Errorf("class not found")
+	}
+
+	teams := snap.TeamsByClass(classID)
+	numLegs := legCount(teams)
+
+	// Positions at each changeover come from the leg standings
+	positions := make(map[[2]int]int)
+	for leg := 1; leg < numLegs; leg++ {
+		standings, err := legStandings(snap, classID, leg)
+		if err != nil {
+			return nil, err
+		}
+		for _, standing := range standings.Standings {
+			positions[[2]int{standing.TeamID, leg}] = standing.Position
+		}
+	}
+
+	competitors := competitorIndex(snap)
+
+	type changeover struct {
+		entry ChangeoverEntry
+		at    time.Time
+	}
+	var changeovers []changeover
+	for _, team := range teams {
+		legs := teamLegProgress(team, competitors)
+		for i := 0; i < len(legs)-1; i++ {
+			if !legs[i].complete {
+				break
+			}
+			changeovers = append(changeovers, changeover{
+				entry: ChangeoverEntry{
+					Team:        team.Name,
+					Club:        team.Club.Name,
+					Leg:         i + 1,
+					Incoming:    legs[i].runners,
+					Outgoing:    legs[i+1].runners,
+					Time:        legs[i].finish.Format("15:04:05"),
+					ElapsedTime: FormatDuration(legs[i].finish.Sub(teamStart(team, legs))),
+					Position:    positions[[2]int{team.ID, i + 1}],
+				},
+				at: *legs[i].finish,
+			})
+		}
+	}
+
+	sort.SliceStable(changeovers, func(i, j int) bool {
+		return changeovers[i].at.Before(changeovers[j].at)
+	})
+
+	result := make([]ChangeoverEntry, 0, len(changeovers))
+	for _, c := range changeovers {
+		result = append(result, c.entry)
+	}
+	return result, nil
+}
+
+// IsRelayClass reports whether a class has any relay teams
+func (s *Service) IsRelayClass(classID int) bool {
+	return len(s.snapshot().TeamsByClass(classID)) > 0
+}
+
+func teamResultEntry(team models.Team, competitors map[int]models.Competitor, status string) TeamResultEntry {
+	entry := TeamResultEntry{
+		Name:   team.Name,
+		Bib:    team.Bib,
+		Club:   team.Club.Name,
+		Status: status,
+		Legs:   []TeamLegResult{},
+	}
+
+	legs := teamLegProgress(team, competitors)
+	start := teamStart(team, legs)
+	translator := i18n.GetInstance()
+	for i, lp := range legs {
+		legResult := TeamLegResult{
+			Leg:     i + 1,
+			Runners: lp.runners,
+		}
+		if lp.status != "" {
+			legResult.Status = translator.GetStatusDescription(lp.status)
+		}
+		if lp.complete {
+			legResult.LegTime = FormatDuration(lp.finish.Sub(lp.start))
+			legResult.ElapsedTime = FormatDuration(lp.finish.Sub(start))
+			legResult.ChangeoverTime = lp.finish.Format("15:04:05")
+		}
+		entry.Legs = append(entry.Legs, legResult)
+	}
+	return entry
+}
+
+// competitorIndex returns all competitors of a snapshot keyed by ID
+func competitorIndex(snap *state.Snapshot) map[int]models.Competitor {
+	competitors := snap.Competitors()
+	index := make(map[int]models.Competitor, len(competitors))
+	for _, comp := range competitors {
+		index[comp.ID] = comp
+	}
+	return index
+}
+
+// teamLegProgress works out how far a team has come on each of its legs
+func teamLegProgress(team models.Team, competitors map[int]models.Competitor) []legProgress {
+	legs := make([]legProgress, 0, len(team.Legs))
+	for _, leg := range team.Legs {
+		lp := legProgress{runners: []string{}, complete: len(leg.CompetitorIDs) > 0}
+		for _, id := range leg.CompetitorIDs {
+			comp, ok := competitors[id]
+			if !ok {
+				lp.complete = false
+				continue
+			}
+			lp.runners = append(lp.runners, comp.Name)
+
+			switch comp.Status {
+			case "3", "4", "5", "6", "20", "21", "99":
+				lp.failed = true
+				lp.status = comp.Status
+			}
+			if !comp.StartTime.IsZero() && (lp.start.IsZero() || comp.StartTime.Before(lp.start)) {
+				lp.start = comp.StartTime
+			}
+			if comp.FinishTime == nil {
+				lp.complete = false
+			} else if lp.finish == nil || comp.FinishTime.After(*lp.finish) {
+				finish := *comp.FinishTime
+				lp.finish = &finish
+			}
+		}
+		if lp.failed {
+			lp.complete = false
+		} else if lp.complete {
+			lp.status = "1"
+		}
+		legs = append(legs, lp)
+	}
+	return legs
+}
+
+// teamStart returns the team's start time, falling back to the first leg's start
+func teamStart(team models.Team, legs []legProgress) time.Time {
+	if !team.StartTime.IsZero() || len(legs) == 0 {
+		return team.StartTime
+	}
+	return legs[0].start
+}
+
+// completedLegs returns the number of consecutively completed legs and the team time after the last of them
+func completedLegs(team models.Team, competitors map[int]models.Competitor) (int, time.Duration) {
+	legs := teamLegProgress(team, competitors)
+	done := 0
+	var elapsed time.Duration
+	for _, lp := range legs {
+		if !lp.complete {
+			break
+		}
+		done++
+		elapsed = lp.finish.Sub(teamStart(team, legs))
+	}
+	return done, elapsed
+}
+
+// teamRunningTime returns the running time of a team that has finished, from the team
+// start or the first leg's start when the team has none
+func teamRunningTime(team models.Team, competitors map[int]models.Competitor) time.Duration {
+	return team.FinishTime.Sub(teamStart(team, teamLegProgress(team, competitors)))
+}
+
+// legCount returns the highest number of legs among the given teams
+func legCount(teams []models.Team) int {
+	legs := 0
+	for _, team := range teams {
+		if len(team.Legs) > legs {
+			legs = len(team.Legs)
+		}
+	}
+	return legs
+}
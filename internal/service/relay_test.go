@@ -0,0 +1,194 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+// setupRelayState creates a two-leg relay where team 1 has finished and team 2 is out on leg 2
+func setupRelayState() *state.State {
+	appState := state.New()
+
+	relayClass := models.Class{ID: 1, Name: "Relay"}
+	club1 := models.Club{ID: 1, Name: "Club A"}
+	club2 := models.Club{ID: 2, Name: "Club B"}
+
+	start := time.Now().Add(-3 * time.Hour)
+	at := func(d time.Duration) *time.Time {
+		t := start.Add(d)
+		return &t
+	}
+
+	competitors := []models.Competitor{
+		{ID: 11, Name: "Runner A1", Class: relayClass, Club: club1, Status: "1", StartTime: start, FinishTime: at(60 * time.Minute), TeamID: 1, Leg: 1},
+		{ID: 12, Name: "Runner A2", Class: relayClass, Club: club1, Status: "1", StartTime: *at(60 * time.Minute), FinishTime: at(110 * time.Minute), TeamID: 1, Leg: 2},
+		{ID: 21, Name: "Runner B1", Class: relayClass, Club: club2, Status: "1", StartTime: start, FinishTime: at(55 * time.Minute), TeamID: 2, Leg: 1},
+		{ID: 22, Name: "Runner B2", Class: relayClass, Club: club2, Status: "0", StartTime: *at(55 * time.Minute), TeamID: 2, Leg: 2},
+	}
+
+	teams := []models.Team{
+		{
+			ID: 1, Name: "Club A 1", Club: club1, Class: relayClass, Status: "1",
+			StartTime: start, FinishTime: at(110 * time.Minute),
+			Legs: []models.Leg{{Number: 1, CompetitorIDs: []int{11}}, {Number: 2, CompetitorIDs: []int{12}}},
+		},
+		{
+			ID: 2, Name: "Club B 1", Club: club2, Class: relayClass, Status: "0",
+			StartTime: start,
+			Legs:      []models.Leg{{Number: 1, CompetitorIDs: []int{21}}, {Number: 2, CompetitorIDs: []int{22}}},
+		},
+	}
+
+	appState.UpdateFromMeOS(nil, []models.Control{}, []models.Class{relayClass}, []models.Club{club1, club2}, competitors, teams)
+	return appState
+}
+
+func TestGetTeamResults(t *testing.T) {
+	svc := New(setupRelayState())
+
+	results, err := svc.GetTeamResults(1)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "Club A 1", results[0].Name)
+	assert.Equal(t, 1, results[0].Position)
+	assert.Equal(t, "1:50:00.0", results[0].RunningTime)
+	require.Len(t, results[0].Legs, 2)
+	assert.Equal(t, "50:00.0", results[0].Legs[1].LegTime)
+	assert.Equal(t, "1:50:00.0", results[0].Legs[1].ElapsedTime)
+
+	// The running team is listed after finished teams without a position
+	assert.Equal(t, "Club B 1", results[1].Name)
+	assert.Equal(t, 0, results[1].Position)
+	assert.Equal(t, "Running", results[1].Status)
+	assert.Equal(t, "55:00.0", results[1].Legs[0].LegTime)
+	assert.Empty(t, results[1].Legs[1].LegTime)
+}
+
+func TestGetTeamResults_FinishedNotApproved(t *testing.T) {
+	appState := setupRelayState()
+	finish := time.Now().Add(-time.Hour)
+	appState.Lock()
+	appState.Competitors[3].FinishTime = &finish
+	appState.Teams[1].FinishTime = &finish
+	appState.Unlock()
+
+	results, err := New(appState).GetTeamResults(1)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// A team that has crossed the line is not shown as not started while awaiting approval
+	assert.Equal(t, "Club B 1", results[1].Name)
+	assert.Equal(t, 0, results[1].Position)
+	assert.Equal(t, "Running", results[1].Status)
+	assert.NotEmpty(t, results[1].Legs[1].LegTime)
+}
+
+func TestGetTeamResults_LegStartsOnly(t *testing.T) {
+	appState := setupRelayState()
+	appState.Lock()
+	for i := range appState.Teams {
+		appState.Teams[i].StartTime = time.Time{}
+	}
+	appState.Unlock()
+
+	results, err := New(appState).GetTeamResults(1)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// Teams whose times exist only on their legs start with the first leg
+	assert.Equal(t, "Club A 1", results[0].Name)
+	assert.Equal(t, "1:50:00.0", results[0].RunningTime)
+	assert.Equal(t, "Club B 1", results[1].Name)
+	assert.Equal(t, "Running", results[1].Status)
+
+	// A team whose first leg starts later is waiting
+	appState.Lock()
+	for i := range appState.Competitors {
+		if appState.Competitors[i].TeamID == 2 {
+			appState.Competitors[i].StartTime = time.Now().Add(time.Hour)
+			appState.Competitors[i].FinishTime = nil
+		}
+	}
+	appState.Unlock()
+
+	results, err = New(appState).GetTeamResults(1)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Waiting to Start", results[1].Status)
+}
+
+func TestGetLegStandings(t *testing.T) {
+	svc := New(setupRelayState())
+
+	leg1, err := svc.GetLegStandings(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, leg1.Legs)
+	require.Len(t, leg1.Standings, 2)
+	assert.Equal(t, "Club B 1", leg1.Standings[0].Team)
+	assert.Equal(t, 1, leg1.Standings[0].Position)
+	assert.Equal(t, "Club A 1", leg1.Standings[1].Team)
+	assert.Equal(t, "+5:00.0", leg1.Standings[1].Difference)
+	assert.Equal(t, 2, leg1.Standings[1].LegPosition)
+
+	leg2, err := svc.GetLegStandings(1, 2)
+	require.NoError(t, err)
+	require.Len(t, leg2.Standings, 1)
+	assert.Equal(t, "Club A 1", leg2.Standings[0].Team)
+	assert.Equal(t, []string{"Runner A2"}, leg2.Standings[0].Runners)
+
+	_, err = svc.GetLegStandings(1, 3)
+	assert.Error(t, err)
+
+	_, err = svc.GetLegStandings(99, 1)
+	assert.Error(t, err)
+}
+
+func TestGetLegStandings_FailedLeg(t *testing.T) {
+	appState := setupRelayState()
+	appState.Lock()
+	for i := range appState.Competitors {
+		if appState.Competitors[i].ID == 21 {
+			appState.Competitors[i].Status = "3"
+		}
+	}
+	appState.Unlock()
+
+	standings, err := New(appState).GetLegStandings(1, 1)
+	require.NoError(t, err)
+	require.Len(t, standings.Standings, 2)
+	assert.Equal(t, "Club A 1", standings.Standings[0].Team)
+	assert.Equal(t, 1, standings.Standings[0].Position)
+	assert.Equal(t, 0, standings.Standings[1].Position)
+	assert.Equal(t, "Miss Punch", standings.Standings[1].Status)
+}
+
+func TestGetChangeovers(t *testing.T) {
+	svc := New(setupRelayState())
+
+	changeovers, err := svc.GetChangeovers(1)
+	require.NoError(t, err)
+	require.Len(t, changeovers, 2)
+
+	// Chronological order: team B handed over first
+	assert.Equal(t, "Club B 1", changeovers[0].Team)
+	assert.Equal(t, 1, changeovers[0].Position)
+	assert.Equal(t, []string{"Runner B1"}, changeovers[0].Incoming)
+	assert.Equal(t, []string{"Runner B2"}, changeovers[0].Outgoing)
+	assert.Equal(t, "55:00.0", changeovers[0].ElapsedTime)
+	assert.Equal(t, "Club A 1", changeovers[1].Team)
+	assert.Equal(t, 2, changeovers[1].Position)
+}
+
+func TestIsRelayClass(t *testing.T) {
+	svc := New(setupRelayState())
+
+	assert.True(t, svc.IsRelayClass(1))
+	assert.False(t, svc.IsRelayClass(2))
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSearchResults limits how many competitors a search returns
+const maxSearchResults = 50
+
+// SearchResult represents a competitor matching a search
+type SearchResult struct {
+	CompetitorID int    `json:"competitorId"`
+	Name         string `json:"name"`
+	Bib          string `json:"bib,omitempty"`
+	Card         int    `json:"card,omitempty"`
+	ClubID       int    `json:"clubId"`
+	Club         string `json:"club"`
+	ClassID      int    `json:"classId"`
+	Class        string `json:"class"`
+	Status       string `json:"status"`
+	StartTime    string `json:"startTime,omitempty"`  // Formatted as HH:mm:ss
+	Overridden   bool   `json:"overridden,omitempty"` // Set when an admin has overridden the competitor's data
+}
+
+// Search finds competitors by partial name, club name, SI card number or bib. Every word of
+// the query has to match one of them, ignoring case and accents, so "kare ravinen" finds
+// Kåre from OK Ravinen. Card numbers match from the start and bibs match exactly. Results
+// are sorted with names starting with the query first, then by name.
+func (s *Service) Search(query string) []SearchResult {
+	terms := strings.Fields(fold(query))
+	results := []SearchResult{}
+	if len(terms) == 0 {
+		return results
+	}
+
+	snap := s.snapshot()
+	now := s.Now()
+
+	for _, comp := range snap.Competitors() {
+		name := fold(comp.Name)
+		club := fold(comp.Club.Name)
+		bib := fold(comp.Bib)
+		card := ""
+		if comp.Card != 0 {
+			card = strconv.Itoa(comp.Card)
+		}
+
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(name, term) && !strings.Contains(club, term) &&
+				(card == "" || !strings.HasPrefix(card, term)) && (bib == "" || bib != term) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		result := SearchResult{
+			CompetitorID: comp.ID,
+			Name:         comp.Name,
+			Bib:          comp.Bib,
+			Card:         comp.Card,
+			ClubID:       comp.Club.ID,
+			Club:         comp.Club.Name,
+			ClassID:      comp.Class.ID,
+			Class:        comp.Class.Name,
+			Status:       statusDescription(comp, now),
+			Overridden:   s.overridden(comp.ID),
+		}
+		if class, ok := snap.Class(comp.Class.ID); ok {
+			result.Class = class.Name
+		}
+		if !comp.StartTime.IsZero() {
+			result.StartTime = comp.StartTime.Format("15:04:05")
+		}
+		results = append(results, result)
+	}
+
+	prefix := terms[0]
+	sort.Slice(results, func(i, j int) bool {
+		pi := strings.HasPrefix(fold(results[i].Name), prefix)
+		pj := strings.HasPrefix(fold(results[j].Name), prefix)
+		if pi != pj {
+			return pi
+		}
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].CompetitorID < results[j].CompetitorID
+	})
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+	return results
+}
+
+// letterFolds spells out the Nordic letters that have no decomposed form
+var letterFolds = strings.NewReplacer("ø", "o", "æ", "ae", "ð", "d", "þ", "th", "ł", "l", "ß", "ss")
+
+// fold lowercases a string and strips its accents for matching
+func fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, strings.ToLower(s))
+	if err != nil {
+		folded = strings.ToLower(s)
+	}
+	return letterFolds.Replace(folded)
+}
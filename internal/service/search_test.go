@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+func setupSearchState() *state.State {
+	appState := state.New()
+
+	class := models.Class{ID: 1, Name: "H21"}
+	ravinen := models.Club{ID: 1, Name: "OK Ravinen"}
+	linne := models.Club{ID: 2, Name: "Linné OK"}
+
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Kåre Olsen", Bib: "101", Card: 800123, Class: class, Club: ravinen, Status: "0"},
+		{ID: 2, Name: "Anna Linné", Bib: "102", Card: 600456, Class: class, Club: ravinen, Status: "0"},
+		{ID: 3, Name: "Søren Dahl", Bib: "5", Card: 123500, Class: class, Club: linne, Status: "0"},
+	}
+
+	appState.UpdateFromMeOS(nil, nil, []models.Class{class}, []models.Club{ravinen, linne}, competitors, nil)
+	return appState
+}
+
+func searchIDs(results []SearchResult) []int {
+	ids := []int{}
+	for _, r := range results {
+		ids = append(ids, r.CompetitorID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	svc := New(setupSearchState())
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"partial name", "olse", []int{1}},
+		{"accented query", "Kåre", []int{1}},
+		{"unaccented query", "kare", []int{1}},
+		{"letter without decomposition", "soren", []int{3}},
+		{"name or club", "linne", []int{2, 3}},
+		{"club name", "ravinen", []int{2, 1}},
+		{"words match different fields", "linné ravinen", []int{2}},
+		{"card from the start", "800", []int{1}},
+		{"bib exactly", "5", []int{3}},
+		{"names starting with the query first", "s", []int{3, 1}},
+		{"no match", "nobody", []int{}},
+		{"empty query", "  ", []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, searchIDs(svc.Search(tt.query)))
+		})
+	}
+}
@@ -7,12 +7,19 @@ import (
 
 	"meos-graphics/internal/i18n"
 	"meos-graphics/internal/models"
+	"meos-graphics/internal/overrides"
 	"meos-graphics/internal/state"
 )
 
 // Service contains the business logic for competition data
 type Service struct {
-	state *state.State
+	state     *state.State
+	overrides *overrides.Store
+	clock     func() time.Time
+
+	// Set for a service that answers as of a moment in the past
+	fixed *state.Snapshot
+	at    time.Time
 }
 
 // New creates a new service instance
@@ -22,6 +29,60 @@ func New(appState *state.State) *Service {
 	}
 }
 
+// UseOverrides applies the manual overrides of a store to everything the service returns
+func (s *Service) UseOverrides(store *overrides.Store) {
+	s.overrides = store
+}
+
+// UseClock makes the service answer as of the time returned by clock instead of the
+// current time, such as the replayed time of a recorded race
+func (s *Service) UseClock(clock func() time.Time) {
+	s.clock = clock
+}
+
+// At returns a service that answers from a snapshot of the state as it was at the given
+// time, which also decides who had started and finished. Manual overrides do not apply.
+func (s *Service) At(snap *state.Snapshot, at time.Time) *Service {
+	return &Service{
+		state: s.state,
+		fixed: snap,
+		at:    at,
+	}
+}
+
+// snapshot returns the state the service answers from: the current state with any
+// overrides applied, or the past snapshot of a service created with At
+func (s *Service) snapshot() *state.Snapshot {
+	if s.fixed != nil {
+		return s.fixed
+	}
+	if s.overrides == nil {
+		return s.state.Snapshot()
+	}
+	return s.overrides.Apply(s.state.Snapshot())
+}
+
+// Now returns the time the service answers as of, which decides who has started and
+// finished
+func (s *Service) Now() time.Time {
+	if !s.at.IsZero() {
+		return s.at
+	}
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+// overridden reports whether a competitor's data has been manually overridden
+func (s *Service) overridden(competitorID int) bool {
+	if s.overrides == nil {
+		return false
+	}
+	_, ok := s.overrides.Get(competitorID)
+	return ok
+}
+
 // ClassInfo represents basic class information
 type ClassInfo struct {
 	ID       int    `json:"id"`
@@ -31,9 +92,10 @@ type ClassInfo struct {
 
 // StartListEntry represents an entry in the start list
 type StartListEntry struct {
-	Name      string `json:"name"`
-	Club      string `json:"club"`
-	StartTime string `json:"startTime"` // Formatted as HH:mm
+	Name       string `json:"name"`
+	Club       string `json:"club"`
+	StartTime  string `json:"startTime"`            // Formatted as HH:mm
+	Overridden bool   `json:"overridden,omitempty"` // Set when an admin has overridden the competitor's data
 }
 
 // ResultEntry represents a competitor's result
@@ -44,33 +106,49 @@ type ResultEntry struct {
 	RunningTime string `json:"runningTime,omitempty"` // Formatted duration string
 	Position    int    `json:"position,omitempty"`
 	Difference  string `json:"difference,omitempty"` // Formatted duration from leader
+
+	// Set for running competitors who have passed a radio control
+	PredictedTime   string `json:"predictedTime,omitempty"`   // Formatted predicted running time
+	VirtualPosition int    `json:"virtualPosition,omitempty"` // Position at the predicted time
+
+	// Set when an admin has overridden the competitor's data
+	Overridden bool `json:"overridden,omitempty"`
 }
 
 // SplitTime represents a split time at a control
 type SplitTime struct {
 	Position       int     `json:"position,omitempty"`
+	CompetitorID   int     `json:"competitorId"`
 	Name           string  `json:"name"`
 	Club           string  `json:"club"`
 	ElapsedTime    *string `json:"elapsedTime,omitempty"`
 	TimeDifference *string `json:"timeDifference,omitempty"`
+	LegTime        *string `json:"legTime,omitempty"`       // Time from the previous control, or the start for the first control
+	LegPosition    int     `json:"legPosition,omitempty"`   // Rank of the leg time
+	LegDifference  *string `json:"legDifference,omitempty"` // Time lost to the best leg time
+	BestLeg        bool    `json:"bestLeg,omitempty"`       // Set for the fastest leg time
+	Overridden     bool    `json:"overridden,omitempty"`    // Set when an admin has overridden the competitor's data
 }
 
 // SplitStanding represents standings at a control
 type SplitStanding struct {
 	ControlID   int         `json:"controlId"`
 	ControlName string      `json:"controlName"`
+	LegFrom     string      `json:"legFrom"`               // Control the leg starts at, "Start" for the first control
+	BestLegTime *string     `json:"bestLegTime,omitempty"` // Fastest leg time
 	Standings   []SplitTime `json:"standings"`
 }
 
 // SplitsResponse represents the full splits response
 type SplitsResponse struct {
-	ClassName string          `json:"className"`
-	Splits    []SplitStanding `json:"splits"`
+	ClassName   string          `json:"className"`
+	Splits      []SplitStanding `json:"splits"`
+	Predictions []Prediction    `json:"predictions"` // Predicted finishes of the running competitors, fastest first
 }
 
 // GetClasses returns all competition classes sorted by order key
 func (s *Service) GetClasses() []ClassInfo {
-	classes := s.state.GetClasses()
+	classes := s.snapshot().Classes()
 
 	var classInfos []ClassInfo
 	for _, class := range classes {
@@ -91,7 +169,7 @@ func (s *Service) GetClasses() []ClassInfo {
 
 // GetStartList returns the start list for a specific class
 func (s *Service) GetStartList(classID int) ([]StartListEntry, error) {
-	competitors := s.state.GetCompetitorsByClass(classID)
+	competitors := s.snapshot().CompetitorsByClass(classID)
 	if len(competitors) == 0 {
 		return []StartListEntry{}, nil
 	}
@@ -104,9 +182,10 @@ func (s *Service) GetStartList(classID int) ([]StartListEntry, error) {
 	var startList []StartListEntry
 	for _, comp := range competitors {
 		startList = append(startList, StartListEntry{
-			Name:      comp.Name,
-			Club:      comp.Club.Name,
-			StartTime: comp.StartTime.Format("15:04"),
+			Name:       comp.Name,
+			Club:       comp.Club.Name,
+			Overridden: s.overridden(comp.ID),
+			StartTime:  comp.StartTime.Format("15:04"),
 		})
 	}
 
@@ -115,8 +194,9 @@ func (s *Service) GetStartList(classID int) ([]StartListEntry, error) {
 
 // GetResults returns the results for a specific class
 func (s *Service) GetResults(classID int) ([]ResultEntry, error) {
-	competitors := s.state.GetCompetitorsByClass(classID)
-	currentTime := time.Now()
+	snap := s.snapshot()
+	competitors := snap.CompetitorsByClass(classID)
+	currentTime := s.Now()
 
 	var results []ResultEntry
 	var finishedCompetitors []models.Competitor
@@ -129,7 +209,7 @@ func (s *Service) GetResults(classID int) ([]ResultEntry, error) {
 	for _, comp := range competitors {
 		switch comp.Status {
 		case "1": // Approved/Finished
-			if comp.FinishTime != nil {
+			if approvedFinish(comp.Status, comp.FinishTime) {
 				finishedCompetitors = append(finishedCompetitors, comp)
 			}
 		case "3": // Miss Punch
@@ -171,56 +251,20 @@ func (s *Service) GetResults(classID int) ([]ResultEntry, error) {
 		}
 	}
 
-	// Sort finished competitors by time, then by name for ties
-	sort.Slice(finishedCompetitors, func(i, j int) bool {
-		if finishedCompetitors[i].FinishTime == nil || finishedCompetitors[j].FinishTime == nil {
-			return false
-		}
-		timeI := finishedCompetitors[i].FinishTime.Sub(finishedCompetitors[i].StartTime)
-		timeJ := finishedCompetitors[j].FinishTime.Sub(finishedCompetitors[j].StartTime)
-		if timeI == timeJ {
-			// For tied times, sort alphabetically by name
-			return finishedCompetitors[i].Name < finishedCompetitors[j].Name
-		}
-		return timeI < timeJ
-	})
-
 	// Build results with positions
-	position := 1
-	var winnerTime time.Duration
-
-	for i, comp := range finishedCompetitors {
-		runTime := comp.FinishTime.Sub(comp.StartTime)
-		timeStr := formatDuration(runTime)
-
-		var timeBehind *string
-		if i == 0 {
-			winnerTime = runTime
-		} else {
-			behind := runTime - winnerTime
-			behindStr := "+" + formatDuration(behind)
-			timeBehind = &behindStr
-		}
-
-		// Calculate position considering ties
-		if i > 0 {
-			prevRunTime := finishedCompetitors[i-1].FinishTime.Sub(finishedCompetitors[i-1].StartTime)
-			if runTime != prevRunTime {
-				// Not a tie, update position to current index + 1
-				position = i + 1
-			}
-			// If times are equal, keep the same position
-		}
-
+	ranked := rankFinishers(finishedCompetitors, competitorRunningTime, func(comp models.Competitor) string { return comp.Name })
+	for i, r := range ranked {
+		comp := r.entry
 		result := ResultEntry{
 			Name:        comp.Name,
 			Club:        comp.Club.Name,
+			Overridden:  s.overridden(comp.ID),
 			Status:      i18n.GetInstance().GetStatusDescription("1"),
-			RunningTime: timeStr,
-			Position:    position,
+			RunningTime: FormatDuration(r.runningTime),
+			Position:    r.position,
 		}
-		if timeBehind != nil {
-			result.Difference = *timeBehind
+		if i > 0 {
+			result.Difference = "+" + FormatDuration(r.runningTime-ranked[0].runningTime)
 		}
 		results = append(results, result)
 	}
@@ -241,30 +285,42 @@ func (s *Service) GetResults(classID int) ([]ResultEntry, error) {
 			status = i18n.GetInstance().GetStatusDescription("4")
 		}
 		results = append(results, ResultEntry{
-			Name:   comp.Name,
-			Club:   comp.Club.Name,
-			Status: status,
+			Name:       comp.Name,
+			Club:       comp.Club.Name,
+			Overridden: s.overridden(comp.ID),
+			Status:     status,
 		})
 	}
 
-	// Add running competitors (sorted by start time)
+	// Add running competitors (sorted by start time) with their predicted finish
 	sort.Slice(runningCompetitors, func(i, j int) bool {
 		return runningCompetitors[i].StartTime.Before(runningCompetitors[j].StartTime)
 	})
+	var predictions map[int]prediction
+	if class, ok := snap.Class(classID); ok && len(runningCompetitors) > 0 {
+		predictions = predictClass(class, competitors, currentTime)
+	}
 	for _, comp := range runningCompetitors {
-		results = append(results, ResultEntry{
-			Name:   comp.Name,
-			Club:   comp.Club.Name,
-			Status: i18n.GetInstance().GetStatusDescription("1001"),
-		})
+		entry := ResultEntry{
+			Name:       comp.Name,
+			Club:       comp.Club.Name,
+			Overridden: s.overridden(comp.ID),
+			Status:     i18n.GetInstance().GetStatusDescription("1001"),
+		}
+		if p, ok := predictions[comp.ID]; ok {
+			entry.PredictedTime = p.PredictedTime
+			entry.VirtualPosition = p.VirtualPosition
+		}
+		results = append(results, entry)
 	}
 
 	// Add waiting competitors (not yet started)
 	for _, comp := range waitingCompetitors {
 		results = append(results, ResultEntry{
-			Name:   comp.Name,
-			Club:   comp.Club.Name,
-			Status: i18n.GetInstance().GetStatusDescription("1000"),
+			Name:       comp.Name,
+			Club:       comp.Club.Name,
+			Overridden: s.overridden(comp.ID),
+			Status:     i18n.GetInstance().GetStatusDescription("1000"),
 		})
 	}
 
@@ -280,87 +336,139 @@ func (s *Service) GetResults(classID int) ([]ResultEntry, error) {
 			status = i18n.GetInstance().GetStatusDescription("20")
 		}
 		results = append(results, ResultEntry{
-			Name:   comp.Name,
-			Club:   comp.Club.Name,
-			Status: status,
+			Name:       comp.Name,
+			Club:       comp.Club.Name,
+			Overridden: s.overridden(comp.ID),
+			Status:     status,
 		})
 	}
 
 	return results, nil
 }
 
-// GetSplits returns split standings for a specific class
-func (s *Service) GetSplits(classID int) (*SplitsResponse, error) {
-	// Get class info
-	var className string
-	var radioControls []models.Control
-	classes := s.state.GetClasses()
-	for _, class := range classes {
-		if class.ID == classID {
-			className = class.Name
-			radioControls = class.RadioControls
-			break
+// ranking is an approved finisher with their place in the results
+type ranking[T any] struct {
+	entry       T
+	runningTime time.Duration
+	position    int
+}
+
+// rankFinishers orders approved finishers by running time, then by name, giving equal
+// times the same position. The results and the exports both rank through it so their
+// positions agree.
+func rankFinishers[T any](finishers []T, runningTime func(T) time.Duration, name func(T) string) []ranking[T] {
+	ranked := make([]ranking[T], 0, len(finishers))
+	for _, entry := range finishers {
+		ranked = append(ranked, ranking[T]{entry: entry, runningTime: runningTime(entry)})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].runningTime == ranked[j].runningTime {
+			return name(ranked[i].entry) < name(ranked[j].entry)
+		}
+		return ranked[i].runningTime < ranked[j].runningTime
+	})
+	for i := range ranked {
+		ranked[i].position = i + 1
+		if i > 0 && ranked[i].runningTime == ranked[i-1].runningTime {
+			ranked[i].position = ranked[i-1].position
 		}
 	}
+	return ranked
+}
+
+// approvedFinish reports whether a competitor or team is ranked in the results
+func approvedFinish(status string, finishTime *time.Time) bool {
+	return status == "1" && finishTime != nil
+}
 
-	if className == "" {
+// competitorRunningTime returns the running time of a competitor who has finished
+func competitorRunningTime(comp models.Competitor) time.Duration {
+	return comp.FinishTime.Sub(comp.StartTime)
+}
+
+// GetSplits returns split standings for a specific class. Besides the elapsed time at each
+// control it ranks the leg from the previous control, or from the start for the first one.
+func (s *Service) GetSplits(classID int) (*SplitsResponse, error) {
+	// Get class info
+	snap := s.snapshot()
+	class, ok := snap.Class(classID)
+	if !ok || class.Name == "" {
 		return nil, fmt.Errorf("class not found")
 	}
+	return s.classSplits(snap, class), nil
+}
 
-	competitors := s.state.GetCompetitorsByClass(classID)
+// classSplits returns the split standings of a class in a snapshot, along with the
+// predictions of its running competitors
+func (s *Service) classSplits(snap *state.Snapshot, class models.Class) *SplitsResponse {
+	competitors := snap.CompetitorsByClass(class.ID)
 
 	response := &SplitsResponse{
-		ClassName: className,
+		ClassName: class.Name,
 		Splits:    []SplitStanding{},
 	}
 
 	// Process each control (including finish)
-	allControls := radioControls
+	allControls := append([]models.Control{}, class.RadioControls...)
 	allControls = append(allControls, models.Control{ID: -1, Name: "Finish"})
 
+	// Passing times at the previous control, where each competitor's next leg starts
+	previous := make(map[int]time.Time, len(competitors))
+	for _, comp := range competitors {
+		if !comp.StartTime.IsZero() {
+			previous[comp.ID] = comp.StartTime
+		}
+	}
+	legFrom := "Start"
+
+	type splitEntry struct {
+		competitor models.Competitor
+		splitTime  time.Time
+		elapsed    time.Duration
+		legTime    time.Duration
+		hasLeg     bool
+	}
+
 	for _, control := range allControls {
 		standing := SplitStanding{
 			ControlID:   control.ID,
 			ControlName: control.Name,
+			LegFrom:     legFrom,
 			Standings:   []SplitTime{},
 		}
 
-		var splitEntries []struct {
-			competitor models.Competitor
-			splitTime  *time.Time
-			elapsed    time.Duration
-		}
+		var splitEntries []splitEntry
 
 		// Collect split times for this control
 		for _, comp := range competitors {
-			var splitTime *time.Time
-			var elapsed time.Duration
+			var entry splitEntry
+			found := false
 
 			if control.ID == -1 { // Finish
 				if comp.FinishTime != nil {
-					splitTime = comp.FinishTime
-					elapsed = splitTime.Sub(comp.StartTime)
-					splitEntries = append(splitEntries, struct {
-						competitor models.Competitor
-						splitTime  *time.Time
-						elapsed    time.Duration
-					}{comp, splitTime, elapsed})
+					entry = splitEntry{competitor: comp, splitTime: *comp.FinishTime}
+					found = true
 				}
 			} else {
 				// Find split for this control
 				for _, split := range comp.Splits {
 					if split.Control.ID == control.ID {
-						splitTime = &split.PassingTime
-						elapsed = splitTime.Sub(comp.StartTime)
-						splitEntries = append(splitEntries, struct {
-							competitor models.Competitor
-							splitTime  *time.Time
-							elapsed    time.Duration
-						}{comp, splitTime, elapsed})
+						entry = splitEntry{competitor: comp, splitTime: split.PassingTime}
+						found = true
 						break
 					}
 				}
 			}
+
+			if found {
+				entry.elapsed = entry.splitTime.Sub(comp.StartTime)
+				// A leg needs a passing at the previous control
+				if from, ok := previous[comp.ID]; ok {
+					entry.legTime = entry.splitTime.Sub(from)
+					entry.hasLeg = true
+				}
+				splitEntries = append(splitEntries, entry)
+			}
 		}
 
 		// Sort by elapsed time, then by name for ties
@@ -372,19 +480,33 @@ func (s *Service) GetSplits(classID int) (*SplitsResponse, error) {
 			return splitEntries[i].elapsed < splitEntries[j].elapsed
 		})
 
+		// Find the best leg time
+		var bestLeg time.Duration
+		hasBestLeg := false
+		for _, entry := range splitEntries {
+			if entry.hasLeg && (!hasBestLeg || entry.legTime < bestLeg) {
+				bestLeg = entry.legTime
+				hasBestLeg = true
+			}
+		}
+		if hasBestLeg {
+			bestLegStr := FormatDuration(bestLeg)
+			standing.BestLegTime = &bestLegStr
+		}
+
 		// Build standings with positions
 		position := 1
 		var leaderTime time.Duration
 
 		for i, entry := range splitEntries {
-			elapsedStr := formatDuration(entry.elapsed)
+			elapsedStr := FormatDuration(entry.elapsed)
 
 			var timeBehind *string
 			if i == 0 {
 				leaderTime = entry.elapsed
 			} else {
 				behind := entry.elapsed - leaderTime
-				behindStr := "+" + formatDuration(behind)
+				behindStr := "+" + FormatDuration(behind)
 				timeBehind = &behindStr
 			}
 
@@ -397,13 +519,35 @@ func (s *Service) GetSplits(classID int) (*SplitsResponse, error) {
 				// If times are equal, keep the same position
 			}
 
-			standing.Standings = append(standing.Standings, SplitTime{
+			splitTime := SplitTime{
 				Position:       position,
+				CompetitorID:   entry.competitor.ID,
 				Name:           entry.competitor.Name,
 				Club:           entry.competitor.Club.Name,
+				Overridden:     s.overridden(entry.competitor.ID),
 				ElapsedTime:    &elapsedStr,
 				TimeDifference: timeBehind,
-			})
+			}
+
+			if entry.hasLeg {
+				legStr := FormatDuration(entry.legTime)
+				splitTime.LegTime = &legStr
+				// Equal leg times share a rank
+				splitTime.LegPosition = 1
+				for _, other := range splitEntries {
+					if other.hasLeg && other.legTime < entry.legTime {
+						splitTime.LegPosition++
+					}
+				}
+				if entry.legTime == bestLeg {
+					splitTime.BestLeg = true
+				} else {
+					lostStr := "+" + FormatDuration(entry.legTime-bestLeg)
+					splitTime.LegDifference = &lostStr
+				}
+			}
+
+			standing.Standings = append(standing.Standings, splitTime)
 		}
 
 		// Add competitors without this split (but not for finish)
@@ -419,8 +563,10 @@ func (s *Service) GetSplits(classID int) (*SplitsResponse, error) {
 					}
 					if !found {
 						standing.Standings = append(standing.Standings, SplitTime{
-							Name: comp.Name,
-							Club: comp.Club.Name,
+							CompetitorID: comp.ID,
+							Name:         comp.Name,
+							Club:         comp.Club.Name,
+							Overridden:   s.overridden(comp.ID),
 						})
 					}
 				}
@@ -428,13 +574,22 @@ func (s *Service) GetSplits(classID int) (*SplitsResponse, error) {
 		}
 
 		response.Splits = append(response.Splits, standing)
+
+		// The next leg starts at this control
+		previous = make(map[int]time.Time, len(splitEntries))
+		for _, entry := range splitEntries {
+			previous[entry.competitor.ID] = entry.splitTime
+		}
+		legFrom = control.Name
 	}
 
-	return response, nil
+	response.Predictions = s.classPredictions(snap, class)
+
+	return response
 }
 
-// formatDuration formats a duration with deciseconds
-func formatDuration(d time.Duration) string {
+// FormatDuration formats a duration with deciseconds
+func FormatDuration(d time.Duration) string {
 	// Convert to deciseconds to avoid floating point precision issues
 	totalDeciseconds := d.Milliseconds() / 100
 
@@ -80,7 +80,7 @@ func TestGetResults_SharedPositions(t *testing.T) {
 	}
 
 	// Update state with test data
-	appState.UpdateFromMeOS(nil, []models.Control{}, []models.Class{testClass}, []models.Club{}, competitors)
+	appState.UpdateFromMeOS(nil, []models.Control{}, []models.Class{testClass}, []models.Club{}, competitors, nil)
 
 	result, err := svc.GetResults(1)
 	assert.NoError(t, err)
@@ -235,7 +235,7 @@ func TestGetResults_MultipleSharedPositions(t *testing.T) {
 		},
 	}
 
-	appState.UpdateFromMeOS(nil, []models.Control{}, []models.Class{testClass}, []models.Club{}, competitors)
+	appState.UpdateFromMeOS(nil, []models.Control{}, []models.Class{testClass}, []models.Club{}, competitors, nil)
 
 	result, err := svc.GetResults(1)
 	assert.NoError(t, err)
@@ -317,7 +317,7 @@ func TestGetSplits_SharedPositions(t *testing.T) {
 		},
 	}
 
-	appState.UpdateFromMeOS(nil, []models.Control{}, []models.Class{testClass}, []models.Club{}, competitors)
+	appState.UpdateFromMeOS(nil, []models.Control{}, []models.Class{testClass}, []models.Club{}, competitors, nil)
 
 	result, err := svc.GetSplits(1)
 	assert.NoError(t, err)
@@ -394,7 +394,7 @@ func TestGetResults_TiedCompetitorsAlphabeticalOrder(t *testing.T) {
 		},
 	}
 
-	appState.UpdateFromMeOS(nil, []models.Control{}, []models.Class{testClass}, []models.Club{}, competitors)
+	appState.UpdateFromMeOS(nil, []models.Control{}, []models.Class{testClass}, []models.Club{}, competitors, nil)
 
 	result, err := svc.GetResults(1)
 	assert.NoError(t, err)
@@ -412,3 +412,105 @@ func TestGetResults_TiedCompetitorsAlphabeticalOrder(t *testing.T) {
 	assert.Equal(t, "Charlie Runner", result[2].Name)
 	assert.Equal(t, "Zebra Runner", result[3].Name)
 }
+
+func TestGetSplits_LegTimes(t *testing.T) {
+	appState := state.New()
+	svc := New(appState)
+
+	startTime := time.Now().Add(-time.Hour)
+	control1 := models.Control{ID: 1, Name: "Control 1"}
+	control2 := models.Control{ID: 2, Name: "Control 2"}
+	testClass := models.Class{ID: 1, Name: "Elite", RadioControls: []models.Control{control1, control2}}
+
+	at := func(d time.Duration) *time.Time {
+		t := startTime.Add(d)
+		return &t
+	}
+
+	competitors := []models.Competitor{
+		{
+			// Fastest to control 1, slow on the long leg
+			ID: 1, Name: "Runner A", StartTime: startTime, Status: "1", Class: testClass,
+			FinishTime: at(40 * time.Minute),
+			Splits: []models.Split{
+				{Control: control1, PassingTime: *at(5 * time.Minute)},
+				{Control: control2, PassingTime: *at(30 * time.Minute)},
+			},
+		},
+		{
+			// Best on the long leg and to the finish
+			ID: 2, Name: "Runner B", StartTime: startTime, Status: "1", Class: testClass,
+			FinishTime: at(35 * time.Minute),
+			Splits: []models.Split{
+				{Control: control1, PassingTime: *at(6 * time.Minute)},
+				{Control: control2, PassingTime: *at(26 * time.Minute)},
+			},
+		},
+		{
+			// Missed control 1, so has no leg time to control 2
+			ID: 3, Name: "Runner C", StartTime: startTime, Status: "0", Class: testClass,
+			Splits: []models.Split{
+				{Control: control2, PassingTime: *at(25 * time.Minute)},
+			},
+		},
+	}
+
+	appState.UpdateFromMeOS(nil, []models.Control{control1, control2}, []models.Class{testClass}, []models.Club{}, competitors, nil)
+
+	result, err := svc.GetSplits(1)
+	assert.NoError(t, err)
+	assert.Len(t, result.Splits, 3)
+
+	// First leg is from the start
+	first := result.Splits[0]
+	assert.Equal(t, "Start", first.LegFrom)
+	assert.Equal(t, "5:00.0", *first.BestLegTime)
+	assert.True(t, first.Standings[0].BestLeg)
+	assert.Equal(t, 2, first.Standings[1].LegPosition)
+	assert.Equal(t, "+1:00.0", *first.Standings[1].LegDifference)
+
+	// Runner C leads at control 2 but has no leg; Runner B's 20:00 beats Runner A's 25:00
+	second := result.Splits[1]
+	assert.Equal(t, "Control 1", second.LegFrom)
+	assert.Equal(t, "Runner C", second.Standings[0].Name)
+	assert.Nil(t, second.Standings[0].LegTime)
+	assert.Equal(t, 0, second.Standings[0].LegPosition)
+	assert.Equal(t, "Runner B", second.Standings[1].Name)
+	assert.Equal(t, "20:00.0", *second.Standings[1].LegTime)
+	assert.Equal(t, 1, second.Standings[1].LegPosition)
+	assert.True(t, second.Standings[1].BestLeg)
+	assert.Equal(t, "Runner A", second.Standings[2].Name)
+	assert.Equal(t, 2, second.Standings[2].LegPosition)
+	assert.Equal(t, "+5:00.0", *second.Standings[2].LegDifference)
+
+	// Last leg is from the last control to the finish
+	finish := result.Splits[2]
+	assert.Equal(t, "Control 2", finish.LegFrom)
+	assert.Equal(t, "9:00.0", *finish.BestLegTime)
+	assert.Equal(t, "Runner B", finish.Standings[0].Name)
+	assert.True(t, finish.Standings[0].BestLeg)
+	assert.Equal(t, "10:00.0", *finish.Standings[1].LegTime)
+}
+
+func TestService_UseClock(t *testing.T) {
+	appState := state.New()
+	class := models.Class{ID: 1, Name: "H21"}
+	start := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	comp := models.Competitor{ID: 1, Name: "Anna", Class: class, Status: "0", StartTime: start}
+	appState.UpdateFromMeOS(nil, nil, []models.Class{class}, nil, []models.Competitor{comp}, nil)
+
+	svc := New(appState)
+	clock := start.Add(-time.Minute)
+	svc.UseClock(func() time.Time { return clock })
+
+	// Before the start on the service's clock the competitor is waiting, even though the
+	// start is long past on the wall clock
+	results, err := svc.GetResults(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Waiting to Start", results[0].Status)
+
+	clock = start.Add(time.Minute)
+	results, err = svc.GetResults(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Running", results[0].Status)
+}
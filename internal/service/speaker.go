@@ -0,0 +1,144 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"meos-graphics/internal/models"
+)
+
+// finishControlID stands for the finish where controls are listed, as in the splits
+const finishControlID = -1
+
+// ClassLeader represents the current leader of a class
+type ClassLeader struct {
+	ClassID     int    `json:"classId"`
+	Class       string `json:"class"`
+	Name        string `json:"name,omitempty"` // Empty while nobody has finished
+	Club        string `json:"club,omitempty"`
+	RunningTime string `json:"runningTime,omitempty"` // Formatted duration string
+	Finished    int    `json:"finished"`              // Number of approved finishers
+	Running     int    `json:"running"`               // Number of competitors still out on the course
+	Overridden  bool   `json:"overridden,omitempty"`  // Set when an admin has overridden the leader's data
+}
+
+// ExpectedArrival represents a running competitor expected at a radio control or the finish
+type ExpectedArrival struct {
+	CompetitorID int    `json:"competitorId"`
+	Name         string `json:"name"`
+	Club         string `json:"club"`
+	ClassID      int    `json:"classId"`
+	Class        string `json:"class"`
+	ControlID    int    `json:"controlId"` // -1 for the finish
+	Control      string `json:"control"`
+	ExpectedTime string `json:"expectedTime"`         // Clock time, formatted as HH:mm:ss
+	ElapsedTime  string `json:"elapsedTime"`          // Expected formatted running time at the control
+	Overridden   bool   `json:"overridden,omitempty"` // Set when an admin has overridden the competitor's data
+}
+
+// GetClassLeaders returns the leader of every class, sorted by order key
+func (s *Service) GetClassLeaders() []ClassLeader {
+	snap := s.snapshot()
+	now := s.Now()
+
+	leaders := []ClassLeader{}
+	for _, class := range s.GetClasses() {
+		entry := ClassLeader{ClassID: class.ID, Class: class.Name}
+		var best *models.Competitor
+		for _, comp := range snap.CompetitorsByClass(class.ID) {
+			switch {
+			case comp.Status == "1" && comp.FinishTime != nil:
+				entry.Finished++
+				runTime := comp.FinishTime.Sub(comp.StartTime)
+				if best == nil || runTime < best.FinishTime.Sub(best.StartTime) ||
+					(runTime == best.FinishTime.Sub(best.StartTime) && comp.Name < best.Name) {
+					c := comp
+					best = &c
+				}
+			case running(comp, now):
+				entry.Running++
+			}
+		}
+		if best != nil {
+			entry.Name = best.Name
+			entry.Club = best.Club.Name
+			entry.RunningTime = FormatDuration(best.FinishTime.Sub(best.StartTime))
+			entry.Overridden = s.overridden(best.ID)
+		}
+		leaders = append(leaders, entry)
+	}
+	return leaders
+}
+
+// GetExpectedArrivals returns the running competitors expected at their next radio control
+// or the finish within the given time from now, soonest first, as predicted from their
+// time at the last radio control. Competitors are listed until a minute after they were due.
+func (s *Service) GetExpectedArrivals(within time.Duration) []ExpectedArrival {
+	snap := s.snapshot()
+	now := s.Now()
+
+	type arrival struct {
+		entry    ExpectedArrival
+		expected time.Time
+	}
+	var arrivals []arrival
+
+	for _, class := range snap.Classes() {
+		competitors := snap.CompetitorsByClass(class.ID)
+
+		for _, comp := range competitors {
+			if !running(comp, now) {
+				continue
+			}
+
+			// The next control is the one after the last radio control passed
+			next := 0
+			for i, ctrl := range class.RadioControls {
+				if _, ok := elapsedAt(comp, ctrl.ID); ok {
+					next = i + 1
+				}
+			}
+			control := models.Control{ID: finishControlID, Name: "Finish"}
+			if next < len(class.RadioControls) {
+				control = class.RadioControls[next]
+			}
+
+			elapsed, _, ok := predictTime(class, competitors, comp, control.ID)
+			if !ok {
+				continue
+			}
+			expected := comp.StartTime.Add(elapsed)
+			if expected.Before(now.Add(-time.Minute)) || expected.After(now.Add(within)) {
+				continue
+			}
+
+			arrivals = append(arrivals, arrival{
+				entry: ExpectedArrival{
+					CompetitorID: comp.ID,
+					Name:         comp.Name,
+					Club:         comp.Club.Name,
+					ClassID:      class.ID,
+					Class:        class.Name,
+					ControlID:    control.ID,
+					Control:      control.Name,
+					ExpectedTime: expected.Format("15:04:05"),
+					ElapsedTime:  FormatDuration(elapsed),
+					Overridden:   s.overridden(comp.ID),
+				},
+				expected: expected,
+			})
+		}
+	}
+
+	sort.SliceStable(arrivals, func(i, j int) bool { return arrivals[i].expected.Before(arrivals[j].expected) })
+	result := make([]ExpectedArrival, len(arrivals))
+	for i, a := range arrivals {
+		result[i] = a.entry
+	}
+	return result
+}
+
+// running reports whether a competitor has started and is still out on the course
+func running(comp models.Competitor, now time.Time) bool {
+	return comp.Status == "0" && comp.FinishTime == nil && !comp.StartTime.IsZero() && !now.Before(comp.StartTime)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meos-graphics/internal/models"
+	"meos-graphics/internal/state"
+)
+
+// setupSpeakerState creates a class with one radio control where the leader has finished
+// 30 minutes after the start, one runner is between the radio control and the finish and
+// one runner has not reached the radio control yet
+func setupSpeakerState(now time.Time) *state.State {
+	appState := state.New()
+
+	radio := models.Control{ID: 31, Name: "Radio"}
+	class := models.Class{ID: 1, Name: "H21", RadioControls: []models.Control{radio}}
+	empty := models.Class{ID: 2, Name: "D21", OrderKey: 1}
+	club := models.Club{ID: 1, Name: "Club A"}
+
+	leaderStart := now.Add(-time.Hour)
+	leaderFinish := leaderStart.Add(30 * time.Minute)
+	slowStart := now.Add(-20 * time.Minute)
+	lateStart := now.Add(-8 * time.Minute)
+
+	competitors := []models.Competitor{
+		{
+			ID: 1, Name: "Leader", Class: class, Club: club, Status: "1",
+			StartTime: leaderStart, FinishTime: &leaderFinish,
+			Splits: []models.Split{{Control: radio, PassingTime: leaderStart.Add(10 * time.Minute)}},
+		},
+		{
+			// 20% slower than the leader at the radio control, due at the finish in 16 minutes
+			ID: 2, Name: "Slow", Class: class, Club: club, Status: "0", StartTime: slowStart,
+			Splits: []models.Split{{Control: radio, PassingTime: slowStart.Add(12 * time.Minute)}},
+		},
+		{
+			// Due at the radio control in 3 minutes, at the average of the others' 10:00 and 12:00
+			ID: 3, Name: "Late", Class: class, Club: club, Status: "0", StartTime: lateStart,
+		},
+		{
+			ID: 4, Name: "Waiting", Class: class, Club: club, Status: "0", StartTime: now.Add(time.Hour),
+		},
+	}
+
+	appState.UpdateFromMeOS(nil, []models.Control{radio}, []models.Class{class, empty}, []models.Club{club}, competitors, nil)
+	return appState
+}
+
+func TestGetClassLeaders(t *testing.T) {
+	svc := New(setupSpeakerState(time.Now()))
+
+	leaders := svc.GetClassLeaders()
+	require.Len(t, leaders, 2)
+
+	assert.Equal(t, "H21", leaders[0].Class)
+	assert.Equal(t, "Leader", leaders[0].Name)
+	assert.Equal(t, "30:00.0", leaders[0].RunningTime)
+	assert.Equal(t, 1, leaders[0].Finished)
+	assert.Equal(t, 2, leaders[0].Running)
+
+	assert.Equal(t, "D21", leaders[1].Class)
+	assert.Empty(t, leaders[1].Name)
+}
+
+func TestGetExpectedArrivals(t *testing.T) {
+	svc := New(setupSpeakerState(time.Now()))
+
+	arrivals := svc.GetExpectedArrivals(5 * time.Minute)
+	require.Len(t, arrivals, 1)
+	assert.Equal(t, "Late", arrivals[0].Name)
+	assert.Equal(t, "Radio", arrivals[0].Control)
+	assert.Equal(t, "11:00.0", arrivals[0].ElapsedTime)
+
+	arrivals = svc.GetExpectedArrivals(20 * time.Minute)
+	require.Len(t, arrivals, 2)
+	assert.Equal(t, "Slow", arrivals[1].Name)
+	assert.Equal(t, finishControlID, arrivals[1].ControlID)
+	assert.Equal(t, "36:00.0", arrivals[1].ElapsedTime)
+}
@@ -42,14 +42,9 @@ func (a *Adapter) Connect() error {
 	baseTime := time.Now()
 	event, controls, classes, clubs, competitors := a.generator.GenerateInitialData(baseTime)
 
-	// Update state
-	a.state.Lock()
-	a.state.Event = &event
-	a.state.Controls = controls
-	a.state.Classes = classes
-	a.state.Clubs = clubs
-	a.state.Competitors = competitors
-	a.state.Unlock()
+	// Update state and notify listeners, so the initial data is audited and broadcast
+	// like any other update
+	a.state.UpdateFromMeOS(&event, controls, classes, clubs, competitors, a.state.GetTeams())
 
 	a.mu.Lock()
 	a.connected = true
@@ -116,7 +111,7 @@ func (a *Adapter) updateSimulation() {
 	clubs := a.state.GetClubs()
 
 	// Update state atomically and notify listeners
-	a.state.UpdateFromMeOS(event, controls, classes, clubs, competitors)
+	a.state.UpdateFromMeOS(event, controls, classes, clubs, competitors, a.state.GetTeams())
 
 	// Log phase changes
 	elapsed := currentTime.Sub(a.generator.startTime)
@@ -33,6 +33,8 @@ func TestAdapter_Connect(t *testing.T) {
 	}
 
 	appState := state.New()
+	var changes []state.Change
+	appState.OnChange(func(change state.Change) { changes = append(changes, change) })
 	adapter := NewAdapter(appState, 15*time.Minute, 3*time.Minute, 7*time.Minute, 5*time.Minute, false, 3, 20, 3)
 
 	err := adapter.Connect()
@@ -45,6 +47,11 @@ func TestAdapter_Connect(t *testing.T) {
 		t.Error("Adapter should be connected after Connect()")
 	}
 
+	// Listeners hear about the initial data
+	if len(changes) != 1 || !changes[0].Event || len(changes[0].Competitors) == 0 {
+		t.Errorf("Expected one change with the initial data, got %d", len(changes))
+	}
+
 	// Verify state was populated
 	event := appState.GetEvent()
 	if event == nil {
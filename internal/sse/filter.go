@@ -0,0 +1,99 @@
+package sse
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Filter selects the events a client receives. A client with an empty filter receives
+// every event. Events that carry no class, club or competitor IDs concern the whole event
+// and pass every ID filter.
+type Filter struct {
+	Types       []string
+	Classes     []int
+	Clubs       []int
+	Competitors []int
+}
+
+// ParseFilter reads a filter from the types, classes, clubs and competitors query
+// parameters. Each takes a comma separated list and may be repeated.
+func ParseFilter(query url.Values) (Filter, error) {
+	var filter Filter
+	filter.Types = splitList(query["types"])
+
+	var err error
+	if filter.Classes, err = parseIDs("class", query["classes"]); err != nil {
+		return Filter{}, err
+	}
+	if filter.Clubs, err = parseIDs("club", query["clubs"]); err != nil {
+		return Filter{}, err
+	}
+	if filter.Competitors, err = parseIDs("competitor", query["competitors"]); err != nil {
+		return Filter{}, err
+	}
+	return filter, nil
+}
+
+// Matches reports whether the event should be sent to a client with this filter. With
+// several ID filters an event matches when it concerns any of the subscribed entities.
+func (f Filter) Matches(event Event) bool {
+	if len(f.Types) > 0 && !containsString(f.Types, event.Type) {
+		return false
+	}
+
+	if len(f.Classes) == 0 && len(f.Clubs) == 0 && len(f.Competitors) == 0 {
+		return true
+	}
+	if len(event.ClassIDs) == 0 && len(event.ClubIDs) == 0 && len(event.CompetitorIDs) == 0 {
+		return true
+	}
+	return intersects(f.Classes, event.ClassIDs) ||
+		intersects(f.Clubs, event.ClubIDs) ||
+		intersects(f.Competitors, event.CompetitorIDs)
+}
+
+func splitList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+func parseIDs(name string, values []string) ([]int, error) {
+	var ids []int
+	for _, item := range splitList(values) {
+		id, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s ID %q", name, item)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(a, b []int) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
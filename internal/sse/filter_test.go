@@ -0,0 +1,62 @@
+package sse
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	query, _ := url.ParseQuery("types=class-updated,update&classes=1,2&classes=3&clubs=7&competitors=")
+	filter, err := ParseFilter(query)
+	if err != nil {
+		t.Fatalf("ParseFilter failed: %v", err)
+	}
+	if len(filter.Types) != 2 || filter.Types[0] != "class-updated" || filter.Types[1] != "update" {
+		t.Errorf("Types = %v, want [class-updated update]", filter.Types)
+	}
+	if len(filter.Classes) != 3 || filter.Classes[2] != 3 {
+		t.Errorf("Classes = %v, want [1 2 3]", filter.Classes)
+	}
+	if len(filter.Clubs) != 1 || filter.Clubs[0] != 7 {
+		t.Errorf("Clubs = %v, want [7]", filter.Clubs)
+	}
+	if len(filter.Competitors) != 0 {
+		t.Errorf("Competitors = %v, want none", filter.Competitors)
+	}
+
+	query, _ = url.ParseQuery("classes=1,abc")
+	if _, err := ParseFilter(query); err == nil || err.Error() != `invalid class ID "abc"` {
+		t.Errorf("ParseFilter error = %v, want invalid class ID", err)
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	classEvent := Event{Type: "class-updated", ClassIDs: []int{1, 2}, ClubIDs: []int{7}, CompetitorIDs: []int{11}}
+	globalEvent := Event{Type: "source-status"}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		event  Event
+		want   bool
+	}{
+		{"empty filter", Filter{}, classEvent, true},
+		{"matching class", Filter{Classes: []int{2}}, classEvent, true},
+		{"other class", Filter{Classes: []int{3}}, classEvent, false},
+		{"matching club", Filter{Clubs: []int{7}}, classEvent, true},
+		{"matching competitor", Filter{Competitors: []int{11}}, classEvent, true},
+		{"any subscription matches", Filter{Classes: []int{3}, Competitors: []int{11}}, classEvent, true},
+		{"event-wide event", Filter{Classes: []int{3}}, globalEvent, true},
+		{"matching type", Filter{Types: []string{"class-updated"}}, classEvent, true},
+		{"other type", Filter{Types: []string{"update"}}, classEvent, false},
+		{"type and class", Filter{Types: []string{"class-updated"}, Classes: []int{3}}, classEvent, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.event); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package sse
+
+// historySize is how many recent events the hub keeps for clients that reconnect
+const historySize = 256
+
+// history is a ring buffer of the most recent events in the order they were broadcast.
+// Event IDs in the buffer are consecutive.
+type history struct {
+	events []Event
+	start  int
+	size   int
+}
+
+func newHistory(capacity int) *history {
+	return &history{events: make([]Event, capacity)}
+}
+
+// add stores an event, overwriting the oldest one when the buffer is full
+func (h *history) add(event Event) {
+	if h.size < len(h.events) {
+		h.events[(h.start+h.size)%len(h.events)] = event
+		h.size++
+		return
+	}
+	h.events[h.start] = event
+	h.start = (h.start + 1) % len(h.events)
+}
+
+// since returns the events broadcast after the event with the given ID, or false when some
+// of them are no longer in the buffer and the client has to reload everything
+func (h *history) since(id, lastID uint64) ([]Event, bool) {
+	if id > lastID {
+		// The client saw IDs this run has not sent
+		return nil, false
+	}
+	if id == lastID {
+		return nil, true
+	}
+	if h.size == 0 || id+1 < h.events[h.start].ID {
+		return nil, false
+	}
+
+	var events []Event
+	for i := 0; i < h.size; i++ {
+		event := h.events[(h.start+i)%len(h.events)]
+		if event.ID > id {
+			events = append(events, event)
+		}
+	}
+	return events, true
+}
@@ -0,0 +1,40 @@
+package sse
+
+import "testing"
+
+func TestHistory_Since(t *testing.T) {
+	h := newHistory(3)
+	for id := uint64(1); id <= 5; id++ {
+		h.add(Event{ID: id, Type: "update"})
+	}
+
+	tests := []struct {
+		name    string
+		id      uint64
+		wantIDs []uint64
+		wantOK  bool
+	}{
+		{"up to date", 5, nil, true},
+		{"missed one", 4, []uint64{5}, true},
+		{"missed all kept", 2, []uint64{3, 4, 5}, true},
+		{"missed evicted events", 1, nil, false},
+		{"ID from before a restart", 9, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, ok := h.since(tt.id, 5)
+			if ok != tt.wantOK {
+				t.Fatalf("since(%d) ok = %v, want %v", tt.id, ok, tt.wantOK)
+			}
+			if len(events) != len(tt.wantIDs) {
+				t.Fatalf("since(%d) returned %d events, want %d", tt.id, len(events), len(tt.wantIDs))
+			}
+			for i, event := range events {
+				if event.ID != tt.wantIDs[i] {
+					t.Errorf("event %d has ID %d, want %d", i, event.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
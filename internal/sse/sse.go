@@ -3,12 +3,19 @@ package sse
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	ginsse "github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 
+	"meos-graphics/internal/feed"
 	"meos-graphics/internal/logger"
+	"meos-graphics/internal/state"
 )
 
 // Client represents a connected SSE client
@@ -16,13 +23,30 @@ type Client struct {
 	ID      string
 	Channel chan Event
 	Closed  bool
+	Filter  Filter
 	mu      sync.RWMutex
+
+	// Set when the client reconnects with a Last-Event-ID header
+	resume      bool
+	lastEventID uint64
 }
 
-// Event represents an SSE event
+// clientBuffer is how many events can queue up for a client before it is disconnected to
+// reconnect with its Last-Event-ID
+const clientBuffer = 10
+
+// Event represents an SSE event. The hub assigns each broadcast event an increasing ID,
+// sent prefixed with the hub's epoch.
+// The class, club and competitor IDs route the event to clients subscribed to them and
+// are not sent.
 type Event struct {
+	ID   uint64      `json:"-"`
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
+
+	ClassIDs      []int `json:"-"`
+	ClubIDs       []int `json:"-"`
+	CompetitorIDs []int `json:"-"`
 }
 
 // Hub manages SSE clients
@@ -32,6 +56,13 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
+
+	// epoch distinguishes the event IDs of different runs of the server, as IDs restart at 1
+	epoch string
+
+	// Recent events for clients that reconnect, owned by Run
+	history *history
+	lastID  uint64
 }
 
 // NewHub creates a new SSE hub
@@ -41,6 +72,8 @@ func NewHub() *Hub {
 		broadcast:  make(chan Event, 100),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		epoch:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		history:    newHistory(historySize),
 	}
 }
 
@@ -51,24 +84,28 @@ func (h *Hub) Run() {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client.ID] = client
+			if client.resume {
+				h.replay(client)
+			}
 			h.mu.Unlock()
 			logger.InfoLogger.Printf("SSE client registered: %s", client.ID)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
 			if _, ok := h.clients[client.ID]; ok {
-				client.mu.Lock()
-				client.Closed = true
-				close(client.Channel)
-				client.mu.Unlock()
-				delete(h.clients, client.ID)
+				h.remove(client)
 				logger.InfoLogger.Printf("SSE client unregistered: %s", client.ID)
 			}
 			h.mu.Unlock()
 
 		case event := <-h.broadcast:
+			h.lastID++
+			event.ID = h.lastID
+			h.history.add(event)
+
+			var full []*Client
 			h.mu.RLock()
-			for id, client := range h.clients {
+			for _, client := range h.clients {
 				client.mu.RLock()
 				if client.Closed {
 					client.mu.RUnlock()
@@ -76,25 +113,92 @@ func (h *Hub) Run() {
 				}
 				client.mu.RUnlock()
 
+				if !client.Filter.Matches(event) {
+					continue
+				}
+
 				select {
 				case client.Channel <- event:
 				default:
-					// Client's channel is full, skip this event
-					logger.DebugLogger.Printf("SSE client %s channel full, skipping event", id)
+					full = append(full, client)
 				}
 			}
 			h.mu.RUnlock()
+
+			// Skipping the event would leave a gap the client never learns about, so it is
+			// disconnected after the events it has queued and resumes from the last of them
+			if len(full) > 0 {
+				h.mu.Lock()
+				for _, client := range full {
+					logger.InfoLogger.Printf("SSE client %s is not keeping up, disconnecting it", client.ID)
+					h.remove(client)
+				}
+				h.mu.Unlock()
+			}
 		}
 	}
 }
 
+// remove closes a client's channel, which ends its stream once the queued events are sent,
+// and forgets the client. The caller must hold the write lock.
+func (h *Hub) remove(client *Client) {
+	client.mu.Lock()
+	client.Closed = true
+	close(client.Channel)
+	client.mu.Unlock()
+	delete(h.clients, client.ID)
+}
+
+// replay queues the events a reconnecting client missed. When they are no longer all in
+// the history the client gets a resync event and has to reload everything instead.
+func (h *Hub) replay(client *Client) {
+	events, ok := h.history.since(client.lastEventID, h.lastID)
+	if !ok {
+		logger.InfoLogger.Printf("SSE client %s missed too many events, sending resync", client.ID)
+		client.Channel <- Event{ID: h.lastID, Type: "resync", Data: gin.H{"lastEventId": h.formatID(h.lastID)}}
+		return
+	}
+
+	for _, event := range events {
+		if !client.Filter.Matches(event) {
+			continue
+		}
+		select {
+		case client.Channel <- event:
+		default:
+			logger.InfoLogger.Printf("SSE client %s is not keeping up with the replay, disconnecting it", client.ID)
+			h.remove(client)
+			return
+		}
+	}
+}
+
+// formatID returns the event ID as sent to clients
+func (h *Hub) formatID(id uint64) string {
+	return h.epoch + "-" + strconv.FormatUint(id, 10)
+}
+
+// parseID returns the number of an event ID sent by this hub, or false for an ID from
+// another run of the server or one it never sent
+func (h *Hub) parseID(value string) (uint64, bool) {
+	epoch, number, found := strings.Cut(value, "-")
+	if !found || epoch != h.epoch {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(number, 10, 64)
+	return id, err == nil
+}
+
 // BroadcastUpdate sends an update event to all connected clients
 func (h *Hub) BroadcastUpdate(eventType string, data interface{}) {
-	event := Event{
+	h.Broadcast(Event{
 		Type: eventType,
 		Data: data,
-	}
+	})
+}
 
+// Broadcast sends an event to the clients whose filter matches it
+func (h *Hub) Broadcast(event Event) {
 	select {
 	case h.broadcast <- event:
 	default:
@@ -102,8 +206,80 @@ func (h *Hub) BroadcastUpdate(eventType string, data interface{}) {
 	}
 }
 
-// HandleSSE handles SSE connections
+// ClassUpdate is the payload of a class-updated event
+type ClassUpdate struct {
+	Revision      uint64 `json:"revision"`
+	ClassIDs      []int  `json:"classIds"`
+	CompetitorIDs []int  `json:"competitorIds,omitempty"`
+	TeamIDs       []int  `json:"teamIds,omitempty"`
+}
+
+// BroadcastChange sends an update event for a state change, followed by a class-updated
+// event naming the classes that changed. Changes to the event details or the class list
+// go to every client; other changes only to clients subscribed to what changed.
+func (h *Hub) BroadcastChange(change state.Change) {
+	update := Event{
+		Type: "update",
+		Data: gin.H{
+			"timestamp": time.Now().Unix(),
+			"revision":  change.Revision,
+			"event":     change.Event,
+		},
+	}
+	if !change.Event {
+		update.ClassIDs = change.Classes
+		update.ClubIDs = change.Clubs
+		update.CompetitorIDs = change.Competitors
+	}
+	h.Broadcast(update)
+
+	if len(change.Classes) == 0 {
+		return
+	}
+	h.Broadcast(Event{
+		Type: "class-updated",
+		Data: ClassUpdate{
+			Revision:      change.Revision,
+			ClassIDs:      change.Classes,
+			CompetitorIDs: change.Competitors,
+			TeamIDs:       change.Teams,
+		},
+		ClassIDs:      change.Classes,
+		ClubIDs:       change.Clubs,
+		CompetitorIDs: change.Competitors,
+	})
+}
+
+// BroadcastFeed sends each competitor feed item as an event of the item's type
+func (h *Hub) BroadcastFeed(items []feed.Item) {
+	for _, item := range items {
+		event := Event{
+			Type:          item.Type,
+			Data:          item,
+			ClassIDs:      []int{item.ClassID},
+			CompetitorIDs: []int{item.CompetitorID},
+		}
+		if item.ClubID != 0 {
+			event.ClubIDs = []int{item.ClubID}
+		}
+		if item.PreviousLeaderID != 0 {
+			event.CompetitorIDs = append(event.CompetitorIDs, item.PreviousLeaderID)
+		}
+		h.Broadcast(event)
+	}
+}
+
+// HandleSSE handles SSE connections. The types, classes, clubs and competitors query
+// parameters limit the events the client receives. A client that reconnects with a
+// Last-Event-ID header first gets the events it missed, or a resync event when they are
+// too old to replay. A client that falls behind is disconnected to reconnect that way.
 func (h *Hub) HandleSSE(c *gin.Context) {
+	filter, err := ParseFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Set headers for SSE
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -114,7 +290,18 @@ func (h *Hub) HandleSSE(c *gin.Context) {
 	clientID := fmt.Sprintf("%d", time.Now().UnixNano())
 	client := &Client{
 		ID:      clientID,
-		Channel: make(chan Event, 10),
+		Channel: make(chan Event, clientBuffer),
+		Filter:  filter,
+	}
+	if header := c.GetHeader("Last-Event-ID"); header != "" {
+		client.resume = true
+		// Leave room for the replayed events
+		client.Channel = make(chan Event, clientBuffer+historySize)
+		var ok bool
+		if client.lastEventID, ok = h.parseID(header); !ok {
+			// An ID this run never sent cannot be resumed from
+			client.lastEventID = math.MaxUint64
+		}
 	}
 
 	// Register client
@@ -151,7 +338,11 @@ func (h *Hub) HandleSSE(c *gin.Context) {
 				continue
 			}
 
-			c.SSEvent(event.Type, string(data))
+			c.Render(-1, ginsse.Event{
+				Id:    h.formatID(event.ID),
+				Event: event.Type,
+				Data:  string(data),
+			})
 			c.Writer.Flush()
 
 		case <-time.After(30 * time.Second):
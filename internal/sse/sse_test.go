@@ -2,6 +2,7 @@ package sse
 
 import (
 	"bufio"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -101,7 +102,7 @@ func TestSSEIntegration(t *testing.T) {
 			},
 		}
 
-		appState.UpdateFromMeOS(event, controls, classes, clubs, competitors)
+		appState.UpdateFromMeOS(event, controls, classes, clubs, competitors, nil)
 
 		// Wait for update event
 		timeout = time.After(2 * time.Second)
@@ -287,7 +288,7 @@ func TestSSEMultipleClients(t *testing.T) {
 				StartTime: time.Now(),
 			},
 		}
-		appState.UpdateFromMeOS(nil, nil, nil, nil, competitors)
+		appState.UpdateFromMeOS(nil, nil, nil, nil, competitors, nil)
 
 		// Verify all clients receive the update
 		timeout := time.After(2 * time.Second)
@@ -440,3 +441,293 @@ func TestSSEDisconnectCleanup(t *testing.T) {
 		}
 	})
 }
+
+func TestSSEClassUpdated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	appState := state.New()
+	sseHub := NewHub()
+	go sseHub.Run()
+	appState.OnChange(sseHub.BroadcastChange)
+
+	classes := []models.Class{{ID: 1, Name: "Class 1"}, {ID: 2, Name: "Class 2"}}
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Runner 1", Status: "0", Class: classes[0]},
+		{ID: 2, Name: "Runner 2", Status: "0", Class: classes[1]},
+	}
+	appState.UpdateFromMeOS(&models.Event{Name: "Test Event"}, nil, classes, nil, competitors, nil)
+
+	router := gin.New()
+	router.GET("/sse", sseHub.HandleSSE)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/sse")
+	if err != nil {
+		t.Fatalf("Failed to connect to SSE: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Collect event names with their data
+	lines := make(chan string, 20)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		var name string
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "event:") {
+				name = strings.TrimPrefix(line, "event:")
+			} else if strings.HasPrefix(line, "data:") {
+				lines <- name + " " + strings.TrimPrefix(line, "data:")
+			}
+		}
+		close(lines)
+	}()
+
+	next := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for event")
+			return ""
+		}
+	}
+
+	if line := next(); !strings.HasPrefix(line, "connected") {
+		t.Fatalf("Expected connected event, got: %s", line)
+	}
+
+	// Finish the runner in class 2
+	finish := time.Now()
+	competitors[1].Status = "1"
+	competitors[1].FinishTime = &finish
+	appState.UpdateFromMeOS(&models.Event{Name: "Test Event"}, nil, classes, nil, competitors, nil)
+
+	if line := next(); !strings.HasPrefix(line, "update ") || !strings.Contains(line, `"revision":2`) {
+		t.Errorf("Expected update event with revision 2, got: %s", line)
+	}
+	line := next()
+	if !strings.HasPrefix(line, "class-updated ") {
+		t.Fatalf("Expected class-updated event, got: %s", line)
+	}
+	if !strings.Contains(line, `"classIds":[2]`) || !strings.Contains(line, `"competitorIds":[2]`) {
+		t.Errorf("class-updated should name class 2 and competitor 2, got: %s", line)
+	}
+}
+
+func TestSSEFilteredSubscription(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	appState := state.New()
+	sseHub := NewHub()
+	go sseHub.Run()
+	appState.OnChange(sseHub.BroadcastChange)
+
+	classes := []models.Class{{ID: 1, Name: "Class 1"}, {ID: 2, Name: "Class 2"}}
+	competitors := []models.Competitor{
+		{ID: 1, Name: "Runner 1", Status: "0", Class: classes[0]},
+		{ID: 2, Name: "Runner 2", Status: "0", Class: classes[1]},
+	}
+	appState.UpdateFromMeOS(&models.Event{Name: "Test Event"}, nil, classes, nil, competitors, nil)
+
+	router := gin.New()
+	router.GET("/sse", sseHub.HandleSSE)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	t.Run("RejectsInvalidFilter", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/sse?classes=abc")
+		if err != nil {
+			t.Fatalf("Failed to connect to SSE: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ReceivesOnlySubscribedClass", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/sse?types=class-updated&classes=1")
+		if err != nil {
+			t.Fatalf("Failed to connect to SSE: %v", err)
+		}
+		defer resp.Body.Close()
+
+		lines := make(chan string, 20)
+		go func() {
+			scanner := bufio.NewScanner(resp.Body)
+			var name string
+			for scanner.Scan() {
+				line := scanner.Text()
+				if strings.HasPrefix(line, "event:") {
+					name = strings.TrimPrefix(line, "event:")
+				} else if strings.HasPrefix(line, "data:") {
+					lines <- name + " " + strings.TrimPrefix(line, "data:")
+				}
+			}
+			close(lines)
+		}()
+
+		next := func() string {
+			select {
+			case line := <-lines:
+				return line
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timeout waiting for event")
+				return ""
+			}
+		}
+
+		if line := next(); !strings.HasPrefix(line, "connected") {
+			t.Fatalf("Expected connected event, got: %s", line)
+		}
+
+		// A change in class 2 must not reach the client, a change in class 1 must
+		finish := time.Now()
+		competitors[1].Status = "1"
+		competitors[1].FinishTime = &finish
+		appState.UpdateFromMeOS(&models.Event{Name: "Test Event"}, nil, classes, nil, competitors, nil)
+		competitors[0].Status = "1"
+		competitors[0].FinishTime = &finish
+		appState.UpdateFromMeOS(&models.Event{Name: "Test Event"}, nil, classes, nil, competitors, nil)
+
+		line := next()
+		if !strings.HasPrefix(line, "class-updated ") || !strings.Contains(line, `"classIds":[1]`) {
+			t.Errorf("Expected class-updated event for class 1 only, got: %s", line)
+		}
+	})
+}
+
+func TestSSELastEventIDReplay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sseHub := NewHub()
+	go sseHub.Run()
+
+	router := gin.New()
+	router.GET("/sse", sseHub.HandleSSE)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// connect opens a stream and returns its events as "id event data" lines
+	connect := func(lastEventID string) (chan string, func()) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/sse", nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to connect to SSE: %v", err)
+		}
+
+		lines := make(chan string, 20)
+		go func() {
+			scanner := bufio.NewScanner(resp.Body)
+			var id, name string
+			for scanner.Scan() {
+				line := scanner.Text()
+				switch {
+				case strings.HasPrefix(line, "id:"):
+					id = strings.TrimPrefix(line, "id:")
+				case strings.HasPrefix(line, "event:"):
+					name = strings.TrimPrefix(line, "event:")
+				case strings.HasPrefix(line, "data:"):
+					lines <- id + " " + name + " " + strings.TrimPrefix(line, "data:")
+					id = ""
+				}
+			}
+			close(lines)
+		}()
+		return lines, func() { resp.Body.Close() }
+	}
+
+	next := func(lines chan string) string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for event")
+			return ""
+		}
+	}
+
+	// Receive three numbered events on a first connection
+	lines, disconnect := connect("")
+	if line := next(lines); !strings.Contains(line, "connected") {
+		t.Fatalf("Expected connected event, got: %s", line)
+	}
+	for i := 0; i < 3; i++ {
+		sseHub.BroadcastUpdate("update", gin.H{"n": i})
+	}
+	for i := 1; i <= 3; i++ {
+		if line := next(lines); !strings.HasPrefix(line, fmt.Sprintf("%s-%d update ", sseHub.epoch, i)) {
+			t.Errorf("Expected update event with ID %d, got: %s", i, line)
+		}
+	}
+	disconnect()
+
+	t.Run("ReplaysMissedEvents", func(t *testing.T) {
+		lines, disconnect := connect(sseHub.epoch + "-1")
+		defer disconnect()
+
+		if line := next(lines); !strings.Contains(line, "connected") {
+			t.Fatalf("Expected connected event, got: %s", line)
+		}
+		if line := next(lines); line != sseHub.epoch+`-2 update {"n":1}` {
+			t.Errorf("Expected replayed event 2, got: %s", line)
+		}
+		if line := next(lines); line != sseHub.epoch+`-3 update {"n":2}` {
+			t.Errorf("Expected replayed event 3, got: %s", line)
+		}
+	})
+
+	// IDs this run never sent, including ones from an earlier run with the same number
+	for _, id := range []string{sseHub.epoch + "-1000", "other-3", "other-1", "3", "garbage"} {
+		t.Run("Resyncs "+id, func(t *testing.T) {
+			lines, disconnect := connect(id)
+			defer disconnect()
+
+			if line := next(lines); !strings.Contains(line, "connected") {
+				t.Fatalf("Expected connected event, got: %s", line)
+			}
+			if line := next(lines); !strings.HasPrefix(line, sseHub.epoch+"-3 resync ") {
+				t.Errorf("Expected resync event, got: %s", line)
+			}
+		})
+	}
+}
+
+func TestSSESlowClient(t *testing.T) {
+	sseHub := NewHub()
+	go sseHub.Run()
+
+	client := &Client{ID: "slow", Channel: make(chan Event, clientBuffer)}
+	sseHub.register <- client
+
+	// One event more than the client has room for
+	for i := 0; i <= clientBuffer; i++ {
+		sseHub.BroadcastUpdate("update", gin.H{"n": i})
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sseHub.GetConnectedClients() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Slow client was not disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The queued events arrive in order before the stream ends, so the client can resume
+	// from the last of them
+	var ids []uint64
+	for event := range client.Channel {
+		ids = append(ids, event.ID)
+	}
+	if len(ids) != clientBuffer || ids[0] != 1 || ids[len(ids)-1] != clientBuffer {
+		t.Fatalf("Queued event IDs = %v, want 1 to %d", ids, clientBuffer)
+	}
+	if events, ok := sseHub.history.since(ids[len(ids)-1], sseHub.lastID); !ok || len(events) != 1 {
+		t.Errorf("Expected the missed event to be replayable, got %d events, ok %v", len(events), ok)
+	}
+}
@@ -0,0 +1,285 @@
+package state
+
+import (
+	"sort"
+
+	"meos-graphics/internal/models"
+)
+
+// Change describes what an update changed, so listeners can refresh only the classes and
+// competitors that are affected
+type Change struct {
+	Revision uint64 `json:"revision"`
+	// Event is set when the event details or the list of classes changed
+	Event       bool  `json:"event,omitempty"`
+	Classes     []int `json:"classes"`
+	Competitors []int `json:"competitors,omitempty"`
+	Teams       []int `json:"teams,omitempty"`
+	Controls    []int `json:"controls,omitempty"`
+	// Clubs lists the clubs whose details changed or that have a changed competitor or team
+	Clubs []int `json:"clubs,omitempty"`
+
+	// Previous and Current are the snapshots before and after the update, so listeners can
+	// compare exactly what it changed even when further updates have been made since
+	Previous *Snapshot `json:"-"`
+	Current  *Snapshot `json:"-"`
+}
+
+// Empty reports whether nothing changed
+func (c Change) Empty() bool {
+	return !c.Event && len(c.Classes) == 0 && len(c.Competitors) == 0 && len(c.Teams) == 0 &&
+		len(c.Controls) == 0 && len(c.Clubs) == 0
+}
+
+// idSet collects changed IDs
+type idSet map[int]struct{}
+
+func (s idSet) add(ids ...int) {
+	for _, id := range ids {
+		s[id] = struct{}{}
+	}
+}
+
+func (s idSet) has(id int) bool {
+	_, ok := s[id]
+	return ok
+}
+
+func (s idSet) sorted() []int {
+	if len(s) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Diff returns what changed from one snapshot to another
+func Diff(prev, current *Snapshot) Change {
+	change := computeChange(prev, current.event, current.controls, current.classes, current.clubs, current.competitors, current.teams)
+	change.Revision = current.revision
+	change.Previous, change.Current = prev, current
+	return change
+}
+
+// computeChange compares the new data against the current snapshot. A class counts as
+// changed when its definition changed or when any of its competitors or teams was added,
+// removed or changed, including through a renamed club or control.
+func computeChange(current *Snapshot, event *models.Event, controls []models.Control, classes []models.Class, clubs []models.Club, competitors []models.Competitor, teams []models.Team) Change {
+	change := Change{Event: eventChanged(current.event, event)}
+	changedClasses := idSet{}
+	changedControls := idSet{}
+	changedClubs := idSet{}
+	renamedClubs := idSet{}
+	changedCompetitors := idSet{}
+	changedTeams := idSet{}
+
+	seen := idSet{}
+	for _, ctrl := range controls {
+		seen.add(ctrl.ID)
+		if existing, ok := current.Control(ctrl.ID); !ok || existing.Name != ctrl.Name {
+			changedControls.add(ctrl.ID)
+		}
+	}
+	for _, ctrl := range current.controls {
+		if !seen.has(ctrl.ID) {
+			changedControls.add(ctrl.ID)
+		}
+	}
+
+	seen = idSet{}
+	for _, club := range clubs {
+		seen.add(club.ID)
+		if existing, ok := current.Club(club.ID); !ok || existing != club {
+			renamedClubs.add(club.ID)
+		}
+	}
+	for _, club := range current.clubs {
+		if !seen.has(club.ID) {
+			renamedClubs.add(club.ID)
+		}
+	}
+	changedClubs.add(renamedClubs.sorted()...)
+
+	seen = idSet{}
+	for _, class := range classes {
+		seen.add(class.ID)
+		existing, ok := current.Class(class.ID)
+		if !ok || existing.Name != class.Name || existing.OrderKey != class.OrderKey {
+			change.Event = true
+			changedClasses.add(class.ID)
+			continue
+		}
+		if controlsDiffer(existing.RadioControls, class.RadioControls, changedControls) {
+			changedClasses.add(class.ID)
+		}
+	}
+	for _, class := range current.classes {
+		if !seen.has(class.ID) {
+			change.Event = true
+			changedClasses.add(class.ID)
+		}
+	}
+
+	seen = idSet{}
+	for _, comp := range competitors {
+		seen.add(comp.ID)
+		existing, ok := current.Competitor(comp.ID)
+		switch {
+		case !ok:
+			changedCompetitors.add(comp.ID)
+			changedClasses.add(comp.Class.ID)
+			changedClubs.add(comp.Club.ID)
+		case competitorChanged(existing, comp) || renamedClubs.has(comp.Club.ID) || splitsAffected(comp.Splits, changedControls):
+			changedCompetitors.add(comp.ID)
+			changedClasses.add(existing.Class.ID, comp.Class.ID)
+			changedClubs.add(existing.Club.ID, comp.Club.ID)
+		}
+	}
+	for _, comp := range current.competitors {
+		if !seen.has(comp.ID) {
+			changedCompetitors.add(comp.ID)
+			changedClasses.add(comp.Class.ID)
+			changedClubs.add(comp.Club.ID)
+		}
+	}
+
+	seen = idSet{}
+	for _, team := range teams {
+		seen.add(team.ID)
+		existing, ok := current.Team(team.ID)
+		switch {
+		case !ok:
+			changedTeams.add(team.ID)
+			changedClasses.add(team.Class.ID)
+			changedClubs.add(team.Club.ID)
+		case teamChanged(existing, team) || renamedClubs.has(team.Club.ID):
+			changedTeams.add(team.ID)
+			changedClasses.add(existing.Class.ID, team.Class.ID)
+			changedClubs.add(existing.Club.ID, team.Club.ID)
+		}
+	}
+	for _, team := range current.teams {
+		if !seen.has(team.ID) {
+			changedTeams.add(team.ID)
+			changedClasses.add(team.Class.ID)
+			changedClubs.add(team.Club.ID)
+		}
+	}
+
+	// Competitors without a class or club do not belong to a page that could be refreshed
+	delete(changedClasses, 0)
+	delete(changedClubs, 0)
+
+	change.Classes = changedClasses.sorted()
+	change.Competitors = changedCompetitors.sorted()
+	change.Teams = changedTeams.sorted()
+	change.Controls = changedControls.sorted()
+	change.Clubs = changedClubs.sorted()
+	if change.Classes == nil {
+		change.Classes = []int{}
+	}
+	return change
+}
+
+// eventChanged reports whether two events differ
+func eventChanged(current, updated *models.Event) bool {
+	if current == nil || updated == nil {
+		return current != updated
+	}
+	return current.Name != updated.Name || current.Organizer != updated.Organizer || current.Start != updated.Start
+}
+
+// controlsDiffer reports whether two radio control lists differ or use a changed control
+func controlsDiffer(current, updated []models.Control, changedControls idSet) bool {
+	if len(current) != len(updated) {
+		return true
+	}
+	for i := range updated {
+		if current[i].ID != updated[i].ID || current[i].Name != updated[i].Name || changedControls.has(updated[i].ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitsAffected reports whether any split was taken at a changed control
+func splitsAffected(splits []models.Split, changedControls idSet) bool {
+	if len(changedControls) == 0 {
+		return false
+	}
+	for _, split := range splits {
+		if changedControls.has(split.Control.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// competitorChanged reports whether any field shown for a competitor differs
+func competitorChanged(current, updated models.Competitor) bool {
+	if current.Status != updated.Status ||
+		current.Card != updated.Card ||
+		current.Bib != updated.Bib ||
+		current.Name != updated.Name ||
+		current.StartTime != updated.StartTime ||
+		current.Class.ID != updated.Class.ID ||
+		current.Club.ID != updated.Club.ID ||
+		current.TeamID != updated.TeamID ||
+		current.Leg != updated.Leg {
+		return true
+	}
+
+	if (current.FinishTime == nil) != (updated.FinishTime == nil) {
+		return true
+	}
+	if current.FinishTime != nil && *current.FinishTime != *updated.FinishTime {
+		return true
+	}
+
+	if len(current.Splits) != len(updated.Splits) {
+		return true
+	}
+	for j := range updated.Splits {
+		if current.Splits[j].Control.ID != updated.Splits[j].Control.ID ||
+			current.Splits[j].PassingTime != updated.Splits[j].PassingTime {
+			return true
+		}
+	}
+	return false
+}
+
+// teamChanged reports whether any field shown for a team differs
+func teamChanged(current, updated models.Team) bool {
+	if current.Name != updated.Name ||
+		current.Bib != updated.Bib ||
+		current.Status != updated.Status ||
+		current.StartTime != updated.StartTime ||
+		current.Class.ID != updated.Class.ID ||
+		current.Club.ID != updated.Club.ID {
+		return true
+	}
+	if (current.FinishTime == nil) != (updated.FinishTime == nil) {
+		return true
+	}
+	if current.FinishTime != nil && *current.FinishTime != *updated.FinishTime {
+		return true
+	}
+	if len(current.Legs) != len(updated.Legs) {
+		return true
+	}
+	for i := range updated.Legs {
+		if len(current.Legs[i].CompetitorIDs) != len(updated.Legs[i].CompetitorIDs) {
+			return true
+		}
+		for j := range updated.Legs[i].CompetitorIDs {
+			if current.Legs[i].CompetitorIDs[j] != updated.Legs[i].CompetitorIDs[j] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package state
+
+import (
+	"sort"
+	"time"
+
+	"meos-graphics/internal/models"
+)
+
+// Snapshot is an immutable view of the state indexed by ID and class. Readers can hold on
+// to a snapshot for as long as they like; later updates publish a new one. The slices it
+// returns are shared between all readers and must not be modified.
+type Snapshot struct {
+	revision uint64
+
+	event       *models.Event
+	controls    []models.Control
+	classes     []models.Class
+	clubs       []models.Club
+	competitors []models.Competitor
+	teams       []models.Team
+
+	controlIndex    map[int]int
+	classIndex      map[int]int
+	clubIndex       map[int]int
+	competitorIndex map[int]int
+	teamIndex       map[int]int
+
+	// Positions of the competitors and teams of each class, in state order
+	competitorsByClass map[int][]int
+	teamsByClass       map[int][]int
+
+	// Start times of the competitors and teams in ascending order
+	startTimes []time.Time
+}
+
+// NewSnapshot builds a snapshot of the given data at a revision, for views of the state
+// other than the current one
+func NewSnapshot(revision uint64, event *models.Event, controls []models.Control, classes []models.Class, clubs []models.Club, competitors []models.Competitor, teams []models.Team) *Snapshot {
+	return newSnapshot(revision, event, controls, classes, clubs, competitors, teams)
+}
+
+// newSnapshot copies the given data and indexes it
+func newSnapshot(revision uint64, event *models.Event, controls []models.Control, classes []models.Class, clubs []models.Club, competitors []models.Competitor, teams []models.Team) *Snapshot {
+	snap := &Snapshot{
+		revision: revision,
+
+		controls:    append([]models.Control{}, controls...),
+		classes:     append([]models.Class{}, classes...),
+		clubs:       append([]models.Club{}, clubs...),
+		competitors: append([]models.Competitor{}, competitors...),
+		teams:       append([]models.Team{}, teams...),
+
+		controlIndex:    make(map[int]int, len(controls)),
+		classIndex:      make(map[int]int, len(classes)),
+		clubIndex:       make(map[int]int, len(clubs)),
+		competitorIndex: make(map[int]int, len(competitors)),
+		teamIndex:       make(map[int]int, len(teams)),
+	}
+	snap.event = copyEvent(event)
+
+	for i, ctrl := range snap.controls {
+		snap.controlIndex[ctrl.ID] = i
+	}
+	for i, class := range snap.classes {
+		snap.classIndex[class.ID] = i
+	}
+	for i, club := range snap.clubs {
+		snap.clubIndex[club.ID] = i
+	}
+	for i, comp := range snap.competitors {
+		snap.competitorIndex[comp.ID] = i
+		if !comp.StartTime.IsZero() {
+			snap.startTimes = append(snap.startTimes, comp.StartTime)
+		}
+	}
+	for i, team := range snap.teams {
+		snap.teamIndex[team.ID] = i
+		if !team.StartTime.IsZero() {
+			snap.startTimes = append(snap.startTimes, team.StartTime)
+		}
+	}
+	sort.Slice(snap.startTimes, func(i, j int) bool { return snap.startTimes[i].Before(snap.startTimes[j]) })
+	snap.competitorsByClass = groupByClass(snap.competitors, func(comp models.Competitor) int { return comp.Class.ID })
+	snap.teamsByClass = groupByClass(snap.teams, func(team models.Team) int { return team.Class.ID })
+
+	return snap
+}
+
+// withEvent returns a copy of the snapshot with a different event and revision
+func (s *Snapshot) withEvent(revision uint64, event *models.Event) *Snapshot {
+	snap := *s
+	snap.revision = revision
+	snap.event = copyEvent(event)
+	return &snap
+}
+
+// groupByClass returns the positions of the entities of each class
+func groupByClass[T any](entities []T, classID func(T) int) map[int][]int {
+	groups := make(map[int][]int)
+	for i, entity := range entities {
+		id := classID(entity)
+		groups[id] = append(groups[id], i)
+	}
+	return groups
+}
+
+// pick returns the entities at the given positions in a new slice, or nil if there are none
+func pick[T any](entities []T, positions []int) []T {
+	if len(positions) == 0 {
+		return nil
+	}
+	result := make([]T, len(positions))
+	for i, pos := range positions {
+		result[i] = entities[pos]
+	}
+	return result
+}
+
+func copyEvent(event *models.Event) *models.Event {
+	if event == nil {
+		return nil
+	}
+	e := *event
+	return &e
+}
+
+// Revision returns the state revision the snapshot was taken at
+func (s *Snapshot) Revision() uint64 {
+	return s.revision
+}
+
+// Started returns how many competitors and teams have a start time at or before t. Views
+// that tell waiting from running competitors only change with this count and the revision.
+func (s *Snapshot) Started(t time.Time) int {
+	return sort.Search(len(s.startTimes), func(i int) bool { return s.startTimes[i].After(t) })
+}
+
+// Event returns a copy of the event, or nil if no event is loaded
+func (s *Snapshot) Event() *models.Event {
+	return copyEvent(s.event)
+}
+
+// Controls returns all controls
+func (s *Snapshot) Controls() []models.Control {
+	return s.controls
+}
+
+// Classes returns all classes
+func (s *Snapshot) Classes() []models.Class {
+	return s.classes
+}
+
+// Clubs returns all clubs
+func (s *Snapshot) Clubs() []models.Club {
+	return s.clubs
+}
+
+// Competitors returns all competitors
+func (s *Snapshot) Competitors() []models.Competitor {
+	return s.competitors
+}
+
+// Teams returns all teams
+func (s *Snapshot) Teams() []models.Team {
+	return s.teams
+}
+
+// CompetitorsByClass returns the competitors of a class in a new slice
+func (s *Snapshot) CompetitorsByClass(classID int) []models.Competitor {
+	return pick(s.competitors, s.competitorsByClass[classID])
+}
+
+// TeamsByClass returns the teams of a class in a new slice
+func (s *Snapshot) TeamsByClass(classID int) []models.Team {
+	return pick(s.teams, s.teamsByClass[classID])
+}
+
+// Control looks up a control by ID
+func (s *Snapshot) Control(id int) (models.Control, bool) {
+	i, ok := s.controlIndex[id]
+	if !ok {
+		return models.Control{}, false
+	}
+	return s.controls[i], true
+}
+
+// Class looks up a class by ID
+func (s *Snapshot) Class(id int) (models.Class, bool) {
+	i, ok := s.classIndex[id]
+	if !ok {
+		return models.Class{}, false
+	}
+	return s.classes[i], true
+}
+
+// Club looks up a club by ID
+func (s *Snapshot) Club(id int) (models.Club, bool) {
+	i, ok := s.clubIndex[id]
+	if !ok {
+		return models.Club{}, false
+	}
+	return s.clubs[i], true
+}
+
+// Competitor looks up a competitor by ID
+func (s *Snapshot) Competitor(id int) (models.Competitor, bool) {
+	i, ok := s.competitorIndex[id]
+	if !ok {
+		return models.Competitor{}, false
+	}
+	return s.competitors[i], true
+}
+
+// Team looks up a team by ID
+func (s *Snapshot) Team(id int) (models.Team, bool) {
+	i, ok := s.teamIndex[id]
+	if !ok {
+		return models.Team{}, false
+	}
+	return s.teams[i], true
+}
+
+// WithCompetitorsAndTeams returns a copy of the snapshot at the same revision with other
+// competitors and teams, for views that layer changes on top of the state
+func (s *Snapshot) WithCompetitorsAndTeams(competitors []models.Competitor, teams []models.Team) *Snapshot {
+	return newSnapshot(s.revision, s.event, s.controls, s.classes, s.clubs, competitors, teams)
+}
@@ -2,10 +2,14 @@ package state
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"meos-graphics/internal/models"
 )
 
+// State holds the competition data. Writers replace the exported fields between Lock and
+// Unlock, or through UpdateFromMeOS; each write publishes a new indexed Snapshot that the
+// getters read without taking the lock. The revision increases with every change.
 type State struct {
 	mu              sync.RWMutex
 	Event           *models.Event
@@ -13,200 +17,181 @@ type State struct {
 	Classes         []models.Class
 	Clubs           []models.Club
 	Competitors     []models.Competitor
-	updateCallbacks []func()
+	Teams           []models.Team
+	changeCallbacks []func(Change)
+
+	revision uint64
+	snapshot atomic.Pointer[Snapshot]
 }
 
 func New() *State {
-	return &State{
+	s := &State{
 		Controls:    []models.Control{},
 		Classes:     []models.Class{},
 		Clubs:       []models.Club{},
 		Competitors: []models.Competitor{},
+		Teams:       []models.Team{},
 	}
+	s.publish()
+	return s
 }
 
+// Lock acquires the write lock for direct changes to the exported fields
 func (s *State) Lock() {
 	s.mu.Lock()
 }
 
+// Unlock publishes the changes made since Lock, under a new revision if anything changed,
+// releases the write lock and notifies listeners of the changes
 func (s *State) Unlock() {
+	prev := s.Snapshot()
+	change := computeChange(prev, s.Event, s.Controls, s.Classes, s.Clubs, s.Competitors, s.Teams)
+	if !change.Empty() {
+		s.revision++
+	}
+	change.Revision = s.revision
+	s.publish()
+	change.Previous, change.Current = prev, s.Snapshot()
 	s.mu.Unlock()
+
+	if !change.Empty() {
+		s.notifyChange(change)
+	}
+}
+
+// publish replaces the snapshot with one built from the exported fields. The caller must
+// hold the write lock.
+func (s *State) publish() {
+	s.snapshot.Store(newSnapshot(s.revision, s.Event, s.Controls, s.Classes, s.Clubs, s.Competitors, s.Teams))
+}
+
+// Snapshot returns the current immutable view of the state
+func (s *State) Snapshot() *Snapshot {
+	return s.snapshot.Load()
+}
+
+// Revision returns the current state revision
+func (s *State) Revision() uint64 {
+	return s.Snapshot().Revision()
 }
 
 func (s *State) GetEvent() *models.Event {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.Event
+	return s.Snapshot().Event()
 }
 
+// SetEvent replaces the event and notifies listeners if it changed
 func (s *State) SetEvent(event *models.Event) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	if !eventChanged(s.Event, event) {
+		s.mu.Unlock()
+		return
+	}
+	prev := s.Snapshot()
 	s.Event = event
+	s.revision++
+	s.snapshot.Store(prev.withEvent(s.revision, event))
+	change := Change{Revision: s.revision, Event: true, Previous: prev, Current: s.Snapshot()}
+	s.mu.Unlock()
+
+	s.notifyChange(change)
 }
 
 func (s *State) GetControls() []models.Control {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	result := make([]models.Control, len(s.Controls))
-	copy(result, s.Controls)
-	return result
+	return append([]models.Control{}, s.Snapshot().Controls()...)
 }
 
 func (s *State) GetClasses() []models.Class {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	result := make([]models.Class, len(s.Classes))
-	copy(result, s.Classes)
-	return result
+	return append([]models.Class{}, s.Snapshot().Classes()...)
 }
 
 func (s *State) GetClubs() []models.Club {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	result := make([]models.Club, len(s.Clubs))
-	copy(result, s.Clubs)
-	return result
+	return append([]models.Club{}, s.Snapshot().Clubs()...)
 }
 
 func (s *State) GetCompetitors() []models.Competitor {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	result := make([]models.Competitor, len(s.Competitors))
-	copy(result, s.Competitors)
-	return result
+	return append([]models.Competitor{}, s.Snapshot().Competitors()...)
 }
 
 func (s *State) GetCompetitorsByClass(classID int) []models.Competitor {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	return s.Snapshot().CompetitorsByClass(classID)
+}
 
-	var result []models.Competitor
-	for _, comp := range s.Competitors {
-		if comp.Class.ID == classID {
-			result = append(result, comp)
-		}
+func (s *State) GetCompetitor(id int) *models.Competitor {
+	if comp, ok := s.Snapshot().Competitor(id); ok {
+		return &comp
 	}
-	return result
+	return nil
 }
 
-func (s *State) GetCompetitor(id int) *models.Competitor {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// GetControl returns the control with the given ID, or nil if there is none
+func (s *State) GetControl(id int) *models.Control {
+	if ctrl, ok := s.Snapshot().Control(id); ok {
+		return &ctrl
+	}
+	return nil
+}
+
+// GetClass returns the class with the given ID, or nil if there is none
+func (s *State) GetClass(id int) *models.Class {
+	if class, ok := s.Snapshot().Class(id); ok {
+		return &class
+	}
+	return nil
+}
+
+// GetClub returns the club with the given ID, or nil if there is none
+func (s *State) GetClub(id int) *models.Club {
+	if club, ok := s.Snapshot().Club(id); ok {
+		return &club
+	}
+	return nil
+}
+
+func (s *State) GetTeams() []models.Team {
+	return append([]models.Team{}, s.Snapshot().Teams()...)
+}
+
+func (s *State) GetTeamsByClass(classID int) []models.Team {
+	return s.Snapshot().TeamsByClass(classID)
+}
 
-	for _, comp := range s.Competitors {
-		if comp.ID == id {
-			return &comp
-		}
+func (s *State) GetTeam(id int) *models.Team {
+	if team, ok := s.Snapshot().Team(id); ok {
+		return &team
 	}
 	return nil
 }
 
 // OnUpdate registers a callback to be called when the state is updated
 func (s *State) OnUpdate(callback func()) {
+	s.OnChange(func(Change) { callback() })
+}
+
+// OnChange registers a callback to be called with what changed when the state is updated
+func (s *State) OnChange(callback func(Change)) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.updateCallbacks = append(s.updateCallbacks, callback)
+	s.changeCallbacks = append(s.changeCallbacks, callback)
 }
 
-// notifyUpdate calls all registered update callbacks
-func (s *State) notifyUpdate() {
+// notifyChange calls all registered change callbacks
+func (s *State) notifyChange(change Change) {
 	s.mu.RLock()
-	callbacks := make([]func(), len(s.updateCallbacks))
-	copy(callbacks, s.updateCallbacks)
+	callbacks := make([]func(Change), len(s.changeCallbacks))
+	copy(callbacks, s.changeCallbacks)
 	s.mu.RUnlock()
 
 	for _, cb := range callbacks {
-		cb()
+		cb(change)
 	}
 }
 
 // UpdateFromMeOS updates the state with new data from MeOS and notifies listeners only if data changed
-func (s *State) UpdateFromMeOS(event *models.Event, controls []models.Control, classes []models.Class, clubs []models.Club, competitors []models.Competitor) {
+func (s *State) UpdateFromMeOS(event *models.Event, controls []models.Control, classes []models.Class, clubs []models.Club, competitors []models.Competitor, teams []models.Team) {
 	s.mu.Lock()
 
-	// Check if data has actually changed
-	hasChanges := false
-
-	// Check event changes
-	if !hasChanges && (s.Event == nil && event != nil || s.Event != nil && event == nil) {
-		hasChanges = true
-	}
-	if !hasChanges && s.Event != nil && event != nil {
-		if s.Event.Name != event.Name || s.Event.Organizer != event.Organizer || s.Event.Start != event.Start {
-			hasChanges = true
-		}
-	}
-
-	// Check basic length changes
-	if !hasChanges && len(s.Controls) != len(controls) {
-		hasChanges = true
-	}
-	if !hasChanges && len(s.Classes) != len(classes) {
-		hasChanges = true
-	}
-	if !hasChanges && len(s.Clubs) != len(clubs) {
-		hasChanges = true
-	}
-	if !hasChanges && len(s.Competitors) != len(competitors) {
-		hasChanges = true
-	}
-
-	// For competitors, create a map for efficient lookup and check all fields
-	if !hasChanges && len(s.Competitors) == len(competitors) {
-		// Create map of current competitors for O(1) lookup
-		currentMap := make(map[int]*models.Competitor)
-		for i := range s.Competitors {
-			currentMap[s.Competitors[i].ID] = &s.Competitors[i]
-		}
-
-		// Check each new competitor against current state
-		for _, newComp := range competitors {
-			current, exists := currentMap[newComp.ID]
-			if !exists {
-				hasChanges = true
-				break
-			}
-
-			// Check all relevant fields
-			if current.Status != newComp.Status ||
-				current.Card != newComp.Card ||
-				current.Name != newComp.Name ||
-				current.StartTime != newComp.StartTime ||
-				current.Class.ID != newComp.Class.ID ||
-				current.Club.ID != newComp.Club.ID {
-				hasChanges = true
-				break
-			}
-
-			// Check finish time
-			if (current.FinishTime == nil) != (newComp.FinishTime == nil) {
-				hasChanges = true
-				break
-			}
-			if current.FinishTime != nil && newComp.FinishTime != nil && *current.FinishTime != *newComp.FinishTime {
-				hasChanges = true
-				break
-			}
-
-			// Check splits
-			if len(current.Splits) != len(newComp.Splits) {
-				hasChanges = true
-				break
-			}
-			for j := range newComp.Splits {
-				if j >= len(current.Splits) ||
-					current.Splits[j].Control.ID != newComp.Splits[j].Control.ID ||
-					current.Splits[j].PassingTime != newComp.Splits[j].PassingTime {
-					hasChanges = true
-					break
-				}
-			}
-			if hasChanges {
-				break
-			}
-		}
-	}
+	prev := s.Snapshot()
+	change := computeChange(prev, event, controls, classes, clubs, competitors, teams)
 
 	// Update the state
 	s.Event = event
@@ -214,11 +199,18 @@ func (s *State) UpdateFromMeOS(event *models.Event, controls []models.Control, c
 	s.Classes = classes
 	s.Clubs = clubs
 	s.Competitors = competitors
+	s.Teams = teams
+	if !change.Empty() {
+		s.revision++
+	}
+	change.Revision = s.revision
+	s.publish()
+	change.Previous, change.Current = prev, s.Snapshot()
 
 	s.mu.Unlock()
 
 	// Only notify if there were changes
-	if hasChanges {
-		s.notifyUpdate()
+	if !change.Empty() {
+		s.notifyChange(change)
 	}
 }
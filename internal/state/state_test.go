@@ -80,7 +80,9 @@ func TestState_GetControls(t *testing.T) {
 		testhelpers.CreateTestControl(2, "Control 1"),
 		testhelpers.CreateTestControl(3, "Finish"),
 	}
+	s.Lock()
 	s.Controls = testControls
+	s.Unlock()
 
 	// Get controls
 	retrievedControls := s.GetControls()
@@ -113,7 +115,9 @@ func TestState_GetClasses(t *testing.T) {
 		testhelpers.CreateTestClass(1, "Men Elite", 10, control1, control2),
 		testhelpers.CreateTestClass(2, "Women Elite", 20, control1),
 	}
+	s.Lock()
 	s.Classes = testClasses
+	s.Unlock()
 
 	// Get classes
 	retrievedClasses := s.GetClasses()
@@ -144,7 +148,9 @@ func TestState_GetClubs(t *testing.T) {
 		testhelpers.CreateTestClub(1, "Test Club 1", "SWE"),
 		testhelpers.CreateTestClub(2, "Test Club 2", "NOR"),
 	}
+	s.Lock()
 	s.Clubs = testClubs
+	s.Unlock()
 
 	// Get clubs
 	retrievedClubs := s.GetClubs()
@@ -177,7 +183,9 @@ func TestState_GetCompetitors(t *testing.T) {
 		testhelpers.CreateTestCompetitor(1, "John Doe", club, class),
 		testhelpers.CreateTestCompetitor(2, "Jane Smith", club, class),
 	}
+	s.Lock()
 	s.Competitors = testCompetitors
+	s.Unlock()
 
 	// Get competitors
 	retrievedCompetitors := s.GetCompetitors()
@@ -208,7 +216,9 @@ func TestState_GetCompetitorsByClass(t *testing.T) {
 		testhelpers.CreateTestCompetitor(3, "Mike Johnson", club, class1),
 		testhelpers.CreateTestCompetitor(4, "Sarah Wilson", club, class2),
 	}
+	s.Lock()
 	s.Competitors = competitors
+	s.Unlock()
 
 	// Test getting competitors by class 1
 	class1Competitors := s.GetCompetitorsByClass(1)
@@ -248,7 +258,9 @@ func TestState_GetCompetitor(t *testing.T) {
 		testhelpers.CreateTestCompetitor(2, "Jane Smith", club, class),
 		testhelpers.CreateTestCompetitor(3, "Mike Johnson", club, class),
 	}
+	s.Lock()
 	s.Competitors = competitors
+	s.Unlock()
 
 	// Test getting existing competitor
 	comp := s.GetCompetitor(2)
@@ -269,11 +281,74 @@ func TestState_GetCompetitor(t *testing.T) {
 	}
 }
 
+func TestState_GetTeams(t *testing.T) {
+	s := New()
+
+	relay := testhelpers.CreateTestClass(1, "Relay", 10)
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	teams := []models.Team{
+		{ID: 1, Name: "Team 1", Club: club, Class: relay, Legs: []models.Leg{{Number: 1, CompetitorIDs: []int{1}}}},
+		{ID: 2, Name: "Team 2", Club: club, Class: testhelpers.CreateTestClass(2, "Other", 20)},
+	}
+
+	var updates int32
+	s.OnUpdate(func() { atomic.AddInt32(&updates, 1) })
+
+	s.UpdateFromMeOS(nil, nil, nil, nil, nil, teams)
+	if got := atomic.LoadInt32(&updates); got != 1 {
+		t.Errorf("Update callbacks = %d, want 1", got)
+	}
+
+	if len(s.GetTeams()) != 2 {
+		t.Errorf("GetTeams() length = %d, want 2", len(s.GetTeams()))
+	}
+	if len(s.GetTeamsByClass(1)) != 1 {
+		t.Errorf("GetTeamsByClass(1) length = %d, want 1", len(s.GetTeamsByClass(1)))
+	}
+	if team := s.GetTeam(2); team == nil || team.Name != "Team 2" {
+		t.Errorf("GetTeam(2) = %+v, want Team 2", team)
+	}
+	if s.GetTeam(999) != nil {
+		t.Error("GetTeam(999) should return nil")
+	}
+
+	// Changing a leg assignment is a change, resending the same teams is not
+	changed := []models.Team{teams[0], teams[1]}
+	changed[0].Legs = []models.Leg{{Number: 1, CompetitorIDs: []int{2}}}
+	s.UpdateFromMeOS(nil, nil, nil, nil, nil, changed)
+	s.UpdateFromMeOS(nil, nil, nil, nil, nil, changed)
+	if got := atomic.LoadInt32(&updates); got != 2 {
+		t.Errorf("Update callbacks = %d, want 2", got)
+	}
+}
+
+func TestState_UpdateFromMeOS_DetectsReplacedEntities(t *testing.T) {
+	s := New()
+
+	var updates int32
+	s.OnUpdate(func() { atomic.AddInt32(&updates, 1) })
+
+	classes := []models.Class{testhelpers.CreateTestClass(1, "Men Elite", 10)}
+	clubs := []models.Club{testhelpers.CreateTestClub(1, "Test Club", "SWE")}
+	controls := []models.Control{testhelpers.CreateTestControl(1, "Radio 1")}
+	s.UpdateFromMeOS(nil, controls, classes, clubs, nil, nil)
+
+	// Same number of entities but a different class, club and control
+	s.UpdateFromMeOS(nil, controls, []models.Class{testhelpers.CreateTestClass(2, "Women Elite", 20)}, clubs, nil, nil)
+	s.UpdateFromMeOS(nil, controls, []models.Class{testhelpers.CreateTestClass(2, "Women Elite", 20)}, []models.Club{testhelpers.CreateTestClub(2, "Other Club", "NOR")}, nil, nil)
+	s.UpdateFromMeOS(nil, []models.Control{testhelpers.CreateTestControl(1, "Renamed")}, []models.Class{testhelpers.CreateTestClass(2, "Women Elite", 20)}, []models.Club{testhelpers.CreateTestClub(2, "Other Club", "NOR")}, nil, nil)
+
+	if got := atomic.LoadInt32(&updates); got != 4 {
+		t.Errorf("Update callbacks = %d, want 4", got)
+	}
+}
+
 func TestState_ConcurrentReads(_ *testing.T) {
 	s := New()
 
 	// Set up test data
 	s.SetEvent(testhelpers.CreateTestEvent())
+	s.Lock()
 	s.Controls = []models.Control{
 		testhelpers.CreateTestControl(1, "Start"),
 		testhelpers.CreateTestControl(2, "Finish"),
@@ -287,6 +362,7 @@ func TestState_ConcurrentReads(_ *testing.T) {
 	s.Competitors = []models.Competitor{
 		testhelpers.CreateTestCompetitor(1, "John Doe", s.Clubs[0], s.Classes[0]),
 	}
+	s.Unlock()
 
 	// Run concurrent reads
 	var wg sync.WaitGroup
@@ -498,6 +574,276 @@ func TestState_LockUnlock(t *testing.T) {
 	}
 }
 
+func TestState_Snapshot(t *testing.T) {
+	s := New()
+
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	class1 := testhelpers.CreateTestClass(1, "Men Elite", 10)
+	class2 := testhelpers.CreateTestClass(2, "Women Elite", 20)
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, []models.Class{class1, class2}, []models.Club{club}, []models.Competitor{
+		testhelpers.CreateTestCompetitor(1, "John Doe", club, class1),
+		testhelpers.CreateTestCompetitor(2, "Jane Smith", club, class2),
+	}, nil)
+
+	before := s.Snapshot()
+	if comp, ok := before.Competitor(2); !ok || comp.Name != "Jane Smith" {
+		t.Errorf("Competitor(2) = %+v, %v; want Jane Smith", comp, ok)
+	}
+	if class, ok := before.Class(2); !ok || class.Name != "Women Elite" {
+		t.Errorf("Class(2) = %+v, %v; want Women Elite", class, ok)
+	}
+	if _, ok := before.Club(99); ok {
+		t.Error("Club(99) should not be found")
+	}
+	if got := len(before.CompetitorsByClass(1)); got != 1 {
+		t.Errorf("CompetitorsByClass(1) length = %d, want 1", got)
+	}
+
+	// Direct writes are published on Unlock and leave earlier snapshots untouched
+	s.Lock()
+	s.Competitors[0].Status = "1"
+	s.Competitors = append(s.Competitors, testhelpers.CreateTestCompetitor(3, "Mike Johnson", club, class1))
+	s.Unlock()
+
+	if comp, _ := before.Competitor(1); comp.Status != "0" {
+		t.Errorf("Earlier snapshot status = %q, want %q", comp.Status, "0")
+	}
+	if got := len(before.CompetitorsByClass(1)); got != 1 {
+		t.Errorf("Earlier snapshot CompetitorsByClass(1) length = %d, want 1", got)
+	}
+
+	after := s.Snapshot()
+	if comp, _ := after.Competitor(1); comp.Status != "1" {
+		t.Errorf("Current snapshot status = %q, want %q", comp.Status, "1")
+	}
+	if got := len(after.CompetitorsByClass(1)); got != 2 {
+		t.Errorf("Current snapshot CompetitorsByClass(1) length = %d, want 2", got)
+	}
+
+	// The returned event is a copy
+	before.Event().Name = "Modified"
+	if before.Event().Name == "Modified" {
+		t.Error("Event() should return a copy")
+	}
+}
+
+func TestState_SetEventNotifies(t *testing.T) {
+	s := New()
+	var changes []Change
+	s.OnChange(func(change Change) { changes = append(changes, change) })
+
+	event := testhelpers.CreateTestEvent()
+	s.SetEvent(event)
+	s.SetEvent(event)
+	if len(changes) != 1 {
+		t.Fatalf("Got %d changes, want 1", len(changes))
+	}
+	change := changes[0]
+	if !change.Event || change.Revision != 1 {
+		t.Errorf("Change = %+v, want an event change at revision 1", change)
+	}
+	if change.Previous.Event() != nil || change.Current.Event().Name != event.Name {
+		t.Error("Change snapshots do not show the event being set")
+	}
+}
+
+func TestState_UnlockNotifies(t *testing.T) {
+	s := New()
+	var changes []Change
+	s.OnChange(func(change Change) { changes = append(changes, change) })
+
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	class := testhelpers.CreateTestClass(1, "Men Elite", 10)
+	s.Lock()
+	s.Classes = []models.Class{class}
+	s.Competitors = []models.Competitor{testhelpers.CreateTestCompetitor(1, "John Doe", club, class)}
+	s.Unlock()
+
+	// Unlocking without changes does not notify
+	s.Lock()
+	s.Unlock()
+
+	if len(changes) != 1 {
+		t.Fatalf("Got %d changes, want 1", len(changes))
+	}
+	change := changes[0]
+	if change.Revision != 1 || len(change.Competitors) != 1 || len(change.Classes) != 1 {
+		t.Errorf("Change = %+v, want the competitor and class at revision 1", change)
+	}
+	if len(change.Previous.Competitors()) != 0 || len(change.Current.Competitors()) != 1 {
+		t.Error("Change snapshots do not show the competitor being added")
+	}
+}
+
+func TestState_Revision(t *testing.T) {
+	s := New()
+	if got := s.Revision(); got != 0 {
+		t.Errorf("Initial revision = %d, want 0", got)
+	}
+
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	class := testhelpers.CreateTestClass(1, "Men Elite", 10)
+	comp := testhelpers.CreateTestCompetitor(1, "John Doe", club, class)
+	event := testhelpers.CreateTestEvent()
+
+	s.UpdateFromMeOS(event, nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+	if got := s.Revision(); got != 1 {
+		t.Errorf("Revision after first update = %d, want 1", got)
+	}
+
+	// Updates without changes keep the revision
+	s.UpdateFromMeOS(event, nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+	s.SetEvent(event)
+	if got := s.Revision(); got != 1 {
+		t.Errorf("Revision after unchanged update = %d, want 1", got)
+	}
+
+	comp.Status = "1"
+	s.UpdateFromMeOS(event, nil, []models.Class{class}, []models.Club{club}, []models.Competitor{comp}, nil)
+	renamed := *event
+	renamed.Name = "Renamed"
+	s.SetEvent(&renamed)
+	s.Lock()
+	s.Competitors[0].Status = "3"
+	s.Unlock()
+	if got := s.Revision(); got != 4 {
+		t.Errorf("Revision after three changes = %d, want 4", got)
+	}
+	s.Lock()
+	s.Unlock()
+	if got := s.Revision(); got != 4 {
+		t.Errorf("Revision after Unlock without changes = %d, want 4", got)
+	}
+	if got := s.Snapshot().Revision(); got != s.Revision() {
+		t.Errorf("Snapshot revision = %d, want %d", got, s.Revision())
+	}
+}
+
+func TestSnapshot_Started(t *testing.T) {
+	s := New()
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	class := testhelpers.CreateTestClass(1, "Men Elite", 10)
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	competitors := []models.Competitor{
+		testhelpers.CreateTestCompetitor(1, "Late", club, class),
+		testhelpers.CreateTestCompetitor(2, "Early", club, class),
+		testhelpers.CreateTestCompetitor(3, "No start", club, class),
+	}
+	competitors[0].StartTime = base.Add(10 * time.Minute)
+	competitors[1].StartTime = base
+	competitors[2].StartTime = time.Time{}
+	s.UpdateFromMeOS(nil, nil, []models.Class{class}, []models.Club{club}, competitors, []models.Team{{ID: 1, StartTime: base.Add(5 * time.Minute)}})
+
+	snap := s.Snapshot()
+	tests := []struct {
+		at   time.Time
+		want int
+	}{
+		{base.Add(-time.Second), 0},
+		{base, 1},
+		{base.Add(5 * time.Minute), 2},
+		{base.Add(time.Hour), 3},
+	}
+	for _, tt := range tests {
+		if got := snap.Started(tt.at); got != tt.want {
+			t.Errorf("Started(%v) = %d, want %d", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestState_OnChange(t *testing.T) {
+	s := New()
+
+	club1 := testhelpers.CreateTestClub(1, "Club One", "SWE")
+	club2 := testhelpers.CreateTestClub(2, "Club Two", "NOR")
+	class1 := testhelpers.CreateTestClass(1, "Men Elite", 10)
+	class2 := testhelpers.CreateTestClass(2, "Women Elite", 20)
+	class3 := testhelpers.CreateTestClass(3, "Juniors", 30)
+	classes := []models.Class{class1, class2, class3}
+	clubs := []models.Club{club1, club2}
+	competitors := []models.Competitor{
+		testhelpers.CreateTestCompetitor(1, "John Doe", club1, class1),
+		testhelpers.CreateTestCompetitor(2, "Jane Smith", club2, class2),
+		testhelpers.CreateTestCompetitor(3, "Mike Johnson", club1, class3),
+	}
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, classes, clubs, competitors, nil)
+
+	var changes []Change
+	s.OnChange(func(change Change) { changes = append(changes, change) })
+
+	update := func(classes []models.Class, clubs []models.Club, competitors []models.Competitor) Change {
+		changes = nil
+		s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, classes, clubs, competitors, nil)
+		if len(changes) != 1 {
+			t.Fatalf("Got %d change notifications, want 1", len(changes))
+		}
+		return changes[0]
+	}
+
+	// A finish only touches the competitor's class
+	finished := append([]models.Competitor{}, competitors...)
+	finished[1] = testhelpers.CreateFinishedCompetitor(2, "Jane Smith", club2, class2, 9000)
+	change := update(classes, clubs, finished)
+	if fmt.Sprint(change.Classes) != "[2]" || fmt.Sprint(change.Competitors) != "[2]" || change.Event {
+		t.Errorf("Finish change = %+v, want class 2 and competitor 2", change)
+	}
+	if fmt.Sprint(change.Clubs) != "[2]" {
+		t.Errorf("Finish change clubs = %v, want the competitor's club [2]", change.Clubs)
+	}
+	if change.Revision != s.Revision() {
+		t.Errorf("Change revision = %d, want %d", change.Revision, s.Revision())
+	}
+	if change.Current != s.Snapshot() || change.Previous.Revision() != change.Revision-1 {
+		t.Errorf("Change snapshots at revisions %d and %d, want the snapshots around the update",
+			change.Previous.Revision(), change.Current.Revision())
+	}
+
+	// Moving a competitor touches both classes
+	moved := append([]models.Competitor{}, finished...)
+	moved[0].Class = class2
+	change = update(classes, clubs, moved)
+	if fmt.Sprint(change.Classes) != "[1 2]" {
+		t.Errorf("Moved competitor classes = %v, want [1 2]", change.Classes)
+	}
+
+	// Renaming a club touches the classes of its members
+	renamedClubs := []models.Club{testhelpers.CreateTestClub(1, "Club Renamed", "SWE"), club2}
+	change = update(classes, renamedClubs, moved)
+	if fmt.Sprint(change.Classes) != "[2 3]" || fmt.Sprint(change.Clubs) != "[1]" {
+		t.Errorf("Club rename change = %+v, want classes [2 3] and club 1", change)
+	}
+
+	// Removing a competitor touches its class
+	change = update(classes, renamedClubs, moved[:2])
+	if fmt.Sprint(change.Classes) != "[3]" || fmt.Sprint(change.Competitors) != "[3]" {
+		t.Errorf("Removal change = %+v, want class 3 and competitor 3", change)
+	}
+
+	// Renaming a class changes the class list
+	renamedClasses := []models.Class{class1, class2, testhelpers.CreateTestClass(3, "Youth", 30)}
+	change = update(renamedClasses, renamedClubs, moved[:2])
+	if !change.Event || fmt.Sprint(change.Classes) != "[3]" {
+		t.Errorf("Class rename change = %+v, want event and class 3", change)
+	}
+}
+
+// newLargeState returns a state with 3000 competitors spread over 30 classes
+func newLargeState() *State {
+	s := New()
+	club := testhelpers.CreateTestClub(1, "Test Club", "SWE")
+	classes := make([]models.Class, 30)
+	for i := range classes {
+		classes[i] = testhelpers.CreateTestClass(i+1, fmt.Sprintf("Class %d", i+1), i*10)
+	}
+	competitors := make([]models.Competitor, 3000)
+	for i := range competitors {
+		competitors[i] = testhelpers.CreateTestCompetitor(i+1, fmt.Sprintf("Competitor %d", i+1), club, classes[i%len(classes)])
+	}
+	s.UpdateFromMeOS(testhelpers.CreateTestEvent(), nil, classes, []models.Club{club}, competitors, nil)
+	return s
+}
+
 func BenchmarkState_GetCompetitors(b *testing.B) {
 	s := New()
 
@@ -509,7 +855,9 @@ func BenchmarkState_GetCompetitors(b *testing.B) {
 	for i := 0; i < 1000; i++ {
 		competitors[i] = testhelpers.CreateTestCompetitor(i+1, fmt.Sprintf("Competitor %d", i+1), club, class)
 	}
+	s.Lock()
 	s.Competitors = competitors
+	s.Unlock()
 
 	b.ResetTimer()
 
@@ -533,7 +881,9 @@ func BenchmarkState_GetCompetitorsByClass(b *testing.B) {
 		class := classes[i%10]
 		competitors[i] = testhelpers.CreateTestCompetitor(i+1, fmt.Sprintf("Competitor %d", i+1), club, class)
 	}
+	s.Lock()
 	s.Competitors = competitors
+	s.Unlock()
 
 	b.ResetTimer()
 
@@ -547,12 +897,14 @@ func BenchmarkState_ConcurrentReads(b *testing.B) {
 
 	// Set up test data
 	s.SetEvent(testhelpers.CreateTestEvent())
+	s.Lock()
 	s.Controls = []models.Control{testhelpers.CreateTestControl(1, "Start")}
 	s.Classes = []models.Class{testhelpers.CreateTestClass(1, "Men Elite", 10)}
 	s.Clubs = []models.Club{testhelpers.CreateTestClub(1, "Test Club", "SWE")}
 	s.Competitors = []models.Competitor{
 		testhelpers.CreateTestCompetitor(1, "John Doe", s.Clubs[0], s.Classes[0]),
 	}
+	s.Unlock()
 
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
@@ -563,3 +915,21 @@ func BenchmarkState_ConcurrentReads(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkState_LargeEvent_GetCompetitor(b *testing.B) {
+	s := newLargeState()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = s.GetCompetitor(i%3000 + 1)
+	}
+}
+
+func BenchmarkState_LargeEvent_GetCompetitorsByClass(b *testing.B) {
+	s := newLargeState()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = s.GetCompetitorsByClass(i%30 + 1)
+	}
+}
@@ -124,6 +124,125 @@ func MOPDiffXML() string {
 </MOPDiff>`
 }
 
+// MOPDiffWithDeletionsXML returns a MOPDiff that deletes entities from MOPCompleteXML
+func MOPDiffWithDeletionsXML() string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<MOPDiff nextdifference="del789">
+    <ctrl id="102" delete="true"/>
+    <cls id="2" delete="true"/>
+    <org id="2" delete="true"/>
+    <cmp id="1" delete="true"/>
+</MOPDiff>`
+}
+
+// MOPRelayXML returns a MOPComplete XML with a two-leg relay class
+func MOPRelayXML() string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<MOPComplete nextdifference="relay123">
+    <competition date="2024-01-01" organizer="Test Organizer" zerotime="09:00:00">Relay Competition</competition>
+    <ctrl id="31">Radio 1</ctrl>
+    <ctrl id="32">Radio 2</ctrl>
+    <cls id="3" ord="30" radio="31;32">Relay</cls>
+    <org id="1" nat="SWE">Test Club 1</org>
+    <org id="2" nat="NOR">Test Club 2</org>
+    <cmp id="101" card="1101">
+        <base org="1" cls="3" stat="1" st="360000" rt="36000">Anna Andersson</base>
+        <radio>31,18000</radio>
+    </cmp>
+    <cmp id="102" card="1102">
+        <base org="1" cls="3" stat="1" st="396000" rt="36000">Bertil Berg</base>
+        <radio>32,15000</radio>
+    </cmp>
+    <cmp id="111" card="1111">
+        <base org="2" cls="3" stat="1" st="360000" rt="30000">Cecilie Carlsen</base>
+        <radio>31,16000</radio>
+    </cmp>
+    <cmp id="112" card="1112">
+        <base org="2" cls="3" stat="0" st="390000">Dag Dahl</base>
+    </cmp>
+    <tm id="10">
+        <base org="1" cls="3" stat="1" st="360000" rt="72000" bib="1">Test Club 1 Team</base>
+        <r>101;102</r>
+    </tm>
+    <tm id="11">
+        <base org="2" cls="3" stat="0" st="360000" bib="2">Test Club 2 Team</base>
+        <r>111;112</r>
+    </tm>
+</MOPComplete>`
+}
+
+// IOFStartListXML returns an IOF XML 3.0 StartList for testing
+func IOFStartListXML() string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<StartList xmlns="http://www.orienteering.org/datastandard/3.0" iofVersion="3.0" createTime="2024-01-01T09:00:00+01:00">
+    <Event>
+        <Id>7</Id>
+        <Name>IOF Competition</Name>
+        <StartTime><Date>2024-01-01</Date><Time>10:00:00+01:00</Time></StartTime>
+        <Organiser><Name>IOF Organizer</Name></Organiser>
+    </Event>
+    <ClassStart>
+        <Class><Id>1</Id><Name>H21</Name></Class>
+        <PersonStart>
+            <Person><Id>11</Id><Name><Family>Doe</Family><Given>John</Given></Name></Person>
+            <Organisation><Id>5</Id><Name>Test Club 1</Name><Country code="SWE">Sweden</Country></Organisation>
+            <Start><BibNumber>101</BibNumber><StartTime>2024-01-01T10:00:00+01:00</StartTime><ControlCard>12345</ControlCard></Start>
+        </PersonStart>
+        <PersonStart>
+            <Person><Id>12</Id><Name><Family>Smith</Family><Given>Jane</Given></Name></Person>
+            <Organisation><Id>6</Id><Name>Test Club 2</Name><Country code="NOR">Norway</Country></Organisation>
+            <Start><BibNumber>102</BibNumber><StartTime>2024-01-01T10:02:00+01:00</StartTime><ControlCard>12346</ControlCard></Start>
+        </PersonStart>
+    </ClassStart>
+    <ClassStart>
+        <Class><Name>D21</Name></Class>
+        <PersonStart>
+            <Person><Name><Family>Johnson</Family><Given>Mia</Given></Name></Person>
+            <Start><StartTime>2024-01-01T10:04:00+01:00</StartTime></Start>
+        </PersonStart>
+    </ClassStart>
+</StartList>`
+}
+
+// IOFResultListXML returns an IOF XML 3.0 ResultList matching IOFStartListXML
+func IOFResultListXML() string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<ResultList xmlns="http://www.orienteering.org/datastandard/3.0" iofVersion="3.0" status="Snapshot">
+    <Event>
+        <Id>7</Id>
+        <Name>IOF Competition</Name>
+        <StartTime><Date>2024-01-01</Date><Time>10:00:00+01:00</Time></StartTime>
+    </Event>
+    <ClassResult>
+        <Class><Id>1</Id><Name>H21</Name></Class>
+        <PersonResult>
+            <Person><Id>11</Id><Name><Family>Doe</Family><Given>John</Given></Name></Person>
+            <Organisation><Id>5</Id><Name>Test Club 1</Name><Country code="SWE">Sweden</Country></Organisation>
+            <Result>
+                <StartTime>2024-01-01T10:00:00+01:00</StartTime>
+                <FinishTime>2024-01-01T10:13:54.5+01:00</FinishTime>
+                <Time>834.5</Time>
+                <Position>1</Position>
+                <Status>OK</Status>
+                <SplitTime><ControlCode>31</ControlCode><Time>302</Time></SplitTime>
+                <SplitTime><ControlCode>32</ControlCode><Time>584</Time></SplitTime>
+                <SplitTime status="Additional"><ControlCode>99</ControlCode><Time>600</Time></SplitTime>
+            </Result>
+        </PersonResult>
+        <PersonResult>
+            <Person><Id>12</Id><Name><Family>Smith</Family><Given>Jane</Given></Name></Person>
+            <Organisation><Id>6</Id><Name>Test Club 2</Name><Country code="NOR">Norway</Country></Organisation>
+            <Result>
+                <StartTime>2024-01-01T10:02:00+01:00</StartTime>
+                <Status>MissingPunch</Status>
+                <SplitTime status="Missing"><ControlCode>31</ControlCode></SplitTime>
+                <SplitTime><ControlCode>32</ControlCode><Time>612</Time></SplitTime>
+            </Result>
+        </PersonResult>
+    </ClassResult>
+</ResultList>`
+}
+
 // InvalidXML returns invalid XML for error testing
 func InvalidXML() string {
 	return `<?xml version="1.0" encoding="UTF-8"?>
@@ -3,10 +3,13 @@ package web
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/gin-gonic/gin"
 
+	"meos-graphics/internal/feed"
 	"meos-graphics/internal/service"
 	"meos-graphics/internal/web/templates"
 )
@@ -14,17 +17,28 @@ import (
 // Handler handles web page requests
 type Handler struct {
 	service           *service.Service
+	feed              *feed.Feed
 	simulationEnabled bool
 }
 
 // New creates a new web handler
-func New(svc *service.Service, simulationEnabled bool) *Handler {
+func New(svc *service.Service, competitorFeed *feed.Feed, simulationEnabled bool) *Handler {
 	return &Handler{
 		service:           svc,
+		feed:              competitorFeed,
 		simulationEnabled: simulationEnabled,
 	}
 }
 
+const (
+	// speakerFeedItems is how many feed items the speaker page shows
+	speakerFeedItems = 50
+	// speakerFinishes is how many recent finishers the speaker page shows
+	speakerFinishes = 20
+	// speakerArrivalWindow is how far ahead the speaker page lists expected arrivals
+	speakerArrivalWindow = 5 * time.Minute
+)
+
 // renderTempl is a helper function to render templ components
 func renderTempl(c *gin.Context, status int, component templ.Component) {
 	c.Header("Content-Type", "text/html; charset=utf-8")
@@ -63,7 +77,8 @@ func (h *Handler) ClassPage(c *gin.Context) {
 		return
 	}
 
-	renderTempl(c, http.StatusOK, templates.ClassPage(classID, className, h.simulationEnabled))
+	isRelay := h.service.IsRelayClass(classID)
+	renderTempl(c, http.StatusOK, templates.ClassPage(classID, className, isRelay, h.simulationEnabled))
 }
 
 // StartListPartial serves the start list as an HTML partial for HTMX
@@ -116,3 +131,177 @@ func (h *Handler) SplitsPartial(c *gin.Context) {
 
 	renderTempl(c, http.StatusOK, templates.SplitsPartial(*splits))
 }
+
+// TeamResultsPartial serves the relay team results as an HTML partial for HTMX
+func (h *Handler) TeamResultsPartial(c *gin.Context) {
+	classID, err := strconv.Atoi(c.Param("classId"))
+	if err != nil {
+		renderTempl(c, http.StatusBadRequest, templates.ErrorPartial("Invalid class ID"))
+		return
+	}
+
+	results, err := h.service.GetTeamResults(classID)
+	if err != nil {
+		renderTempl(c, http.StatusInternalServerError, templates.ErrorPartial(err.Error()))
+		return
+	}
+
+	renderTempl(c, http.StatusOK, templates.TeamResultsPartial(results))
+}
+
+// LegsPartial serves the standings after every relay leg as an HTML partial for HTMX
+func (h *Handler) LegsPartial(c *gin.Context) {
+	classID, err := strconv.Atoi(c.Param("classId"))
+	if err != nil {
+		renderTempl(c, http.StatusBadRequest, templates.ErrorPartial("Invalid class ID"))
+		return
+	}
+
+	var legs []service.LegStandingsResponse
+	if first, err := h.service.GetLegStandings(classID, 1); err == nil {
+		legs = append(legs, *first)
+		for leg := 2; leg <= first.Legs; leg++ {
+			standings, err := h.service.GetLegStandings(classID, leg)
+			if err != nil {
+				renderTempl(c, http.StatusInternalServerError, templates.ErrorPartial(err.Error()))
+				return
+			}
+			legs = append(legs, *standings)
+		}
+	}
+
+	renderTempl(c, http.StatusOK, templates.LegsPartial(legs))
+}
+
+// SpeakerPage serves the live overview for speakers and commentators
+func (h *Handler) SpeakerPage(c *gin.Context) {
+	renderTempl(c, http.StatusOK, templates.SpeakerPage(h.simulationEnabled))
+}
+
+// SpeakerFeedPartial serves the latest competitor feed items, newest first, as an HTML
+// partial for HTMX
+func (h *Handler) SpeakerFeedPartial(c *gin.Context) {
+	items := h.feed.Items(0, 0, speakerFeedItems).Items
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	renderTempl(c, http.StatusOK, templates.SpeakerFeedPartial(items))
+}
+
+// SpeakerArrivalsPartial serves the competitors expected at a control or the finish soon
+// as an HTML partial for HTMX
+func (h *Handler) SpeakerArrivalsPartial(c *gin.Context) {
+	arrivals := h.service.GetExpectedArrivals(speakerArrivalWindow)
+	renderTempl(c, http.StatusOK, templates.SpeakerArrivalsPartial(arrivals))
+}
+
+// SpeakerFinishesPartial serves the latest finishers, newest first, as an HTML partial
+// for HTMX
+func (h *Handler) SpeakerFinishesPartial(c *gin.Context) {
+	items := h.feed.Items(0, 0, feed.MaxItems).Items
+	var finishes []feed.Item
+	for i := len(items) - 1; i >= 0 && len(finishes) < speakerFinishes; i-- {
+		if items[i].Type == feed.TypeFinished {
+			finishes = append(finishes, items[i])
+		}
+	}
+	renderTempl(c, http.StatusOK, templates.SpeakerFinishesPartial(finishes))
+}
+
+// SpeakerLeadersPartial serves the leader of every class as an HTML partial for HTMX
+func (h *Handler) SpeakerLeadersPartial(c *gin.Context) {
+	renderTempl(c, http.StatusOK, templates.SpeakerLeadersPartial(h.service.GetClassLeaders()))
+}
+
+// CompetitorPage serves the page for a single competitor
+func (h *Handler) CompetitorPage(c *gin.Context) {
+	competitorID, err := strconv.Atoi(c.Param("competitorId"))
+	if err != nil {
+		renderTempl(c, http.StatusBadRequest, templates.ErrorPage("Invalid competitor ID"))
+		return
+	}
+
+	competitor, err := h.service.GetCompetitor(competitorID)
+	if err != nil {
+		renderTempl(c, http.StatusNotFound, templates.ErrorPage("Competitor not found"))
+		return
+	}
+
+	renderTempl(c, http.StatusOK, templates.CompetitorPage(*competitor, h.simulationEnabled))
+}
+
+// CompetitorPartial serves a competitor's details as an HTML partial for HTMX
+func (h *Handler) CompetitorPartial(c *gin.Context) {
+	competitorID, err := strconv.Atoi(c.Param("competitorId"))
+	if err != nil {
+		renderTempl(c, http.StatusBadRequest, templates.ErrorPartial("Invalid competitor ID"))
+		return
+	}
+
+	competitor, err := h.service.GetCompetitor(competitorID)
+	if err != nil {
+		renderTempl(c, http.StatusNotFound, templates.ErrorPartial(err.Error()))
+		return
+	}
+
+	renderTempl(c, http.StatusOK, templates.CompetitorPartial(*competitor))
+}
+
+// SearchPage serves the competitor search page, with the results of the query if one is given
+func (h *Handler) SearchPage(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	var results []service.SearchResult
+	if query != "" {
+		results = h.service.Search(query)
+	}
+	renderTempl(c, http.StatusOK, templates.SearchPage(query, results, h.simulationEnabled))
+}
+
+// SearchResultsPartial serves the competitors matching a query as an HTML partial for HTMX
+func (h *Handler) SearchResultsPartial(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	var results []service.SearchResult
+	if query != "" {
+		results = h.service.Search(query)
+	}
+	renderTempl(c, http.StatusOK, templates.SearchResultsPartial(query, results))
+}
+
+// ClubsPage serves the list of clubs
+func (h *Handler) ClubsPage(c *gin.Context) {
+	renderTempl(c, http.StatusOK, templates.ClubsPage(h.service.GetClubs(), h.simulationEnabled))
+}
+
+// ClubPage serves the page for a single club
+func (h *Handler) ClubPage(c *gin.Context) {
+	clubID, err := strconv.Atoi(c.Param("clubId"))
+	if err != nil {
+		renderTempl(c, http.StatusBadRequest, templates.ErrorPage("Invalid club ID"))
+		return
+	}
+
+	club, err := h.service.GetClub(clubID)
+	if err != nil {
+		renderTempl(c, http.StatusNotFound, templates.ErrorPage("Club not found"))
+		return
+	}
+
+	renderTempl(c, http.StatusOK, templates.ClubPage(*club, h.simulationEnabled))
+}
+
+// ClubCompetitorsPartial serves a club's competitors as an HTML partial for HTMX
+func (h *Handler) ClubCompetitorsPartial(c *gin.Context) {
+	clubID, err := strconv.Atoi(c.Param("clubId"))
+	if err != nil {
+		renderTempl(c, http.StatusBadRequest, templates.ErrorPartial("Invalid club ID"))
+		return
+	}
+
+	club, err := h.service.GetClub(clubID)
+	if err != nil {
+		renderTempl(c, http.StatusNotFound, templates.ErrorPartial(err.Error()))
+		return
+	}
+
+	renderTempl(c, http.StatusOK, templates.ClubCompetitorsPartial(club.Competitors))
+}